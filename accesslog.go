@@ -0,0 +1,120 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// 默认慢请求阈值
+const DefaultSlowRequest = time.Second
+
+// AccessLogOption 访问日志选项
+type AccessLogOption func(*accessLogOptions)
+
+type accessLogOptions struct {
+	slow    time.Duration
+	maxBody int
+}
+
+// WithAccessLogSlow 设置慢请求阈值，超过阈值的请求以WARN级别输出并标记slow，<=0表示不区分
+func WithAccessLogSlow(d time.Duration) AccessLogOption {
+	return func(o *accessLogOptions) { o.slow = d }
+}
+
+// WithAccessLogBody 请求失败时记录请求体，最多maxBytes字节，<=0表示不记录
+func WithAccessLogBody(maxBytes int) AccessLogOption {
+	return func(o *accessLogOptions) { o.maxBody = maxBytes }
+}
+
+// AccessLog 服务端结构化访问日志中间件
+// 输出method、path、code、latency、peer、bytes，失败请求以ERROR级别输出
+func AccessLog(logger log.Logger, opts ...AccessLogOption) middleware.Middleware {
+	o := accessLogOptions{slow: DefaultSlowRequest}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			start := time.Now()
+			reply, err := handler(ctx, req)
+			latency := time.Since(start)
+
+			method, path, remote := accessInfo(ctx)
+			code := 200
+			if se := errors.FromError(err); se != nil {
+				code = int(se.Code)
+			}
+
+			kv := []interface{}{
+				"kind", "access",
+				"method", method,
+				"path", path,
+				"code", code,
+				"latency", latency.Seconds(),
+				"peer", remote,
+				"bytes", replySize(reply),
+			}
+
+			level := log.LevelInfo
+			switch {
+			case err != nil:
+				level = log.LevelError
+				kv = append(kv, "error", err.Error())
+				if o.maxBody > 0 {
+					kv = append(kv, "body", truncate(fmt.Sprintf("%+v", req), o.maxBody))
+				}
+			case o.slow > 0 && latency >= o.slow:
+				level = log.LevelWarn
+				kv = append(kv, "slow", true)
+			}
+
+			_ = log.WithContext(ctx, logger).Log(level, kv...)
+			return reply, err
+		}
+	}
+}
+
+// accessInfo 获取请求方法、路径和对端地址
+func accessInfo(ctx context.Context) (method, path, remote string) {
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return "", "", ""
+	}
+
+	method, path = tr.Kind().String(), tr.Operation()
+	if ht, ok := tr.(khttp.Transporter); ok {
+		r := ht.Request()
+		method, path, remote = r.Method, r.URL.Path, r.RemoteAddr
+		return method, path, remote
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+	return method, path, remote
+}
+
+// replySize 响应消息的序列化大小，非protobuf消息返回0
+func replySize(reply interface{}) int {
+	if m, ok := reply.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	return 0
+}
+
+// truncate 截断过长的字符串
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// 管理端口配置键
+	AdminConfigKey = "admin"
+
+	// 默认管理端口监听地址(仅本机可访问)
+	DefaultAdminAddr = "127.0.0.1:9090"
+)
+
+var _ transport.Server = (*AdminServer)(nil)
+
+// AdminConfig 管理端口配置，对应配置中的 admin 节点
+type AdminConfig struct {
+	Enabled bool   `json:"enabled"`
+	Addr    string `json:"addr"`  // 监听地址，默认127.0.0.1:9090
+	Token   string `json:"token"` // 访问令牌，非空时要求 Authorization: Bearer <token>
+}
+
+// AdminServer 管理端口，暴露pprof、expvar和健康检查，可通过Handle挂载Prometheus指标
+type AdminServer struct {
+	mux   *http.ServeMux
+	srv   *http.Server
+	token string
+}
+
+// NewAdminServer 创建管理端口服务，health为nil时不挂载健康检查
+func NewAdminServer(cfg AdminConfig, health *Health) *AdminServer {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = DefaultAdminAddr
+	}
+
+	s := &AdminServer{
+		mux:   http.NewServeMux(),
+		token: cfg.Token,
+	}
+	s.srv = &http.Server{
+		Addr:              addr,
+		Handler:           s.guard(s.mux),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	s.mux.Handle("/debug/vars", expvar.Handler())
+	if health != nil {
+		s.mux.Handle(DefaultHealthPath, health.Handler())
+		s.mux.Handle(DefaultLivenessPath, health.LivenessHandler())
+		s.mux.Handle(DefaultReadinessPath, health.ReadinessHandler())
+	}
+
+	return s
+}
+
+// Handle 在管理端口上挂载自定义处理器
+func (s *AdminServer) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Addr 监听地址
+func (s *AdminServer) Addr() string {
+	return s.srv.Addr
+}
+
+// Start 启动管理端口，阻塞直到服务停止
+func (s *AdminServer) Start(_ context.Context) error {
+	ln, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+	if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop 停止管理端口
+func (s *AdminServer) Stop(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// guard 校验访问令牌
+func (s *AdminServer) guard(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
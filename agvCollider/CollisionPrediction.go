@@ -20,15 +20,15 @@ func calculateOptimalSearchRadius(agvs []*AGV, timeRange, collisionThreshold flo
 	maxSpeed := 0.0
 
 	for _, agv := range agvs {
-		if agv.Width > maxWidth {
-			maxWidth = agv.Width
+		if agv.extent() > maxWidth {
+			maxWidth = agv.extent()
 		}
 		if agv.Speed > maxSpeed {
 			maxSpeed = agv.Speed
 		}
 	}
 
-	// 搜索半径 = 最大车宽 + 最大速度 * 时间范围 + 碰撞阈值 + 安全边距
+	// 搜索半径 = 最大车体尺寸 + 最大速度 * 时间范围 + 碰撞阈值 + 安全边距
 	safetyMargin := maxWidth * 0.5 // 50%的安全边距
 	return maxWidth + maxSpeed*timeRange + collisionThreshold + safetyMargin
 }
@@ -56,16 +56,19 @@ func predictCollisionsForFleetWithKDTree(agvs []*AGV, timeRange, timeStep, colli
 	var collisions []CollisionPrediction
 	seen := make(map[int]map[int]bool)
 
-	// 构建KD树
-	root := buildKDTree(agvs, 0)
-
 	// 计算动态搜索半径
 	searchRadius := calculateOptimalSearchRadius(agvs, timeRange, collisionThreshold)
 
+	// 每辆AGV的轨迹只计算一次
+	trajs := trajectories(agvs)
+
+	// 按楼层构建空间索引
+	index := buildNeighborIndex(agvs, searchRadius)
+
 	for _, agv1 := range agvs {
 		// 使用KD树查找潜在邻居
 		neighbors := []*AGV{}
-		rangeSearch(root, agv1, searchRadius, &neighbors)
+		index.rangeSearch(agv1, searchRadius, &neighbors)
 
 		for _, agv2 := range neighbors {
 			// 避免重复检查同一对AGV
@@ -91,7 +94,7 @@ func predictCollisionsForFleetWithKDTree(agvs []*AGV, timeRange, timeStep, colli
 			}
 
 			// 检测碰撞
-			if hasCollision, collision := agv1.PredictCollisionWith(agv2, timeRange, timeStep, collisionThreshold); hasCollision {
+			if hasCollision, collision := agv1.predictCollision(agv2, trajs[agv1], trajs[agv2], timeRange, timeStep, collisionThreshold); hasCollision {
 				collisions = append(collisions, collision)
 			}
 
@@ -115,54 +118,49 @@ func PredictCollisionsForFleet(agvs []*AGV, timeRange, timeStep, collisionThresh
 	var collisions []CollisionPrediction
 	seen := make(map[int]map[int]bool)
 
-	// 使用KD树优化邻居搜索
-	// 构建KD树用于空间索引
-	root := buildKDTree(agvs, 0)
-
 	// 计算搜索半径：基于AGV最大宽度和预测时间范围
 	maxWidth := 0.0
 	maxSpeed := 0.0
 	for _, agv := range agvs {
-		if agv.Width > maxWidth {
-			maxWidth = agv.Width
+		if agv.extent() > maxWidth {
+			maxWidth = agv.extent()
 		}
 		if agv.Speed > maxSpeed {
 			maxSpeed = agv.Speed
 		}
 	}
 
-	// 搜索半径 = 最大车宽 + 最大速度 * 时间范围 + 碰撞阈值
+	// 搜索半径 = 最大车体尺寸 + 最大速度 * 时间范围 + 碰撞阈值
 	searchRadius := maxWidth + maxSpeed*timeRange + collisionThreshold
 
+	// 按楼层构建空间索引优化邻居搜索
+	index := buildNeighborIndex(agvs, searchRadius)
+	trajs := trajectories(agvs)
+
 	for i := range agvs {
 		// 使用KD树查找潜在邻居
 		neighbors := []*AGV{}
-		rangeSearch(root, agvs[i], searchRadius, &neighbors)
-
-		for j := range neighbors {
-			// 避免重复检查同一对AGV
-			if i >= j {
-				continue
-			}
+		index.rangeSearch(agvs[i], searchRadius, &neighbors)
 
+		for _, other := range neighbors {
 			// 检查是否已经处理过这对AGV
 			if seen[agvs[i].Id] == nil {
 				seen[agvs[i].Id] = make(map[int]bool)
 			}
-			if seen[agvs[j].Id] == nil {
-				seen[agvs[j].Id] = make(map[int]bool)
+			if seen[other.Id] == nil {
+				seen[other.Id] = make(map[int]bool)
 			}
-			if seen[agvs[i].Id][agvs[j].Id] || seen[agvs[j].Id][agvs[i].Id] {
+			if seen[agvs[i].Id][other.Id] || seen[other.Id][agvs[i].Id] {
 				continue
 			}
 
 			// 检测碰撞
-			if hasCollision, collision := agvs[i].PredictCollisionWith(agvs[j], timeRange, timeStep, collisionThreshold); hasCollision {
+			if hasCollision, collision := agvs[i].predictCollision(other, trajs[agvs[i]], trajs[other], timeRange, timeStep, collisionThreshold); hasCollision {
 				collisions = append(collisions, collision)
 			}
 
 			// 标记已处理
-			seen[agvs[i].Id][agvs[j].Id] = true
+			seen[agvs[i].Id][other.Id] = true
 		}
 	}
 
@@ -191,6 +189,7 @@ func PredictCollisionsForFleetOptimized(agvs []*AGV, timeRange, timeStep, collis
 	}
 
 	// 对于小型车队，使用原始方法
+	trajs := trajectories(vs)
 	for i := range vs {
 		for j := range vs {
 			if i >= j {
@@ -209,7 +208,7 @@ func PredictCollisionsForFleetOptimized(agvs []*AGV, timeRange, timeStep, collis
 			}
 
 			// 检测碰撞
-			if hasCollision, collision := vs[i].PredictCollisionWith(vs[j], timeRange, timeStep, collisionThreshold); hasCollision {
+			if hasCollision, collision := vs[i].predictCollision(vs[j], trajs[vs[i]], trajs[vs[j]], timeRange, timeStep, collisionThreshold); hasCollision {
 				collisions = append(collisions, collision)
 			}
 
@@ -221,6 +220,15 @@ func PredictCollisionsForFleetOptimized(agvs []*AGV, timeRange, timeStep, collis
 	return collisions
 }
 
+// trajectories 计算车队中每辆AGV的轨迹
+func trajectories(agvs []*AGV) map[*AGV]*Trajectory {
+	trajs := make(map[*AGV]*Trajectory, len(agvs))
+	for _, agv := range agvs {
+		trajs[agv] = agv.Trajectory(0)
+	}
+	return trajs
+}
+
 // filterAGVsByOrigin 剔除在原点的AGV
 func filterAGVsByOrigin(agvs []*AGV) []*AGV {
 	var filteredAGVs []*AGV
@@ -231,3 +239,68 @@ func filterAGVsByOrigin(agvs []*AGV) []*AGV {
 	}
 	return filteredAGVs
 }
+
+// PredictEarliestCollision 快速模式：只求车队中最早发生的碰撞
+// 按时间递增扫描，每个时刻每辆AGV只预测一次位置，找到碰撞即停止，适用于只关心"是否即将碰撞及何时碰撞"的控制周期
+// 每辆AGV的轨迹只计算一次，不修改AGV状态；调用前需已生成SubPath
+// 参数:
+//   agvs: AGV车队
+//   timeRange: 预测时间范围（秒）
+//   timeStep: 时间步长（秒）
+//   collisionThreshold: 碰撞距离阈值（米），<=0时每对AGV使用两车半宽之和
+// 返回:
+//   bool: 时间范围内是否会发生碰撞
+//   CollisionPrediction: 最早的碰撞事件（同一时刻多对碰撞时取车队中下标靠前的一对）
+func PredictEarliestCollision(agvs []*AGV, timeRange, timeStep, collisionThreshold float64) (bool, CollisionPrediction) {
+	if timeStep <= 0 {
+		timeStep = 0.1 // 默认0.1秒步长
+	}
+	radius := calculateOptimalSearchRadius(agvs, timeRange, collisionThreshold)
+	var pairs []agvPair
+	for _, p := range candidatePairs(agvs, radius) {
+		if agvs[p.i].Floor == agvs[p.j].Floor {
+			pairs = append(pairs, p)
+		}
+	}
+	if len(pairs) == 0 {
+		return false, CollisionPrediction{}
+	}
+
+	// 只预测参与候选对的AGV
+	trajs := make(map[int]*Trajectory)
+	for _, p := range pairs {
+		for _, i := range []int{p.i, p.j} {
+			if trajs[i] == nil {
+				trajs[i] = agvs[i].Trajectory(0)
+			}
+		}
+	}
+
+	poses := make(map[int]Pose, len(trajs))
+	for t := 0.0; t <= timeRange; t += timeStep {
+		for i, tr := range trajs {
+			poses[i] = tr.At(t)
+		}
+		for _, p := range pairs {
+			a1, a2 := agvs[p.i], agvs[p.j]
+			threshold := collisionThreshold
+			if threshold <= 0 {
+				threshold = (a1.Width + a2.Width) / 2
+			}
+			pose1, pose2 := poses[p.i], poses[p.j]
+			if collided, distance := a1.collidesAt(a2, pose1, pose2, threshold); collided {
+				return true, CollisionPrediction{
+					AGV1:               a1,
+					AGV2:               a2,
+					CollisionTime:      t,
+					CollisionPoint:     Point{X: (pose1.X + pose2.X) / 2, Y: (pose1.Y + pose2.Y) / 2, Floor: pose1.Floor},
+					AGV1Pose:           pose1,
+					AGV2Pose:           pose2,
+					Distance:           distance,
+					CollisionThreshold: threshold,
+				}
+			}
+		}
+	}
+	return false, CollisionPrediction{}
+}
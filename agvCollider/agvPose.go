@@ -8,18 +8,22 @@ import "math"
 // - X: 横坐标
 // - Y: 纵坐标
 // - T: 航向角，单位弧度（0表示朝向x正方向）
+// - Floor: 楼层/地图层编号
 type Pose struct {
-	X float64
-	Y float64
-	T float64
+	X     float64
+	Y     float64
+	T     float64
+	Floor int
 }
 
 // Point 表示二维平面上的一个点（无方向）
 // - X: 横坐标
 // - Y: 纵坐标
+// - Floor: 楼层/地图层编号，路径中相邻两点楼层不同时视为跨层（提升机）转运段，不参与平面碰撞检测
 type Point struct {
-	X float64
-	Y float64
+	X     float64
+	Y     float64
+	Floor int
 }
 
 // Segment 表示路径中的一条线段
@@ -31,27 +35,55 @@ type Segment struct {
 }
 
 // AGV 表示自动引导车结构体
+// - Floor:    当前所在楼层/地图层，碰撞检测与KD树查询只在同一楼层内进行
 // - Pose:     当前位姿（位置+方向）
 // - Width:  AGV的宽度（m）
-// - Speed:    行驶速度（m/s）
+// - Length: AGV的长度（m），为0时按半径Width/2的圆形车体检测，否则按航向方向的矩形车体检测
+// - Polygon: 车体坐标系下的凸多边形轮廓（如牵引车列），设置后优先于Width/Length使用
+// - Speed:    巡航速度（m/s）
+// - Velocity: 当前速度（m/s），设置了MaxAccel时从该速度开始加速
+// - MaxAccel: 最大加速度（m/s²），为0时视为瞬时达到巡航速度
+// - MaxDecel: 最大减速度（m/s²），为0时视为瞬时停车
+// - Arcs:     路径中的圆弧，路径相邻两点均位于某段圆弧上时按圆弧行驶，否则按直线行驶
+// - Stops:    途中停靠点（里程从AGV当前位置沿路径计算），与上述参数共同组成速度曲线 Profile
 // - Path:     全局路径（所有规划好的路径点）
 // - SubPath:  当前子路径（缓存，用于避免每次从全局路径起点计算）
 // - InitDone: 是否已经初始化过子路径（首次计算需要用全局路径）
+// - Priority: 任务优先级，数值越大越优先
+// - Loaded:   是否载货
+// - Battery:  剩余电量比例（0~1）
+// - Waited:   已累计等待时长（s）
+// - History:  位姿上报历史与滤波状态，由 Observe 更新
 type AGV struct {
 	Id       int
+	Floor    int
 	Width    float64
+	Length   float64
+	Polygon  Polygon
 	Pose     Pose
 	Speed    float64
+	Velocity float64
+	MaxAccel float64
+	MaxDecel float64
+	Stops    []StopPoint
 	Path     []Point
+	Arcs     []Arc
 	SubPath  []Point
 	InitDone bool
+	Priority int
+	Loaded   bool
+	Battery  float64
+	Waited   float64
+	History  *PoseHistory
+
+	bounds *pathBounds // 路径包围盒缓存
 }
 
 // ===================== 基础工具函数 =====================
 
 // ConvertFloat64SliceToPoints 将[][]float64转换为[]Point
 // 参数:
-//   points: 二维浮点数切片，每个子切片应包含X,Y坐标
+//   points: 二维浮点数切片，每个子切片应包含X,Y坐标，可选第3个元素为楼层
 // 返回:
 //   []Point: 转换后的Point切片
 func Float64SliceToPoints(points [][]float64) []Point {
@@ -60,6 +92,9 @@ func Float64SliceToPoints(points [][]float64) []Point {
 		if len(p) >= 2 {
 			result[i] = Point{X: p[0], Y: p[1]}
 		}
+		if len(p) >= 3 {
+			result[i].Floor = int(p[2])
+		}
 	}
 	return result
 }
@@ -106,8 +141,9 @@ func cross(ax, ay, bx, by float64) float64 {
 //   Point: 插值得到的新点
 func interpolate(p1, p2 Point, ratio float64) Point {
 	return Point{
-		X: p1.X + (p2.X-p1.X)*ratio,
-		Y: p1.Y + (p2.Y-p1.Y)*ratio,
+		X:     p1.X + (p2.X-p1.X)*ratio,
+		Y:     p1.Y + (p2.Y-p1.Y)*ratio,
+		Floor: p1.Floor,
 	}
 }
 
@@ -143,8 +179,9 @@ func projectPointOnSegment(pose Pose, seg Segment) (Point, float64) {
 	}
 	// 计算投影点
 	p := Point{
-		X: seg.Start.X + t*vx,
-		Y: seg.Start.Y + t*vy,
+		X:     seg.Start.X + t*vx,
+		Y:     seg.Start.Y + t*vy,
+		Floor: seg.Start.Floor,
 	}
 	return p, t
 }
@@ -178,11 +215,10 @@ func (agv *AGV) GenerateSubPath() []Point {
 	var segIdx int
 	var proj Point
 
-	// 遍历路径段，找到距离AGV最近的投影点
-	for i := 0; i < n-1; i++ {
-		seg := Segment{Start: basePath[i], End: basePath[i+1]}
-		p, _ := projectPointOnSegment(agv.Pose, seg)
-		d := getDistance(Point{agv.Pose.X, agv.Pose.Y}, p)
+	// 遍历路径段（直线或圆弧），找到距离AGV最近的投影点
+	for i, seg := range agv.pathSegs(basePath) {
+		p, _ := seg.project(Point{X: agv.Pose.X, Y: agv.Pose.Y, Floor: agv.Pose.Floor})
+		d := getDistance(Point{X: agv.Pose.X, Y: agv.Pose.Y, Floor: agv.Pose.Floor}, p)
 		if d < minDist {
 			minDist = d
 			segIdx = i
@@ -202,79 +238,40 @@ func (agv *AGV) GenerateSubPath() []Point {
 	return newPath
 }
 
-// PredictPosition 预测AGV在dt秒后的位姿
+// PredictPosition 预测AGV在dt秒后的位姿，并将AGV的Pose更新为预测位姿
 // 步骤:
-//   1. 调用 GenerateSubPath 获取子路径（自动复用缓存）
-//   2. 计算子路径的累计里程表
-//   3. 根据 v*dt 找到目标距离 targetS
-//   4. 在目标处进行插值，得到预测位置和方向
+//   1. 由当前子路径和速度曲线 Profile 计算轨迹 Trajectory
+//   2. 在轨迹上按dt采样，得到预测位置和方向
+// 同一AGV需要多次预测时应直接使用 Trajectory，避免反复计算且不修改AGV状态
 // 参数:
 //   dt: 预测的时间间隔，单位秒
 // 返回:
 //   Pose: 预测出的位姿
 func (agv *AGV) PredictPosition(dt float64) Pose {
-	newPath := agv.SubPath
-	n := len(newPath)
-	if n < 2 {
+	if len(agv.SubPath) < 2 {
 		return agv.Pose
 	}
-
-	// Step1: 路径每段的长度 & 累计长度
-	segmentLengths := make([]float64, n-1)
-	cumulativeLengths := make([]float64, n)
-	for i := 1; i < n; i++ {
-		segmentLengths[i-1] = getDistance(newPath[i-1], newPath[i])
-		cumulativeLengths[i] = cumulativeLengths[i-1] + segmentLengths[i-1]
-	}
-
-	// Step2: 目标行驶距离 S = v * dt
-	targetS := agv.Speed * dt
-	totalLen := cumulativeLengths[n-1]
-
-	// Step3: 如果超出路径总长，直接返回最后一个点
-	if targetS >= totalLen {
-		last := newPath[n-1]
-		// 航向角取最后一段的方向
-		dx := newPath[n-1].X - newPath[n-2].X
-		dy := newPath[n-1].Y - newPath[n-2].Y
-		theta := math.Atan2(dy, dx)
-		agv.Pose = Pose{X: last.X, Y: last.Y, T: theta}
-		return agv.Pose
-	}
-
-	// Step4: 找出targetS在哪个路径段上
-	var segIdx int
-	for i := 1; i < n; i++ {
-		if targetS <= cumulativeLengths[i] {
-			segIdx = i - 1
-			break
-		}
-	}
-
-	// Step5: 在该段上进行插值
-	segStart := newPath[segIdx]
-	segEnd := newPath[segIdx+1]
-	segLen := segmentLengths[segIdx]
-
-	distOnSeg := targetS - cumulativeLengths[segIdx]
-	ratio := distOnSeg / segLen
-
-	pt := interpolate(segStart, segEnd, ratio)
-	theta := math.Atan2(segEnd.Y-segStart.Y, segEnd.X-segStart.X)
-
-	// 更新AGV姿态并返回
-	agv.Pose = Pose{X: pt.X, Y: pt.Y, T: theta}
+	agv.Pose = agv.Trajectory(0).At(dt)
 	return agv.Pose
 }
 
 // ====================== AGV方法扩展 ======================
 
 // DetectCollisionWith 检测当前AGV和另一辆AGV的潜在碰撞
+// 两车不在同一楼层时不检测
 func (agv *AGV) DetectCollisionWith(other *AGV, tol float64) (bool, CollisionEvent) {
+	if agv.Floor != other.Floor {
+		return false, CollisionEvent{}
+	}
+	// 路径包围盒不重叠时跳过路径段检测
+	bA, bB := agv.pathBounds(), other.pathBounds()
+	if !bA.overlaps(bB) {
+		return false, CollisionEvent{}
+	}
 	ok, col := earliestCollision(
-		agv.Path, other.Path,
-		agv.Speed, other.Speed,
-		(agv.Width+other.Width)/2, tol,
+		bA, bB,
+		agv.Profile(bA.length).legs(), other.Profile(bB.length).legs(),
+		agv.segmentChecker(other), tol,
 	)
 	if ok {
 		return true, CollisionEvent{
@@ -289,57 +286,155 @@ func (agv *AGV) DetectCollisionWith(other *AGV, tol float64) (bool, CollisionEve
 	return false, CollisionEvent{}
 }
 
-// ====================== 基于PredictPosition的碰撞检测 ======================
+// ====================== 基于轨迹预测的碰撞检测 ======================
 
-// PredictCollisionWith 使用PredictPosition方法检测两辆AGV是否会相撞
+// PredictCollisionWith 按两车轨迹 Trajectory 检测两辆AGV是否会相撞，不修改AGV状态
+// 自适应步长：两车相距较远时，按两车最大速度之和推算最早可能接触的时间直接跳过；
+// 接近后按timeStep采样，检测到碰撞后在上一个安全时刻与碰撞时刻之间二分，得到更精确的碰撞时间
+// 两车均为圆形车体、沿直线路径匀速行驶（无加减速）时，按分段匀速运动解析求解，无采样误差
 // 参数:
 //   other: 另一辆AGV
 //   timeRange: 预测时间范围（秒），默认检查0到timeRange秒内的所有时间点
-//   timeStep: 时间步长（秒），用于离散化时间检查
-//   collisionThreshold: 碰撞距离阈值（米），两车中心距离小于此值认为碰撞
+//   timeStep: 时间步长（秒），两车接近时的最大采样间隔
+//   collisionThreshold: 碰撞距离阈值（米），两车中心距离小于此值认为碰撞；
+//                       任一车设置了Length或Polygon时按车体重叠判断，阈值超出两车半宽之和的部分作为车体外扩的安全距离
+//   两车不在同一楼层时不检测；预测位姿所在楼层不同的时刻跳过
 // 返回:
 //   bool: 是否会发生碰撞
 //   CollisionPrediction: 碰撞预测信息
 func (agv *AGV) PredictCollisionWith(other *AGV, timeRange, timeStep, collisionThreshold float64) (bool, CollisionPrediction) {
+	return agv.predictCollision(other, agv.Trajectory(0), other.Trajectory(0), timeRange, timeStep, collisionThreshold)
+}
+
+// predictCollision 在已计算的两车轨迹上检测碰撞，车队检测时每辆AGV的轨迹只计算一次
+func (agv *AGV) predictCollision(other *AGV, trajA, trajB *Trajectory, timeRange, timeStep, collisionThreshold float64) (bool, CollisionPrediction) {
 	if timeStep <= 0 {
 		timeStep = 0.1 // 默认0.1秒步长
 	}
 	if collisionThreshold <= 0 {
 		collisionThreshold = (agv.Width + other.Width) / 2 // 默认使用两车半宽之和
 	}
+	if agv.Floor != other.Floor {
+		return false, CollisionPrediction{}
+	}
 
-	var earliestCollision CollisionPrediction
-	earliestTime := timeRange + 1 // 初始化为超出范围的值
-	found := false
+	// 圆形车体沿直线匀速行驶时解析求解
+	if !agv.hasPolygon() && !other.hasPolygon() && !agv.hasFootprint() && !other.hasFootprint() {
+		ta, okA := agv.linearTrack(trajA, timeRange)
+		tb, okB := other.linearTrack(trajB, timeRange)
+		if okA && okB {
+			t, ok := analyticCollisionTime(ta, tb, timeRange, collisionThreshold)
+			if !ok {
+				return false, CollisionPrediction{}
+			}
+			pose1 := trajA.At(t)
+			pose2 := trajB.At(t)
+			return true, CollisionPrediction{
+				AGV1:               agv,
+				AGV2:               other,
+				CollisionTime:      t,
+				CollisionPoint:     Point{X: (pose1.X + pose2.X) / 2, Y: (pose1.Y + pose2.Y) / 2, Floor: pose1.Floor},
+				AGV1Pose:           pose1,
+				AGV2Pose:           pose2,
+				Distance:           math.Hypot(pose1.X-pose2.X, pose1.Y-pose2.Y),
+				CollisionThreshold: collisionThreshold,
+			}
+		}
+	}
 
-	// 离散化时间检查
-	for t := 0.0; t <= timeRange; t += timeStep {
+	vmax := agv.maxSpeed() + other.maxSpeed()
+	contact := agv.contactRange(other, collisionThreshold)
+	safe := -1.0 // 上一个未碰撞的采样时刻
+
+	// 按时间递增扫描，首次碰撞即为最早碰撞
+	for t := 0.0; t <= timeRange; {
 		// 预测两车在时间t的位置
-		pose1 := agv.PredictPosition(t)
-		pose2 := other.PredictPosition(t)
-
-		// 计算两车中心距离
-		distance := math.Hypot(pose1.X-pose2.X, pose1.Y-pose2.Y)
-
-		// 检查是否碰撞
-		if distance <= collisionThreshold {
-			// 找到碰撞，记录最早的时间
-			if t < earliestTime {
-				earliestTime = t
-				earliestCollision = CollisionPrediction{
-					AGV1:               agv,
-					AGV2:               other,
-					CollisionTime:      t,
-					CollisionPoint:     Point{(pose1.X + pose2.X) / 2, (pose1.Y + pose2.Y) / 2},
-					AGV1Pose:           pose1,
-					AGV2Pose:           pose2,
-					Distance:           distance,
-					CollisionThreshold: collisionThreshold,
-				}
-				found = true
+		pose1 := trajA.At(t)
+		pose2 := trajB.At(t)
+		collided, distance := agv.collidesAt(other, pose1, pose2, collisionThreshold)
+		if collided {
+			if safe >= 0 {
+				t = agv.bisectCollision(other, trajA, trajB, safe, t, collisionThreshold)
+				pose1 = trajA.At(t)
+				pose2 = trajB.At(t)
+				_, distance = agv.collidesAt(other, pose1, pose2, collisionThreshold)
+			}
+			return true, CollisionPrediction{
+				AGV1:               agv,
+				AGV2:               other,
+				CollisionTime:      t,
+				CollisionPoint:     Point{X: (pose1.X + pose2.X) / 2, Y: (pose1.Y + pose2.Y) / 2, Floor: pose1.Floor},
+				AGV1Pose:           pose1,
+				AGV2Pose:           pose2,
+				Distance:           distance,
+				CollisionThreshold: collisionThreshold,
 			}
 		}
+		if vmax <= 0 || t >= timeRange {
+			break // 两车均静止时不会再发生碰撞
+		}
+
+		// 两车相距较远时，间距消耗完之前不可能接触
+		step := timeStep
+		if pose1.Floor == pose2.Floor {
+			step = math.Max(step, (distance-contact)/vmax)
+		}
+		safe = t
+		t = math.Min(t+step, timeRange)
 	}
+	return false, CollisionPrediction{}
+}
 
-	return found, earliestCollision
+// bisectTolerance 二分求碰撞时间的精度（秒）
+const bisectTolerance = 1e-3
+
+// bisectCollision 在未碰撞时刻lo与碰撞时刻hi之间二分，返回最早检测到碰撞的时刻
+func (agv *AGV) bisectCollision(other *AGV, trajA, trajB *Trajectory, lo, hi, collisionThreshold float64) float64 {
+	for hi-lo > bisectTolerance {
+		mid := (lo + hi) / 2
+		if collided, _ := agv.collidesAt(other, trajA.At(mid), trajB.At(mid), collisionThreshold); collided {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
+
+// maxSpeed AGV速度曲线中的最大速度
+func (agv *AGV) maxSpeed() float64 {
+	return math.Max(agv.Speed, agv.Velocity)
+}
+
+// contactRange 两车可能接触时的最大中心距离，中心距离大于该值时一定未碰撞
+// 矩形或多边形车体按外接半径之和加外扩距离（外扩后的矩形外接半径最多增加√2倍外扩距离）
+func (agv *AGV) contactRange(other *AGV, collisionThreshold float64) float64 {
+	if !agv.hasPolygon() && !other.hasPolygon() && !agv.hasFootprint() && !other.hasFootprint() {
+		return collisionThreshold
+	}
+	margin := math.Max(0, collisionThreshold-(agv.Width+other.Width)/2) / 2
+	return agv.reach() + other.reach() + 2*math.Sqrt2*margin
+}
+
+// collidesAt 判断两车分别位于pose1、pose2时是否碰撞
+// 任一车设置了Length或Polygon时按车体重叠判断，阈值超出两车半宽之和的部分作为车体外扩的安全距离
+// 返回:
+//   bool: 是否碰撞
+//   float64: 两车中心距离
+func (agv *AGV) collidesAt(other *AGV, pose1, pose2 Pose, collisionThreshold float64) (bool, float64) {
+	if pose1.Floor != pose2.Floor {
+		return false, 0
+	}
+	// 计算两车中心距离
+	distance := math.Hypot(pose1.X-pose2.X, pose1.Y-pose2.Y)
+
+	// 矩形车体：两车各外扩一半的额外安全距离
+	margin := math.Max(0, collisionThreshold-(agv.Width+other.Width)/2) / 2
+	switch {
+	case agv.hasPolygon() || other.hasPolygon():
+		return polygonsWithin(agv.Shape(pose1), other.Shape(pose2), 2*margin), distance
+	case agv.hasFootprint() || other.hasFootprint():
+		return agv.Footprint(pose1).Inflate(margin).Intersects(other.Footprint(pose2).Inflate(margin)), distance
+	}
+	return distance <= collisionThreshold, distance
 }
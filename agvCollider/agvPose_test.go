@@ -0,0 +1,124 @@
+package agvCollider
+
+import (
+	"math"
+	"testing"
+)
+
+// 自适应步长和二分只在无法解析求解时使用，用例通过加减速或矩形车体走采样路径
+func TestPredictCollisionWithSampled(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      *AGV
+		timeRange float64
+		timeStep  float64
+		threshold float64
+		collide   bool
+	}{
+		{
+			name:      "远距离相向加速",
+			a:         &AGV{Width: 1, Speed: 2, MaxAccel: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 100, Y: 0}}},
+			b:         &AGV{Pose: Pose{X: 100}, Width: 1, Speed: 2, MaxAccel: 1, SubPath: []Point{{X: 100, Y: 0}, {X: 0, Y: 0}}},
+			timeRange: 60,
+			timeStep:  0.5,
+			threshold: 2,
+			collide:   true,
+		},
+		{
+			name:      "垂直交叉加减速",
+			a:         &AGV{Pose: Pose{Y: -30}, Width: 1, Speed: 1.5, MaxAccel: 0.5, MaxDecel: 0.5, SubPath: []Point{{X: 0, Y: -30}, {X: 0, Y: 30}}},
+			b:         &AGV{Pose: Pose{X: -20}, Width: 1, Speed: 1, MaxAccel: 0.5, MaxDecel: 0.5, SubPath: []Point{{X: -20, Y: 0}, {X: 20, Y: 0}}},
+			timeRange: 60,
+			timeStep:  0.1,
+			threshold: 1,
+			collide:   true,
+		},
+		{
+			// 两车接触时间不足0.5秒，跳过的时间过长会漏检
+			name:      "远距离快速交叉",
+			a:         &AGV{Pose: Pose{Y: -60}, Width: 1, Speed: 3, MaxAccel: 2, SubPath: []Point{{X: 0, Y: -60}, {X: 0, Y: 60}}},
+			b:         &AGV{Pose: Pose{X: -60}, Width: 1, Speed: 3, MaxAccel: 2, SubPath: []Point{{X: -60, Y: 0}, {X: 60, Y: 0}}},
+			timeRange: 60,
+			timeStep:  0.1,
+			threshold: 1,
+			collide:   true,
+		},
+		{
+			name:      "矩形车体驶向静止车辆",
+			a:         &AGV{Width: 1, Length: 2, Speed: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 30, Y: 0}}},
+			b:         &AGV{Pose: Pose{X: 20, T: math.Pi / 2}, Width: 1, Length: 2},
+			timeRange: 60,
+			timeStep:  0.5,
+			threshold: 1.2,
+			collide:   true,
+		},
+		{
+			name:      "平行行驶加速",
+			a:         &AGV{Width: 1, Speed: 2, MaxAccel: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 50, Y: 0}}},
+			b:         &AGV{Pose: Pose{Y: 5}, Width: 1, Speed: 2, MaxAccel: 1, SubPath: []Point{{X: 0, Y: 5}, {X: 50, Y: 5}}},
+			timeRange: 60,
+			timeStep:  0.5,
+			threshold: 2,
+			collide:   false,
+		},
+		{
+			name:      "不同楼层",
+			a:         &AGV{Width: 1, Speed: 2, MaxAccel: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 20, Y: 0}}},
+			b:         &AGV{Floor: 1, Pose: Pose{X: 10, Floor: 1}, Width: 1},
+			timeRange: 60,
+			timeStep:  0.5,
+			threshold: 2,
+			collide:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, prediction := tt.a.PredictCollisionWith(tt.b, tt.timeRange, tt.timeStep, tt.threshold)
+			if ok != tt.collide {
+				t.Fatalf("PredictCollisionWith() collide = %v, want %v", ok, tt.collide)
+			}
+			if tt.a.Floor != tt.b.Floor {
+				return
+			}
+
+			want, sok := sampledCollisionTime(tt.a, tt.b, tt.timeRange, tt.threshold)
+			if sok != ok {
+				t.Fatalf("逐点采样 collide = %v, 自适应步长 %v", sok, ok)
+			}
+			// 二分结果为碰撞区间内的时刻，与真实碰撞时刻相差不超过二分精度
+			if ok && math.Abs(prediction.CollisionTime-want) > bisectTolerance+denseStep {
+				t.Errorf("CollisionTime = %v, 逐点采样 %v", prediction.CollisionTime, want)
+			}
+		})
+	}
+}
+
+func TestBisectCollision(t *testing.T) {
+	a := &AGV{Width: 1, Speed: 1, MaxAccel: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 20, Y: 0}}}
+	b := &AGV{Pose: Pose{X: 10}, Width: 1}
+	ta, tb := a.Trajectory(0), b.Trajectory(0)
+	want, ok := sampledCollisionTime(a, b, 30, 1)
+	if !ok {
+		t.Fatal("逐点采样未检测到碰撞")
+	}
+
+	tests := []struct {
+		name   string
+		lo, hi float64
+	}{
+		{"宽区间", 0, 20},
+		{"窄区间", want - 0.01, want + 0.01},
+		{"碰撞时刻靠近上界", 0, want + bisectTolerance/2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := a.bisectCollision(b, ta, tb, tt.lo, tt.hi, 1)
+			if got < want-denseStep || got > want+bisectTolerance {
+				t.Errorf("bisectCollision() = %v, 逐点采样 %v", got, want)
+			}
+			if collided, _ := a.collidesAt(b, ta.At(got), tb.At(got), 1); !collided {
+				t.Errorf("bisectCollision() = %v 时未碰撞", got)
+			}
+		})
+	}
+}
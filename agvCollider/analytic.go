@@ -0,0 +1,120 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+)
+
+// ===================== 连续时间碰撞求解 =====================
+
+// linearTrack AGV沿直线路径匀速行驶时的运动轨迹
+// 相邻两个断点之间AGV做匀速直线运动（或静止），位置随时间线性变化
+type linearTrack struct {
+	*Trajectory
+	breaks []float64 // 速度或方向发生变化的时刻
+}
+
+// linearTrack 构建AGV在[0, horizon]内的分段匀速运动轨迹
+// 仅当子路径全部为直线且速度曲线无加减速（瞬时起停）时可用
+// 返回:
+//
+//	*linearTrack: 运动轨迹
+//	bool: 是否可用解析方法求解
+func (agv *AGV) linearTrack(tr *Trajectory, horizon float64) (*linearTrack, bool) {
+	lt := &linearTrack{Trajectory: tr}
+	if len(tr.segs) == 0 {
+		return lt, true
+	}
+	if agv.MaxAccel > 0 || agv.MaxDecel > 0 {
+		return nil, false
+	}
+	for _, seg := range tr.segs {
+		if seg.arc != nil {
+			return nil, false
+		}
+	}
+
+	addBreak := func(t float64) {
+		if t > 0 && t < horizon {
+			lt.breaks = append(lt.breaks, t)
+		}
+	}
+	for _, s := range tr.cum[1:] {
+		addBreak(tr.T0 + tr.legs.timeAt(s))
+	}
+	for _, leg := range tr.legs {
+		addBreak(tr.T0 + leg.t0)
+		addBreak(tr.T0 + leg.t0 + leg.duration())
+	}
+	return lt, true
+}
+
+// at 计算t时刻的位置
+func (lt *linearTrack) at(t float64) Point {
+	p := lt.At(t)
+	return Point{X: p.X, Y: p.Y, Floor: p.Floor}
+}
+
+// contactTime 两点在dt时长内分别从a0、b0匀速运动到a1、b1，求两点距离首次不超过r的时刻
+// 解 |d + w·τ|² = r² 的较小根
+// 返回:
+//
+//	float64: 首次接触时刻（相对区间起点，0~dt）
+//	bool: 区间内是否接触
+func contactTime(a0, a1, b0, b1 Point, dt, r float64) (float64, bool) {
+	dx, dy, wx, wy := relativeMotion(a0, a1, b0, b1, dt)
+	c := dx*dx + dy*dy - r*r
+	if c <= 0 {
+		return 0, true
+	}
+	a := wx*wx + wy*wy
+	b := 2 * (dx*wx + dy*wy)
+	disc := b*b - 4*a*c
+	if a == 0 || b >= 0 || disc < 0 {
+		return 0, false
+	}
+	tau := (-b - math.Sqrt(disc)) / (2 * a)
+	if tau > dt {
+		return 0, false
+	}
+	return tau, true
+}
+
+// relativeMotion 区间起点时b相对a的位置d及相对速度w
+func relativeMotion(a0, a1, b0, b1 Point, dt float64) (dx, dy, wx, wy float64) {
+	dx, dy = b0.X-a0.X, b0.Y-a0.Y
+	if dt > 0 {
+		wx = ((b1.X - b0.X) - (a1.X - a0.X)) / dt
+		wy = ((b1.Y - b0.Y) - (a1.Y - a0.Y)) / dt
+	}
+	return dx, dy, wx, wy
+}
+
+// analyticCollisionTime 按两车分段匀速运动解析求解首次进入碰撞距离阈值的时刻
+// 两车轨迹的断点合并后，每个区间内相对运动为匀速直线，碰撞时刻有闭式解，无采样误差
+// 返回:
+//
+//	float64: 碰撞时刻
+//	bool: [0, timeRange]内是否碰撞
+func analyticCollisionTime(ta, tb *linearTrack, timeRange, collisionThreshold float64) (float64, bool) {
+	times := append([]float64{0, timeRange}, ta.breaks...)
+	times = append(times, tb.breaks...)
+	sort.Float64s(times)
+
+	a0, b0 := ta.at(0), tb.at(0)
+	for k := 1; k < len(times); k++ {
+		t0, t1 := times[k-1], times[k]
+		if t1 <= t0 {
+			continue
+		}
+		a1, b1 := ta.at(t1), tb.at(t1)
+		// 区间端点楼层不一致时为跨层转运，不参与平面碰撞检测
+		if a0.Floor == b0.Floor && a1.Floor == b1.Floor && a0.Floor == a1.Floor {
+			if tau, ok := contactTime(a0, a1, b0, b1, t1-t0, collisionThreshold); ok {
+				return t0 + tau, true
+			}
+		}
+		a0, b0 = a1, b1
+	}
+	return 0, false
+}
@@ -0,0 +1,164 @@
+package agvCollider
+
+import (
+	"math"
+	"testing"
+)
+
+// denseStep 逐点采样的步长（秒），作为解析解和自适应步长的参照
+const denseStep = 1e-4
+
+// sampledCollisionTime 按denseStep逐点采样，返回首次碰撞的时刻
+func sampledCollisionTime(a, b *AGV, timeRange, collisionThreshold float64) (float64, bool) {
+	ta, tb := a.Trajectory(0), b.Trajectory(0)
+	for i := 0; ; i++ {
+		t := float64(i) * denseStep
+		if t > timeRange {
+			return 0, false
+		}
+		if collided, _ := a.collidesAt(b, ta.At(t), tb.At(t), collisionThreshold); collided {
+			return t, true
+		}
+	}
+}
+
+func TestAnalyticCollisionTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      *AGV
+		timeRange float64
+		threshold float64
+		want      float64
+		collide   bool
+	}{
+		{
+			name:      "相向行驶",
+			a:         &AGV{Speed: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 20, Y: 0}}},
+			b:         &AGV{Pose: Pose{X: 20}, Speed: 1, SubPath: []Point{{X: 20, Y: 0}, {X: 0, Y: 0}}},
+			timeRange: 30,
+			threshold: 2,
+			want:      9,
+			collide:   true,
+		},
+		{
+			name:      "垂直交叉",
+			a:         &AGV{Pose: Pose{Y: -10}, Speed: 1, SubPath: []Point{{X: 0, Y: -10}, {X: 0, Y: 10}}},
+			b:         &AGV{Pose: Pose{X: -10}, Speed: 1, SubPath: []Point{{X: -10, Y: 0}, {X: 10, Y: 0}}},
+			timeRange: 30,
+			threshold: 1,
+			want:      10 - 1/math.Sqrt2,
+			collide:   true,
+		},
+		{
+			name:      "驶向静止车辆",
+			a:         &AGV{Speed: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}}},
+			b:         &AGV{Pose: Pose{X: 5}},
+			timeRange: 30,
+			threshold: 1,
+			want:      4,
+			collide:   true,
+		},
+		{
+			name:      "拐弯后接近",
+			a:         &AGV{Speed: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 5, Y: 5}}},
+			b:         &AGV{Pose: Pose{X: 5, Y: 4}},
+			timeRange: 30,
+			threshold: 1,
+			want:      8,
+			collide:   true,
+		},
+		{
+			name:      "停靠后继续行驶",
+			a:         &AGV{Speed: 1, Stops: []StopPoint{{S: 5, Dwell: 3}}, SubPath: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}}},
+			b:         &AGV{Pose: Pose{X: 8}},
+			timeRange: 30,
+			threshold: 1,
+			want:      10,
+			collide:   true,
+		},
+		{
+			name:      "起始时已重叠",
+			a:         &AGV{Speed: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}}},
+			b:         &AGV{Pose: Pose{X: 0.5}},
+			timeRange: 30,
+			threshold: 1,
+			want:      0,
+			collide:   true,
+		},
+		{
+			name:      "平行行驶",
+			a:         &AGV{Speed: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}}},
+			b:         &AGV{Pose: Pose{Y: 5}, Speed: 1, SubPath: []Point{{X: 0, Y: 5}, {X: 10, Y: 5}}},
+			timeRange: 30,
+			threshold: 2,
+			collide:   false,
+		},
+		{
+			name:      "时间范围内未接触",
+			a:         &AGV{Speed: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 20, Y: 0}}},
+			b:         &AGV{Pose: Pose{X: 20}, Speed: 1, SubPath: []Point{{X: 20, Y: 0}, {X: 0, Y: 0}}},
+			timeRange: 8,
+			threshold: 2,
+			collide:   false,
+		},
+		{
+			name:      "交叉点位于不同楼层",
+			a:         &AGV{Pose: Pose{Y: -10}, Speed: 1, SubPath: []Point{{X: 0, Y: -10}, {X: 0, Y: 10}}},
+			b:         &AGV{Pose: Pose{X: -10, Floor: 1}, Speed: 1, SubPath: []Point{{X: -10, Y: 0, Floor: 1}, {X: 10, Y: 0, Floor: 1}}},
+			timeRange: 30,
+			threshold: 1,
+			collide:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta, okA := tt.a.linearTrack(tt.a.Trajectory(0), tt.timeRange)
+			tb, okB := tt.b.linearTrack(tt.b.Trajectory(0), tt.timeRange)
+			if !okA || !okB {
+				t.Fatalf("linearTrack不可用: %v %v", okA, okB)
+			}
+
+			got, ok := analyticCollisionTime(ta, tb, tt.timeRange, tt.threshold)
+			if ok != tt.collide {
+				t.Fatalf("analyticCollisionTime() collide = %v, want %v", ok, tt.collide)
+			}
+			if ok && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("analyticCollisionTime() = %v, want %v", got, tt.want)
+			}
+
+			// 与逐点采样的结果一致
+			sampled, sok := sampledCollisionTime(tt.a, tt.b, tt.timeRange, tt.threshold)
+			if sok != ok {
+				t.Fatalf("采样结果 collide = %v, 解析结果 %v", sok, ok)
+			}
+			if ok && (sampled < got-1e-9 || sampled > got+denseStep) {
+				t.Errorf("采样碰撞时刻 %v 与解析解 %v 不一致", sampled, got)
+			}
+		})
+	}
+}
+
+func TestLinearTrackUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		agv  *AGV
+	}{
+		{"有加速度", &AGV{Speed: 1, MaxAccel: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}}}},
+		{"有减速度", &AGV{Speed: 1, MaxDecel: 1, SubPath: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}}}},
+		{
+			"圆弧路径",
+			&AGV{
+				Speed:   1,
+				Arcs:    []Arc{{Center: Point{X: 0, Y: 0}, Radius: 5, StartAngle: 0, EndAngle: math.Pi / 2}},
+				SubPath: []Point{{X: 5, Y: 0}, {X: 0, Y: 5}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.agv.linearTrack(tt.agv.Trajectory(0), 30); ok {
+				t.Errorf("linearTrack() 应不可用")
+			}
+		})
+	}
+}
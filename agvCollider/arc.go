@@ -0,0 +1,367 @@
+package agvCollider
+
+import "math"
+
+// arcEps 判断点是否位于圆弧上的距离容差
+const arcEps = 1e-6
+
+// arcPieceAngle 车体检测时圆弧拆分为弦的最大圆心角（10°）
+const arcPieceAngle = math.Pi / 18
+
+// ===================== 圆弧 =====================
+
+// Arc 表示圆弧路径段
+// - Center:     圆心
+// - Radius:     半径
+// - StartAngle: 起点角度，单位弧度
+// - EndAngle:   终点角度，单位弧度；大于StartAngle为逆时针行驶，小于为顺时针行驶
+type Arc struct {
+	Center     Point
+	Radius     float64
+	StartAngle float64
+	EndAngle   float64
+}
+
+// Sweep 圆心角，逆时针为正
+func (a Arc) Sweep() float64 {
+	return a.EndAngle - a.StartAngle
+}
+
+// Length 弧长
+func (a Arc) Length() float64 {
+	return a.Radius * math.Abs(a.Sweep())
+}
+
+// StartPoint 圆弧起点
+func (a Arc) StartPoint() Point {
+	return a.PointAt(0)
+}
+
+// EndPoint 圆弧终点
+func (a Arc) EndPoint() Point {
+	return a.PointAt(1)
+}
+
+// PointAt 按比例插值圆弧上的点
+// 参数:
+//
+//	ratio: 插值比例 (0=起点, 1=终点)
+//
+// 返回:
+//
+//	Point: 圆弧上的点
+func (a Arc) PointAt(ratio float64) Point {
+	theta := a.StartAngle + a.Sweep()*ratio
+	return Point{
+		X:     a.Center.X + a.Radius*math.Cos(theta),
+		Y:     a.Center.Y + a.Radius*math.Sin(theta),
+		Floor: a.Center.Floor,
+	}
+}
+
+// HeadingAt 按比例计算圆弧上的行驶航向（切线方向）
+func (a Arc) HeadingAt(ratio float64) float64 {
+	theta := a.StartAngle + a.Sweep()*ratio
+	if a.Sweep() >= 0 {
+		return theta + math.Pi/2
+	}
+	return theta - math.Pi/2
+}
+
+// angleRatio 计算角度theta在圆弧上的比例
+// 返回:
+//
+//	float64: 比例 (0=起点, 1=终点)
+//	bool:    角度是否落在圆弧范围内
+func (a Arc) angleRatio(theta float64) (float64, bool) {
+	sweep := a.Sweep()
+	if sweep == 0 {
+		return 0, false
+	}
+	u := math.Mod(theta-a.StartAngle, 2*math.Pi)
+	if sweep < 0 {
+		u = -u
+	}
+	if u < 0 {
+		u += 2 * math.Pi
+	}
+	r := u / math.Abs(sweep)
+	return r, r <= 1+arcEps
+}
+
+// contains 判断点p是否位于圆弧上（同一楼层），返回其比例
+func (a Arc) contains(p Point) (float64, bool) {
+	if p.Floor != a.Center.Floor || math.Abs(getDistance(a.Center, p)-a.Radius) > arcEps {
+		return 0, false
+	}
+	r, ok := a.angleRatio(math.Atan2(p.Y-a.Center.Y, p.X-a.Center.X))
+	return math.Min(r, 1), ok
+}
+
+// project 将点p投影到圆弧上
+// 返回:
+//
+//	Point: 圆弧上距离p最近的点
+//	float64: 投影比例 (0=起点, 1=终点)
+func (a Arc) project(p Point) (Point, float64) {
+	if p != a.Center {
+		if r, ok := a.angleRatio(math.Atan2(p.Y-a.Center.Y, p.X-a.Center.X)); ok {
+			r = math.Min(r, 1)
+			return a.PointAt(r), r
+		}
+	}
+	start, end := a.StartPoint(), a.EndPoint()
+	if getDistance(p, start) <= getDistance(p, end) {
+		return start, 0
+	}
+	return end, 1
+}
+
+// sub 截取比例r0到r1之间的圆弧
+func (a Arc) sub(r0, r1 float64) Arc {
+	sweep := a.Sweep()
+	return Arc{
+		Center:     a.Center,
+		Radius:     a.Radius,
+		StartAngle: a.StartAngle + sweep*r0,
+		EndAngle:   a.StartAngle + sweep*r1,
+	}
+}
+
+// ===================== 路径段（直线/圆弧） =====================
+
+// pathSeg 路径中相邻两点之间的一段，arc为nil时为直线
+type pathSeg struct {
+	Segment
+	arc *Arc
+}
+
+// floor 路径段所在楼层，两端楼层不同（跨层转运）时返回false
+func (s pathSeg) floor() (int, bool) {
+	return s.Start.Floor, s.Start.Floor == s.End.Floor
+}
+
+// length 路径段长度
+func (s pathSeg) length() float64 {
+	if s.arc != nil {
+		return s.arc.Length()
+	}
+	return getDistance(s.Start, s.End)
+}
+
+// pointAt 按比例插值路径段上的点
+func (s pathSeg) pointAt(ratio float64) Point {
+	if s.arc != nil {
+		return s.arc.PointAt(ratio)
+	}
+	return interpolate(s.Start, s.End, ratio)
+}
+
+// headingAt 按比例计算路径段上的行驶航向
+func (s pathSeg) headingAt(ratio float64) float64 {
+	if s.arc != nil {
+		return s.arc.HeadingAt(ratio)
+	}
+	return math.Atan2(s.End.Y-s.Start.Y, s.End.X-s.Start.X)
+}
+
+// project 将点p投影到路径段上，返回投影点和比例
+func (s pathSeg) project(p Point) (Point, float64) {
+	if s.arc != nil {
+		return s.arc.project(p)
+	}
+	return projectPointOnSegment(Pose{X: p.X, Y: p.Y, Floor: p.Floor}, s.Segment)
+}
+
+// pieces 将路径段拆分为若干弦，用于车体扫掠检测
+// 返回:
+//
+//	[]Segment: 弦线段（直线段返回自身）
+//	float64:   弦与圆弧之间的最大偏差（弓高），检测时作为外扩距离
+func (s pathSeg) pieces() ([]Segment, float64) {
+	if s.arc == nil {
+		return []Segment{s.Segment}, 0
+	}
+	n := int(math.Ceil(math.Abs(s.arc.Sweep()) / arcPieceAngle))
+	if n < 1 {
+		n = 1
+	}
+	out := make([]Segment, n)
+	for i := 0; i < n; i++ {
+		out[i] = Segment{
+			Start: s.arc.PointAt(float64(i) / float64(n)),
+			End:   s.arc.PointAt(float64(i+1) / float64(n)),
+		}
+	}
+	half := math.Abs(s.arc.Sweep()) / float64(n) / 2
+	return out, s.arc.Radius * (1 - math.Cos(half))
+}
+
+// pathSegs 将路径点转换为路径段
+// 相邻两点均位于 Arcs 中某段圆弧上时，两点之间按该圆弧行驶，否则按直线行驶
+func (agv *AGV) pathSegs(path []Point) []pathSeg {
+	if len(path) < 2 {
+		return nil
+	}
+	segs := make([]pathSeg, len(path)-1)
+	for i := range segs {
+		segs[i] = pathSeg{Segment: Segment{Start: path[i], End: path[i+1]}}
+		if path[i] == path[i+1] {
+			continue
+		}
+		for _, a := range agv.Arcs {
+			r0, ok0 := a.contains(path[i])
+			r1, ok1 := a.contains(path[i+1])
+			if ok0 && ok1 && r0 != r1 {
+				arc := a.sub(r0, r1)
+				segs[i].arc = &arc
+				break
+			}
+		}
+	}
+	return segs
+}
+
+// ===================== 曲线最近点 =====================
+
+// closestPoints 两条路径段上距离最近的一对点，相交时均为交点
+func closestPoints(s1, s2 pathSeg) (Point, Point) {
+	if s1.arc == nil && s2.arc == nil {
+		return closestSegmentPoints(s1.Segment, s2.Segment)
+	}
+
+	// 候选点对：端点到对方的投影、交点、内部极值点
+	pairs := [][2]Point{}
+	for _, p := range []Point{s1.Start, s1.End} {
+		q, _ := s2.project(p)
+		pairs = append(pairs, [2]Point{p, q})
+	}
+	for _, q := range []Point{s2.Start, s2.End} {
+		p, _ := s1.project(q)
+		pairs = append(pairs, [2]Point{p, q})
+	}
+	for _, p := range curveIntersections(s1, s2) {
+		pairs = append(pairs, [2]Point{p, p})
+	}
+	pairs = append(pairs, interiorPairs(s1, s2)...)
+
+	best := pairs[0]
+	minD := getDistance(best[0], best[1])
+	for _, p := range pairs[1:] {
+		if d := getDistance(p[0], p[1]); d < minD {
+			minD = d
+			best = p
+		}
+	}
+	return best[0], best[1]
+}
+
+// onSeg 判断点p是否位于路径段上
+func onSeg(s pathSeg, p Point) bool {
+	q, _ := s.project(p)
+	return getDistance(p, q) <= arcEps
+}
+
+// curveIntersections 计算两条路径段（至少一条为圆弧）的交点
+func curveIntersections(s1, s2 pathSeg) []Point {
+	var cands []Point
+	switch {
+	case s1.arc != nil && s2.arc != nil:
+		cands = circleCircle(s1.arc.Center, s1.arc.Radius, s2.arc.Center, s2.arc.Radius)
+	case s1.arc != nil:
+		cands = lineCircle(s2.Segment, s1.arc.Center, s1.arc.Radius)
+	default:
+		cands = lineCircle(s1.Segment, s2.arc.Center, s2.arc.Radius)
+	}
+	out := cands[:0]
+	for _, p := range cands {
+		if onSeg(s1, p) && onSeg(s2, p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// interiorPairs 计算两条路径段内部的距离极值点对
+// 直线-圆弧：圆心到直线的垂足方向；圆弧-圆弧：圆心连线方向
+func interiorPairs(s1, s2 pathSeg) [][2]Point {
+	var pairs [][2]Point
+	add := func(p, q Point) {
+		if onSeg(s1, p) && onSeg(s2, q) {
+			pairs = append(pairs, [2]Point{p, q})
+		}
+	}
+	onCircle := func(c Point, r, dx, dy float64) Point {
+		return Point{X: c.X + r*dx, Y: c.Y + r*dy, Floor: c.Floor}
+	}
+
+	switch {
+	case s1.arc != nil && s2.arc != nil:
+		c1, c2 := s1.arc.Center, s2.arc.Center
+		d := getDistance(c1, c2)
+		if d == 0 {
+			// 同心圆弧：任一公共角度上距离均为半径差
+			if r, ok := s2.arc.angleRatio(s1.arc.StartAngle); ok {
+				add(s1.Start, s2.arc.PointAt(math.Min(r, 1)))
+			}
+			return pairs
+		}
+		dx, dy := (c2.X-c1.X)/d, (c2.Y-c1.Y)/d
+		for _, k1 := range []float64{1, -1} {
+			for _, k2 := range []float64{1, -1} {
+				add(onCircle(c1, s1.arc.Radius, k1*dx, k1*dy), onCircle(c2, s2.arc.Radius, k2*dx, k2*dy))
+			}
+		}
+	case s1.arc != nil:
+		f := closestPointOnSegment(s1.arc.Center, s2.Segment)
+		if d := getDistance(s1.arc.Center, f); d > 0 {
+			dx, dy := (f.X-s1.arc.Center.X)/d, (f.Y-s1.arc.Center.Y)/d
+			add(onCircle(s1.arc.Center, s1.arc.Radius, dx, dy), f)
+		}
+	default:
+		f := closestPointOnSegment(s2.arc.Center, s1.Segment)
+		if d := getDistance(s2.arc.Center, f); d > 0 {
+			dx, dy := (f.X-s2.arc.Center.X)/d, (f.Y-s2.arc.Center.Y)/d
+			add(f, onCircle(s2.arc.Center, s2.arc.Radius, dx, dy))
+		}
+	}
+	return pairs
+}
+
+// lineCircle 计算线段所在直线与圆的交点
+func lineCircle(seg Segment, c Point, r float64) []Point {
+	dx, dy := seg.End.X-seg.Start.X, seg.End.Y-seg.Start.Y
+	fx, fy := seg.Start.X-c.X, seg.Start.Y-c.Y
+	a := dot(dx, dy, dx, dy)
+	if a == 0 {
+		return nil
+	}
+	b := 2 * dot(fx, fy, dx, dy)
+	disc := b*b - 4*a*(dot(fx, fy, fx, fy)-r*r)
+	if disc < 0 {
+		return nil
+	}
+	sq := math.Sqrt(disc)
+	t1, t2 := (-b-sq)/(2*a), (-b+sq)/(2*a)
+	return []Point{
+		{X: seg.Start.X + t1*dx, Y: seg.Start.Y + t1*dy, Floor: seg.Start.Floor},
+		{X: seg.Start.X + t2*dx, Y: seg.Start.Y + t2*dy, Floor: seg.Start.Floor},
+	}
+}
+
+// circleCircle 计算两圆的交点
+func circleCircle(c1 Point, r1 float64, c2 Point, r2 float64) []Point {
+	d := getDistance(c1, c2)
+	if d == 0 || d > r1+r2 || d < math.Abs(r1-r2) {
+		return nil
+	}
+	a := (r1*r1 - r2*r2 + d*d) / (2 * d)
+	h := math.Sqrt(math.Max(0, r1*r1-a*a))
+	mx := c1.X + a*(c2.X-c1.X)/d
+	my := c1.Y + a*(c2.Y-c1.Y)/d
+	ox, oy := h*(c2.Y-c1.Y)/d, h*(c2.X-c1.X)/d
+	return []Point{
+		{X: mx + ox, Y: my - oy, Floor: c1.Floor},
+		{X: mx - ox, Y: my + oy, Floor: c1.Floor},
+	}
+}
@@ -0,0 +1,139 @@
+package agvCollider
+
+import "math"
+
+// boundsChunkSize 路径分块包围盒中每块包含的路径段数
+const boundsChunkSize = 8
+
+// ===================== 路径包围盒 =====================
+
+// pathBounds AGV路径的包围盒缓存（均按车体外接半径外扩）
+// - segs:   路径段
+// - boxes:  各路径段的包围盒，跨层转运段不参与检测
+// - chunks: 同一楼层连续路径段的分块包围盒
+// - box:    整条路径（不含转运段）的包围盒
+type pathBounds struct {
+	path   []Point
+	arcs   []Arc
+	reach  float64
+	segs   []pathSeg
+	length float64
+	boxes  [][4]float64
+	chunks []boundsChunk
+	box    [4]float64
+	empty  bool
+}
+
+// boundsChunk 连续路径段[lo, hi)的包围盒
+type boundsChunk struct {
+	lo, hi int
+	floor  int
+	box    [4]float64
+}
+
+// reach 车体外接半径：车体中心到轮廓最远点的距离
+// 未设置Length的AGV与矩形车体检测时按 Width×Width 的正方形处理，取其半对角线
+func (agv *AGV) reach() float64 {
+	length := agv.Length
+	if length <= 0 {
+		length = agv.Width
+	}
+	return math.Max(math.Hypot(length, agv.Width)/2, agv.Polygon.Radius())
+}
+
+// pathBounds 返回路径包围盒，Path、Arcs或车体尺寸变化后自动重建
+// 原地修改Path中的路径点后需调用 ResetPathBounds
+func (agv *AGV) pathBounds() *pathBounds {
+	b := agv.bounds
+	reach := agv.reach()
+	if b != nil && sameSlice(b.path, agv.Path) && sameSlice(b.arcs, agv.Arcs) && b.reach == reach {
+		return b
+	}
+	b = newPathBounds(agv, reach)
+	agv.bounds = b
+	return b
+}
+
+// ResetPathBounds 清除路径包围盒缓存
+func (agv *AGV) ResetPathBounds() {
+	agv.bounds = nil
+}
+
+// sameSlice 判断两个切片是否引用同一段底层数组
+func sameSlice[T any](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
+
+// newPathBounds 计算路径段、分块和整条路径的包围盒
+func newPathBounds(agv *AGV, reach float64) *pathBounds {
+	b := &pathBounds{
+		path:  agv.Path,
+		arcs:  agv.Arcs,
+		reach: reach,
+		segs:  agv.pathSegs(agv.Path),
+		box:   emptyBox(),
+		empty: true,
+	}
+	b.length = pathLength(b.segs)
+	b.boxes = make([][4]float64, len(b.segs))
+
+	var cur *boundsChunk
+	for i, seg := range b.segs {
+		floor, ok := seg.floor()
+		if !ok {
+			cur = nil
+			continue
+		}
+		box := segBBox(seg, reach)
+		b.boxes[i] = box
+		b.box = unionBox(b.box, box)
+		b.empty = false
+
+		if cur == nil || cur.floor != floor || cur.hi-cur.lo >= boundsChunkSize {
+			b.chunks = append(b.chunks, boundsChunk{lo: i, hi: i, floor: floor, box: emptyBox()})
+			cur = &b.chunks[len(b.chunks)-1]
+		}
+		cur.hi = i + 1
+		cur.box = unionBox(cur.box, box)
+	}
+	return b
+}
+
+// overlaps 判断两条路径的整体包围盒是否重叠
+func (b *pathBounds) overlaps(o *pathBounds) bool {
+	return !b.empty && !o.empty && boxesOverlap(b.box, o.box)
+}
+
+// chunkPairs 返回每个分块与另一条路径中包围盒重叠的分块下标（同楼层）
+func (b *pathBounds) chunkPairs(o *pathBounds) [][]int {
+	pairs := make([][]int, len(b.chunks))
+	for i, c := range b.chunks {
+		if !boxesOverlap(c.box, o.box) {
+			continue
+		}
+		for j, d := range o.chunks {
+			if c.floor == d.floor && boxesOverlap(c.box, d.box) {
+				pairs[i] = append(pairs[i], j)
+			}
+		}
+	}
+	return pairs
+}
+
+// emptyBox 空包围盒，与任意包围盒合并后即为该包围盒
+func emptyBox() [4]float64 {
+	return [4]float64{math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64}
+}
+
+// unionBox 合并两个包围盒
+func unionBox(a, b [4]float64) [4]float64 {
+	return [4]float64{math.Min(a[0], b[0]), math.Min(a[1], b[1]), math.Max(a[2], b[2]), math.Max(a[3], b[3])}
+}
+
+// boxesOverlap 判断两个包围盒是否重叠（含边界接触）
+func boxesOverlap(a, b [4]float64) bool {
+	return a[0] <= b[2] && b[0] <= a[2] && a[1] <= b[3] && b[1] <= a[3]
+}
@@ -0,0 +1,149 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+)
+
+// ===================== 多车冲突簇 =====================
+
+// ConflictCluster 表示一组互相关联的碰撞事件（如繁忙路口的多车冲突）
+// - Events:  簇内的碰撞事件
+// - Order:   通行顺序
+// - Actions: 按通行顺序排列的调度动作，每辆AGV一个
+type ConflictCluster struct {
+	Events  []CollisionEvent
+	Order   []*AGV
+	Actions []ScheduleAction
+}
+
+// clusterEvents 将碰撞事件分组：同一楼层冲突点距离不超过radius且时间窗（含safeGap）重叠的事件归为一簇
+// 共享同一AGV但相隔较远（时间或空间）的事件分属不同的簇，各自按冲突点排序放行
+func clusterEvents(events []CollisionEvent, radius, safeGap float64) [][]CollisionEvent {
+	parent := make([]int, len(events))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int) {
+		if ri, rj := find(i), find(j); ri != rj {
+			parent[rj] = ri
+		}
+	}
+
+	window := func(e CollisionEvent) (float64, float64) {
+		return math.Min(e.Time1, e.Time2), math.Max(e.Time1, e.Time2)
+	}
+
+	for i := range events {
+		for j := i + 1; j < len(events); j++ {
+			// 不同楼层的冲突点平面坐标可能重合，不属于同一冲突
+			if events[i].Point.Floor != events[j].Point.Floor || getDistance(events[i].Point, events[j].Point) > radius {
+				continue
+			}
+			s1, e1 := window(events[i])
+			s2, e2 := window(events[j])
+			if s1 <= e2+safeGap && s2 <= e1+safeGap {
+				union(i, j)
+			}
+		}
+	}
+
+	groups := make(map[int][]CollisionEvent)
+	var roots []int
+	for i, e := range events {
+		r := find(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], e)
+	}
+	out := make([][]CollisionEvent, 0, len(roots))
+	for _, r := range roots {
+		out = append(out, groups[r])
+	}
+	return out
+}
+
+// ResolveClusters 按簇进行调度决策，为簇内所有AGV给出一致的通行顺序
+// 同一AGV可出现在多个簇中（途经多个冲突点），每个簇给出一个动作；
+// 簇内每辆AGV取其最早的到达时间，按让行策略排序；
+// 后车的放行时间 = max(自身到达时间, 前车放行时间 + safeGap)，等待时间为两者之差
+// 参数:
+//
+//	events: 碰撞事件
+//	radius: 冲突点聚类半径 (m)
+//	safeGap: 安全时间间隔 (s)
+//	policy: 让行策略，为nil时使用 DefaultPriorityPolicy
+//
+// 返回:
+//
+//	[]ConflictCluster: 冲突簇及调度动作
+func ResolveClusters(events []CollisionEvent, radius, safeGap float64, policy PriorityPolicy) []ConflictCluster {
+	if policy == nil {
+		policy = DefaultPriorityPolicy
+	}
+
+	var out []ConflictCluster
+	for _, group := range clusterEvents(events, radius, safeGap) {
+		// 每辆AGV最早的到达时间及对应事件
+		arrival := make(map[int]float64)
+		first := make(map[int]CollisionEvent)
+		agvs := make(map[int]*AGV)
+		note := func(agv *AGV, t float64, e CollisionEvent) {
+			if old, ok := arrival[agv.Id]; !ok || t < old {
+				arrival[agv.Id] = t
+				first[agv.Id] = e
+			}
+			agvs[agv.Id] = agv
+		}
+		for _, e := range group {
+			note(e.AGV1, e.Time1, e)
+			note(e.AGV2, e.Time2, e)
+		}
+
+		inputs := make([]PriorityInput, 0, len(agvs))
+		for id, agv := range agvs {
+			inputs = append(inputs, newPriorityInput(agv, arrival[id]))
+		}
+		sort.Slice(inputs, func(i, j int) bool { return inputs[i].AGV.Id < inputs[j].AGV.Id })
+		sort.SliceStable(inputs, func(i, j int) bool {
+			return policy.Prefer(inputs[i], inputs[j]) && !policy.Prefer(inputs[j], inputs[i])
+		})
+
+		c := ConflictCluster{Events: group}
+		release := math.Inf(-1)
+		for i, in := range inputs {
+			c.Order = append(c.Order, in.AGV)
+			start := in.Arrival
+			if i > 0 {
+				start = math.Max(in.Arrival, release+safeGap)
+			}
+			release = start
+
+			act := ScheduleAction{AGV: in.AGV, Action: ActionGo, Collision: first[in.AGV.Id]}
+			if wait := start - in.Arrival; wait > 0 {
+				act.Action = ActionWait
+				act.WaitTime = wait
+			}
+			c.Actions = append(c.Actions, act)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// ScheduleClusters 使用KD树检测潜在碰撞，并按冲突簇下发调度建议
+// 参数:
+//
+//	radius: KD树范围查询半径，同时作为冲突点聚类半径
+func (s *Scheduler) ScheduleClusters(agvs []*AGV, tol, radius float64) []ConflictCluster {
+	events := DetectCollisionsWithKDTree(agvs, tol, radius)
+	return ResolveClusters(events, radius, s.SafeGap, s.Policy)
+}
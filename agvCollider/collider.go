@@ -0,0 +1,183 @@
+package agvCollider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ===================== 事件订阅 =====================
+
+// Collider 碰撞检测器，按周期检测并向订阅者推送事件
+// - 新出现的碰撞推送 OnCollisionPredicted，并立即推送对应的 OnScheduleIssued
+// - 持续存在的碰撞不重复推送，消失后推送 OnCollisionCleared
+type Collider struct {
+	Scheduler *Scheduler
+
+	mu        sync.RWMutex
+	nextID    int
+	predicted map[int]func(CollisionEvent)
+	cleared   map[int]func(CollisionEvent)
+	issued    map[int]func(ScheduleAction)
+	active    map[[2]int]CollisionEvent
+}
+
+// NewCollider 创建碰撞检测器
+// 参数:
+//
+//	scheduler: 调度器，为nil时使用零值 Scheduler
+func NewCollider(scheduler *Scheduler) *Collider {
+	if scheduler == nil {
+		scheduler = &Scheduler{}
+	}
+	return &Collider{
+		Scheduler: scheduler,
+		predicted: make(map[int]func(CollisionEvent)),
+		cleared:   make(map[int]func(CollisionEvent)),
+		issued:    make(map[int]func(ScheduleAction)),
+		active:    make(map[[2]int]CollisionEvent),
+	}
+}
+
+// OnCollisionPredicted 订阅新预测到的碰撞，返回取消订阅函数
+func (c *Collider) OnCollisionPredicted(fn func(CollisionEvent)) func() {
+	return c.subscribe(func(id int) { c.predicted[id] = fn }, func(id int) { delete(c.predicted, id) })
+}
+
+// OnCollisionCleared 订阅已解除的碰撞，返回取消订阅函数
+func (c *Collider) OnCollisionCleared(fn func(CollisionEvent)) func() {
+	return c.subscribe(func(id int) { c.cleared[id] = fn }, func(id int) { delete(c.cleared, id) })
+}
+
+// OnScheduleIssued 订阅下发的调度动作，返回取消订阅函数
+func (c *Collider) OnScheduleIssued(fn func(ScheduleAction)) func() {
+	return c.subscribe(func(id int) { c.issued[id] = fn }, func(id int) { delete(c.issued, id) })
+}
+
+// subscribe 分配订阅编号并注册
+func (c *Collider) subscribe(add, remove func(id int)) func() {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	add(id)
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			remove(id)
+			c.mu.Unlock()
+		})
+	}
+}
+
+// pairKey 两车编号组成的键（小编号在前）
+func pairKey(e CollisionEvent) [2]int {
+	a, b := e.AGV1.Id, e.AGV2.Id
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// Detect 执行一次检测，边检测边推送事件
+// 参数:
+//
+//	agvs: 所有AGV
+//	tol: 时间差容忍度 (s)
+//	radius: KD树范围查询半径
+//
+// 返回:
+//
+//	[]CollisionEvent: 本次检测到的全部碰撞事件
+func (c *Collider) Detect(agvs []*AGV, tol, radius float64) []CollisionEvent {
+	var events []CollisionEvent
+	current := make(map[[2]int]CollisionEvent)
+
+	detectCollisions(agvs, tol, radius, func(e CollisionEvent) {
+		events = append(events, e)
+		key := pairKey(e)
+		current[key] = e
+
+		c.mu.RLock()
+		_, known := c.active[key]
+		c.mu.RUnlock()
+		if known {
+			return
+		}
+		c.emitPredicted(e)
+		a1, a2 := c.Scheduler.Resolve(e)
+		c.emitIssued(a1)
+		c.emitIssued(a2)
+	})
+
+	c.mu.Lock()
+	var gone []CollisionEvent
+	for key, e := range c.active {
+		if _, ok := current[key]; !ok {
+			gone = append(gone, e)
+		}
+	}
+	c.active = current
+	c.mu.Unlock()
+
+	for _, e := range gone {
+		c.emitCleared(e)
+	}
+	return events
+}
+
+// Run 按固定周期循环检测，直到ctx取消
+// 参数:
+//
+//	interval: 检测周期
+//	agvs: 每个周期获取最新AGV状态的函数
+func (c *Collider) Run(ctx context.Context, interval time.Duration, agvs func() []*AGV, tol, radius float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.Detect(agvs(), tol, radius)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Collider) emitPredicted(e CollisionEvent) {
+	c.mu.RLock()
+	handlers := make([]func(CollisionEvent), 0, len(c.predicted))
+	for _, fn := range c.predicted {
+		handlers = append(handlers, fn)
+	}
+	c.mu.RUnlock()
+	for _, fn := range handlers {
+		fn(e)
+	}
+}
+
+func (c *Collider) emitCleared(e CollisionEvent) {
+	c.mu.RLock()
+	handlers := make([]func(CollisionEvent), 0, len(c.cleared))
+	for _, fn := range c.cleared {
+		handlers = append(handlers, fn)
+	}
+	c.mu.RUnlock()
+	for _, fn := range handlers {
+		fn(e)
+	}
+}
+
+func (c *Collider) emitIssued(a ScheduleAction) {
+	c.mu.RLock()
+	handlers := make([]func(ScheduleAction), 0, len(c.issued))
+	for _, fn := range c.issued {
+		handlers = append(handlers, fn)
+	}
+	c.mu.RUnlock()
+	for _, fn := range handlers {
+		fn(a)
+	}
+}
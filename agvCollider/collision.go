@@ -25,34 +25,47 @@ type CollisionEvent struct {
 // ====================== 碰撞检测逻辑 ======================
 
 // findAllCollisions 找出两条路径的所有交点/重叠点，并计算对应路径长度和时间
+// 先按分块包围盒、再按路径段包围盒剔除不可能冲突的路径段对
 // 参数:
-//   pathA, pathB: 两条路径
-//   vA, vB:       两车速度 (m/s)
-//   width:        AGV宽度
+//   pathA, pathB: 两条路径的包围盒缓存（含路径段，直线或圆弧）
+//   vA, vB:       两车速度曲线
+//   intersects:   路径段冲突检测方式（按车宽或矩形车体）
 // 返回: []Collision，包含所有可能的碰撞事件
-func findAllCollisions(pathA, pathB []Point, vA, vB, width float64) []Collision {
+func findAllCollisions(pathA, pathB *pathBounds, vA, vB profileLegs, intersects segmentChecker) []Collision {
 	var collisions []Collision
-	for i := 0; i < len(pathA)-1; i++ {
-		s1 := Segment{Start: pathA[i], End: pathA[i+1]}
-		for j := 0; j < len(pathB)-1; j++ {
-			s2 := Segment{Start: pathB[j], End: pathB[j+1]}
-			// 判断该两段是否有交点/重合
-			if ok, inter := segmentIntersect(s1, s2, width); ok {
-				// 路径累计长度 = 起点→交点的距离
-				sA := pathDistanceToPoint(pathA, inter)
-				sB := pathDistanceToPoint(pathB, inter)
-				// 到达时间 = 距离 / 速度
-				tA := sA / vA
-				tB := sB / vB
-				// 把这个潜在碰撞事件存入结果集
-				collisions = append(collisions, Collision{
-					Point:     inter,
-					PathADist: sA,
-					PathBDist: sB,
-					TimeA:     tA,
-					TimeB:     tB,
-					TimeDiff:  math.Abs(tA - tB),
-				})
+	if !pathA.overlaps(pathB) {
+		return collisions
+	}
+	pairs := pathA.chunkPairs(pathB)
+	for ci, chunk := range pathA.chunks {
+		for i := chunk.lo; i < chunk.hi; i++ {
+			s1 := pathA.segs[i]
+			for _, cj := range pairs[ci] {
+				// 不同楼层或跨层转运段不在同一分块内，无需再判断
+				for j := pathB.chunks[cj].lo; j < pathB.chunks[cj].hi; j++ {
+					if !boxesOverlap(pathA.boxes[i], pathB.boxes[j]) {
+						continue
+					}
+					s2 := pathB.segs[j]
+					// 判断该两段是否有交点/重合
+					if ok, pA, pB := intersects(s1, s2); ok {
+						// 路径累计长度 = 起点→交点的距离
+						sA := pathDistanceToPoint(pathA.segs, pA)
+						sB := pathDistanceToPoint(pathB.segs, pB)
+						// 到达时间按速度曲线计算
+						tA := vA.timeAt(sA)
+						tB := vB.timeAt(sB)
+						// 把这个潜在碰撞事件存入结果集
+						collisions = append(collisions, Collision{
+							Point:     interpolate(pA, pB, 0.5),
+							PathADist: sA,
+							PathBDist: sB,
+							TimeA:     tA,
+							TimeB:     tB,
+							TimeDiff:  math.Abs(tA - tB),
+						})
+					}
+				}
 			}
 		}
 	}
@@ -65,8 +78,8 @@ func findAllCollisions(pathA, pathB []Point, vA, vB, width float64) []Collision
 // 返回:
 //   bool: 是否存在潜在碰撞
 //   Collision: 最早的碰撞事件
-func earliestCollision(pathA, pathB []Point, vA, vB, width, tol float64) (bool, Collision) {
-	all := findAllCollisions(pathA, pathB, vA, vB, width)
+func earliestCollision(pathA, pathB *pathBounds, vA, vB profileLegs, intersects segmentChecker, tol float64) (bool, Collision) {
+	all := findAllCollisions(pathA, pathB, vA, vB, intersects)
 	if len(all) == 0 {
 		return false, Collision{}
 	}
@@ -88,18 +101,27 @@ func earliestCollision(pathA, pathB []Point, vA, vB, width, tol float64) (bool,
 	return found, best
 }
 
-// DetectCollisionsWithKDTree 使用KD树对所有AGV进行邻居碰撞检测
+// DetectCollisionsWithKDTree 使用空间索引对所有AGV进行邻居碰撞检测（按楼层划分）
+// 空间索引由 DefaultBroadPhase 决定，默认为KD树
 // 参数：
 //   agvs: 所有AGV
 //   tol: 时间差容忍度 (s)
-//   radius: KD树范围查询半径
+//   radius: 范围查询半径
 func DetectCollisionsWithKDTree(agvs []*AGV, tol, radius float64) []CollisionEvent {
-	root := buildKDTree(agvs, 0)
 	results := []CollisionEvent{}
+	detectCollisions(agvs, tol, radius, func(e CollisionEvent) {
+		results = append(results, e)
+	})
+	return results
+}
+
+// detectCollisions 使用空间索引进行邻居碰撞检测，每发现一个碰撞事件即回调emit
+func detectCollisions(agvs []*AGV, tol, radius float64, emit func(CollisionEvent)) {
+	index := buildNeighborIndex(agvs, radius)
 	seen := make(map[int]map[int]bool)
 	for i := range agvs {
 		neighbors := []*AGV{}
-		rangeSearch(root, agvs[i], radius, &neighbors)
+		index.rangeSearch(agvs[i], radius, &neighbors)
 		for _, other := range neighbors {
 			if seen[agvs[i].Id] == nil {
 				seen[agvs[i].Id] = make(map[int]bool)
@@ -111,10 +133,9 @@ func DetectCollisionsWithKDTree(agvs []*AGV, tol, radius float64) []CollisionEve
 				continue
 			}
 			if ok, event := agvs[i].DetectCollisionWith(other, tol); ok {
-				results = append(results, event)
+				emit(event)
 			}
 			seen[agvs[i].Id][other.Id] = true
 		}
 	}
-	return results
 }
@@ -0,0 +1,173 @@
+package agvCollider
+
+import "sort"
+
+// ===================== 等待图 =====================
+
+// WaitForGraph 等待图，边 waiter → holder 表示waiter需等待holder先通过
+type WaitForGraph struct {
+	agvs  map[int]*AGV
+	edges map[int]map[int]bool
+}
+
+// Deadlock 表示一组互相等待的AGV
+// - AGVs:  死锁环中的AGV（按编号排序）
+// - Yield: 建议让行的AGV（在组内等待关系最多，让行后可解除最多的等待）
+type Deadlock struct {
+	AGVs  []*AGV
+	Yield *AGV
+}
+
+// NewWaitForGraph 创建空的等待图
+func NewWaitForGraph() *WaitForGraph {
+	return &WaitForGraph{
+		agvs:  make(map[int]*AGV),
+		edges: make(map[int]map[int]bool),
+	}
+}
+
+// AddWait 添加等待关系：waiter 等待 holder
+func (g *WaitForGraph) AddWait(waiter, holder *AGV) {
+	if waiter == nil || holder == nil || waiter.Id == holder.Id {
+		return
+	}
+	g.agvs[waiter.Id] = waiter
+	g.agvs[holder.Id] = holder
+	if g.edges[waiter.Id] == nil {
+		g.edges[waiter.Id] = make(map[int]bool)
+	}
+	g.edges[waiter.Id][holder.Id] = true
+}
+
+// AddActions 由调度动作添加等待关系，WAIT动作的AGV等待冲突事件中的另一辆AGV
+func (g *WaitForGraph) AddActions(actions []ScheduleAction) {
+	for _, a := range actions {
+		if a.Action != ActionWait || a.AGV == nil {
+			continue
+		}
+		other := a.Collision.AGV1
+		if other == nil || other.Id == a.AGV.Id {
+			other = a.Collision.AGV2
+		}
+		g.AddWait(a.AGV, other)
+	}
+}
+
+// AddConflicts 由预约冲突添加等待关系，后占用的一方等待先占用的一方
+func (g *WaitForGraph) AddConflicts(conflicts []ReservationConflict) {
+	for _, c := range conflicts {
+		g.AddWait(c.B.AGV, c.A.AGV)
+	}
+}
+
+// Deadlocks 检测等待图中的环（Tarjan强连通分量）
+// 返回:
+//
+//	[]Deadlock: 每个包含两辆及以上AGV的强连通分量为一组死锁
+func (g *WaitForGraph) Deadlocks() []Deadlock {
+	ids := make([]int, 0, len(g.agvs))
+	for id := range g.agvs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	index := make(map[int]int)
+	low := make(map[int]int)
+	onStack := make(map[int]bool)
+	var stack []int
+	var groups [][]int
+	next := 0
+
+	var strongConnect func(v int)
+	strongConnect = func(v int) {
+		index[v] = next
+		low[v] = next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range g.successors(v) {
+			if _, seen := index[w]; !seen {
+				strongConnect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] == index[v] {
+			var group []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				group = append(group, w)
+				if w == v {
+					break
+				}
+			}
+			if len(group) > 1 {
+				groups = append(groups, group)
+			}
+		}
+	}
+	for _, id := range ids {
+		if _, seen := index[id]; !seen {
+			strongConnect(id)
+		}
+	}
+
+	out := make([]Deadlock, 0, len(groups))
+	for _, group := range groups {
+		sort.Ints(group)
+		out = append(out, g.deadlock(group))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].AGVs[0].Id < out[j].AGVs[0].Id })
+	return out
+}
+
+// successors 返回v等待的AGV编号（有序）
+func (g *WaitForGraph) successors(v int) []int {
+	out := make([]int, 0, len(g.edges[v]))
+	for w := range g.edges[v] {
+		out = append(out, w)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// deadlock 构造死锁组并给出让行建议
+// 组内入边+出边最多的AGV让行，数量相同时编号大的让行
+func (g *WaitForGraph) deadlock(group []int) Deadlock {
+	in := make(map[int]bool, len(group))
+	for _, id := range group {
+		in[id] = true
+	}
+	degree := make(map[int]int, len(group))
+	for _, v := range group {
+		for w := range g.edges[v] {
+			if in[w] {
+				degree[v]++
+				degree[w]++
+			}
+		}
+	}
+
+	d := Deadlock{AGVs: make([]*AGV, 0, len(group))}
+	yield := group[0]
+	for _, id := range group {
+		d.AGVs = append(d.AGVs, g.agvs[id])
+		if degree[id] >= degree[yield] {
+			yield = id
+		}
+	}
+	d.Yield = g.agvs[yield]
+	return d
+}
+
+// DetectDeadlocks 检测调度动作中的互相等待
+func DetectDeadlocks(actions []ScheduleAction) []Deadlock {
+	g := NewWaitForGraph()
+	g.AddActions(actions)
+	return g.Deadlocks()
+}
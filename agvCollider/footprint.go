@@ -0,0 +1,187 @@
+package agvCollider
+
+import "math"
+
+// ===================== 有向矩形车体(OBB) =====================
+
+// OBB 表示有向矩形车体（Oriented Bounding Box）
+// - Center:     中心点
+// - HalfLength: 沿航向方向的半长
+// - HalfWidth:  垂直航向方向的半宽
+// - T:          航向角，单位弧度
+type OBB struct {
+	Center     Point
+	HalfLength float64
+	HalfWidth  float64
+	T          float64
+}
+
+// axes 返回OBB的两个单位轴（航向方向、垂直方向）
+func (o OBB) axes() (ux, uy, vx, vy float64) {
+	c, s := math.Cos(o.T), math.Sin(o.T)
+	return c, s, -s, c
+}
+
+// Corners 返回OBB的四个顶点（逆时针）
+func (o OBB) Corners() [4]Point {
+	ux, uy, vx, vy := o.axes()
+	lx, ly := ux*o.HalfLength, uy*o.HalfLength
+	wx, wy := vx*o.HalfWidth, vy*o.HalfWidth
+	c := o.Center
+	return [4]Point{
+		{X: c.X + lx - wx, Y: c.Y + ly - wy, Floor: c.Floor},
+		{X: c.X + lx + wx, Y: c.Y + ly + wy, Floor: c.Floor},
+		{X: c.X - lx + wx, Y: c.Y - ly + wy, Floor: c.Floor},
+		{X: c.X - lx - wx, Y: c.Y - ly - wy, Floor: c.Floor},
+	}
+}
+
+// Inflate 四周各外扩margin，用于附加安全距离
+func (o OBB) Inflate(margin float64) OBB {
+	o.HalfLength += margin
+	o.HalfWidth += margin
+	return o
+}
+
+// project 计算OBB在单位轴(ax, ay)上的投影区间
+func (o OBB) project(ax, ay float64) (float64, float64) {
+	ux, uy, vx, vy := o.axes()
+	c := dot(o.Center.X, o.Center.Y, ax, ay)
+	r := o.HalfLength*math.Abs(dot(ux, uy, ax, ay)) + o.HalfWidth*math.Abs(dot(vx, vy, ax, ay))
+	return c - r, c + r
+}
+
+// Intersects 使用分离轴定理判断两个OBB是否重叠（接触也算重叠）
+// 二维矩形只需检查两个矩形各自的两条轴，任一轴上投影不重叠即分离
+func (o OBB) Intersects(p OBB) bool {
+	oux, ouy, ovx, ovy := o.axes()
+	pux, puy, pvx, pvy := p.axes()
+	for _, ax := range [4][2]float64{{oux, ouy}, {ovx, ovy}, {pux, puy}, {pvx, pvy}} {
+		minA, maxA := o.project(ax[0], ax[1])
+		minB, maxB := p.project(ax[0], ax[1])
+		if maxA < minB || maxB < minA {
+			return false
+		}
+	}
+	return true
+}
+
+// ===================== AGV车体 =====================
+
+// hasFootprint 是否设置了车长（按矩形车体检测）
+func (agv *AGV) hasFootprint() bool {
+	return agv.Length > 0
+}
+
+// extent 车体最大尺寸，用于邻居搜索半径
+func (agv *AGV) extent() float64 {
+	return math.Max(math.Max(agv.Width, agv.Length), 2*agv.Polygon.Radius())
+}
+
+// Footprint 返回AGV在指定位姿下的矩形车体
+// 未设置Length时按 Width×Width 的正方形处理
+func (agv *AGV) Footprint(pose Pose) OBB {
+	length := agv.Length
+	if length <= 0 {
+		length = agv.Width
+	}
+	return OBB{
+		Center:     Point{X: pose.X, Y: pose.Y, Floor: pose.Floor},
+		HalfLength: length / 2,
+		HalfWidth:  agv.Width / 2,
+		T:          pose.T,
+	}
+}
+
+// sweptFootprint 返回AGV沿线段行驶扫过的区域
+// 航向取线段方向，前后各延伸半个车长
+func (agv *AGV) sweptFootprint(seg Segment) OBB {
+	o := agv.Footprint(Pose{
+		X:     (seg.Start.X + seg.End.X) / 2,
+		Y:     (seg.Start.Y + seg.End.Y) / 2,
+		T:     math.Atan2(seg.End.Y-seg.Start.Y, seg.End.X-seg.Start.X),
+		Floor: seg.Start.Floor,
+	})
+	o.HalfLength += getDistance(seg.Start, seg.End) / 2
+	return o
+}
+
+// ===================== 线段检测 =====================
+
+// segmentChecker 判断两条路径段是否冲突
+// 返回:
+//
+//	bool:  是否冲突
+//	Point: 冲突点在第一条线段上的位置
+//	Point: 冲突点在第二条线段上的位置
+type segmentChecker func(s1, s2 pathSeg) (bool, Point, Point)
+
+// widthChecker 按车宽检测线段相交/重合（圆形车体）
+// 含圆弧的路径段按曲线是否相交判断
+func widthChecker(width float64) segmentChecker {
+	return func(s1, s2 pathSeg) (bool, Point, Point) {
+		if s1.arc == nil && s2.arc == nil {
+			ok, p := segmentIntersect(s1.Segment, s2.Segment, width)
+			return ok, p, p
+		}
+		p1, p2 := closestPoints(s1, s2)
+		if getDistance(p1, p2) > arcEps {
+			return false, Point{}, Point{}
+		}
+		return true, p1, p2
+	}
+}
+
+// footprintChecker 按两车的矩形车体检测线段冲突
+// 两车沿各自线段扫过的矩形区域重叠即视为冲突，冲突点取两线段上的最近点
+// 圆弧按弦拆分后逐段检测，车体外扩弓高以覆盖弦与圆弧之间的偏差
+func footprintChecker(a, b *AGV) segmentChecker {
+	return func(s1, s2 pathSeg) (bool, Point, Point) {
+		pieces1, sag1 := s1.pieces()
+		pieces2, sag2 := s2.pieces()
+		for _, c1 := range pieces1 {
+			o1 := a.sweptFootprint(c1).Inflate(sag1)
+			for _, c2 := range pieces2 {
+				if o1.Intersects(b.sweptFootprint(c2).Inflate(sag2)) {
+					p1, p2 := closestPoints(s1, s2)
+					return true, p1, p2
+				}
+			}
+		}
+		return false, Point{}, Point{}
+	}
+}
+
+// segmentChecker 根据两车的车体设置选择线段检测方式：多边形 > 矩形 > 圆形
+func (agv *AGV) segmentChecker(other *AGV) segmentChecker {
+	if agv.hasPolygon() || other.hasPolygon() {
+		return polygonChecker(agv, other)
+	}
+	if agv.hasFootprint() || other.hasFootprint() {
+		return footprintChecker(agv, other)
+	}
+	return widthChecker((agv.Width + other.Width) / 2)
+}
+
+// closestSegmentPoints 两条线段上距离最近的一对点，相交时均为交点
+func closestSegmentPoints(s1, s2 Segment) (Point, Point) {
+	if ok, p := segmentIntersect(s1, s2, 0); ok {
+		return p, p
+	}
+
+	pairs := [4][2]Point{
+		{s1.Start, closestPointOnSegment(s1.Start, s2)},
+		{s1.End, closestPointOnSegment(s1.End, s2)},
+		{closestPointOnSegment(s2.Start, s1), s2.Start},
+		{closestPointOnSegment(s2.End, s1), s2.End},
+	}
+	best := pairs[0]
+	minD := getDistance(best[0], best[1])
+	for _, p := range pairs[1:] {
+		if d := getDistance(p[0], p[1]); d < minD {
+			minD = d
+			best = p
+		}
+	}
+	return best[0], best[1]
+}
@@ -0,0 +1,107 @@
+package agvCollider
+
+import "math"
+
+// BroadPhase 粗检测（邻居查询）使用的空间索引类型
+type BroadPhase int
+
+const (
+	BroadPhaseKDTree BroadPhase = iota // KD树
+	BroadPhaseGrid                     // 均匀网格空间哈希
+)
+
+// DefaultBroadPhase 车队检测默认使用的空间索引
+// 车辆密集时网格哈希无需递归建树，通常快于KD树
+var DefaultBroadPhase = BroadPhaseKDTree
+
+// neighborIndex 邻居查询接口，查询结果不含目标自身且只包含同楼层的AGV
+type neighborIndex interface {
+	rangeSearch(target *AGV, r float64, results *[]*AGV)
+}
+
+// buildNeighborIndex 按 DefaultBroadPhase 构建空间索引
+// 参数:
+//
+//	agvs: 所有AGV
+//	radius: 查询半径，网格索引以此作为网格边长
+func buildNeighborIndex(agvs []*AGV, radius float64) neighborIndex {
+	return buildBroadPhase(agvs, radius, DefaultBroadPhase)
+}
+
+// buildBroadPhase 构建指定类型的空间索引
+func buildBroadPhase(agvs []*AGV, radius float64, bp BroadPhase) neighborIndex {
+	if bp == BroadPhaseGrid {
+		return buildSpatialHash(agvs, radius)
+	}
+	return buildFloorIndex(agvs)
+}
+
+// ===================== 网格空间哈希 =====================
+
+// cellKey 网格单元键（楼层 + 网格坐标）
+type cellKey struct {
+	Floor int
+	X, Y  int
+}
+
+// spatialHash 均匀网格空间哈希
+// 每辆AGV按位置落入一个网格，查询时只遍历半径覆盖的网格
+type spatialHash struct {
+	cell  float64
+	cells map[cellKey][]*AGV
+}
+
+// buildSpatialHash 构建网格空间哈希
+// 网格边长取查询半径，半径查询只需遍历周围3×3个网格
+func buildSpatialHash(agvs []*AGV, cell float64) *spatialHash {
+	if cell <= 0 || math.IsInf(cell, 0) || math.IsNaN(cell) {
+		cell = 1
+	}
+	h := &spatialHash{
+		cell:  cell,
+		cells: make(map[cellKey][]*AGV, len(agvs)),
+	}
+	for _, agv := range agvs {
+		key := h.key(agv.Floor, agv.Pose.X, agv.Pose.Y)
+		h.cells[key] = append(h.cells[key], agv)
+	}
+	return h
+}
+
+// key 计算坐标所在的网格
+func (h *spatialHash) key(floor int, x, y float64) cellKey {
+	return cellKey{
+		Floor: floor,
+		X:     int(math.Floor(x / h.cell)),
+		Y:     int(math.Floor(y / h.cell)),
+	}
+}
+
+// rangeSearch 查询目标AGV所在楼层半径r内的邻居
+func (h *spatialHash) rangeSearch(target *AGV, r float64, results *[]*AGV) {
+	lo := h.key(target.Floor, target.Pose.X-r, target.Pose.Y-r)
+	hi := h.key(target.Floor, target.Pose.X+r, target.Pose.Y+r)
+	// 查询范围远大于网格时退化为遍历所有网格
+	if float64(hi.X-lo.X+1)*float64(hi.Y-lo.Y+1) > float64(len(h.cells)) {
+		for key, agvs := range h.cells {
+			if key.Floor == target.Floor {
+				h.collect(agvs, target, r, results)
+			}
+		}
+		return
+	}
+	for x := lo.X; x <= hi.X; x++ {
+		for y := lo.Y; y <= hi.Y; y++ {
+			h.collect(h.cells[cellKey{Floor: target.Floor, X: x, Y: y}], target, r, results)
+		}
+	}
+}
+
+// collect 将网格中距离不超过r的AGV加入结果
+func (h *spatialHash) collect(agvs []*AGV, target *AGV, r float64, results *[]*AGV) {
+	for _, agv := range agvs {
+		if agv.Id != target.Id && distance(agv, target) <= r {
+			*results = append(*results, agv)
+		}
+	}
+}
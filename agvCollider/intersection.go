@@ -0,0 +1,320 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// ===================== 路口定义 =====================
+
+// Intersection 表示地图上需要互斥通行的路口
+// - Name:    路口名称（唯一）
+// - Floor:   所在楼层
+// - Polygon: 路口轮廓（世界坐标）
+type Intersection struct {
+	Name    string
+	Floor   int
+	Polygon Polygon
+}
+
+// IntersectionOccupancy 表示AGV占用路口的时间段
+type IntersectionOccupancy struct {
+	Intersection *Intersection // 路口
+	AGV          *AGV          // 占用的AGV
+	Point        Point         // 进入路口的位置
+	Enter        float64       // 车头进入路口的时间
+	Exit         float64       // 车尾离开路口的时间
+}
+
+// pathSpan 计算路径经过多边形区域的里程区间
+// 取首次进入和最后离开的位置，多次经过同一区域时按整体区间处理
+// 返回:
+//
+//	Point:   进入位置
+//	float64: 进入里程
+//	float64: 离开里程
+//	bool:    路径是否经过该区域
+func pathSpan(segs []pathSeg, floor int, poly Polygon, margin float64) (Point, float64, float64, bool) {
+	var entry Point
+	enter, exit := -1.0, -1.0
+	s0 := 0.0
+	for _, seg := range segs {
+		length := seg.length()
+		if f, ok := seg.floor(); !ok || f != floor {
+			s0 += length
+			continue
+		}
+		pieces, _ := seg.pieces()
+		n := float64(len(pieces))
+		for k, piece := range pieces {
+			if enter < 0 {
+				if p, r, ok := poly.segmentEntry(piece, margin); ok {
+					entry = p
+					enter = s0 + length*(float64(k)+r)/n
+				}
+			}
+			// 反向检测即为离开位置
+			if _, r, ok := poly.segmentEntry(Segment{Start: piece.End, End: piece.Start}, margin); ok {
+				exit = s0 + length*(float64(k+1)-r)/n
+			}
+		}
+		s0 += length
+	}
+	if enter < 0 {
+		return Point{}, 0, 0, false
+	}
+	return entry, enter, math.Max(enter, exit), true
+}
+
+// Occupancy 计算AGV沿路径占用路口的时间段
+// 车头距路口半个车身时开始占用，车尾离开路口后结束占用
+// 返回:
+//
+//	IntersectionOccupancy: 占用信息
+//	bool: 路径是否经过该路口
+func (in *Intersection) Occupancy(agv *AGV) (IntersectionOccupancy, bool) {
+	segs := agv.pathSegs(agv.Path)
+	total := pathLength(segs)
+	p, enter, exit, ok := pathSpan(segs, in.Floor, in.Polygon, agv.Width/2)
+	if !ok {
+		return IntersectionOccupancy{}, false
+	}
+	half := agv.extent() / 2
+	profile := agv.Profile(total).legs()
+	return IntersectionOccupancy{
+		Intersection: in,
+		AGV:          agv,
+		Point:        p,
+		Enter:        profile.timeAt(math.Max(enter-half, 0)),
+		Exit:         profile.timeAt(math.Min(exit+half, total)),
+	}, true
+}
+
+// ===================== 路口锁管理 =====================
+
+// IntersectionLockManager 路口锁管理器
+// 每个路口同一时间只允许一辆AGV持有锁，其余AGV按申请顺序排队
+type IntersectionLockManager struct {
+	mu            sync.Mutex
+	intersections map[string]*Intersection
+	holders       map[string]int   // 路口 → 持有锁的AGV编号
+	queues        map[string][]int // 路口 → 排队的AGV编号
+}
+
+// NewIntersectionLockManager 创建路口锁管理器
+func NewIntersectionLockManager(intersections ...Intersection) *IntersectionLockManager {
+	m := &IntersectionLockManager{
+		intersections: make(map[string]*Intersection),
+		holders:       make(map[string]int),
+		queues:        make(map[string][]int),
+	}
+	for _, in := range intersections {
+		m.AddIntersection(in)
+	}
+	return m
+}
+
+// AddIntersection 添加或替换路口定义
+func (m *IntersectionLockManager) AddIntersection(in Intersection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.intersections[in.Name] = &in
+}
+
+// Acquire 申请路口锁
+// 路口空闲或已由该AGV持有时返回true；否则加入等待队列并返回false
+func (m *IntersectionLockManager) Acquire(name string, agvID int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.acquire(name, agvID)
+}
+
+func (m *IntersectionLockManager) acquire(name string, agvID int) bool {
+	if holder, ok := m.holders[name]; ok {
+		if holder == agvID {
+			return true
+		}
+		for _, id := range m.queues[name] {
+			if id == agvID {
+				return false
+			}
+		}
+		m.queues[name] = append(m.queues[name], agvID)
+		return false
+	}
+	m.holders[name] = agvID
+	m.removeQueued(name, agvID)
+	return true
+}
+
+// Release 释放路口锁，锁转交给队首的AGV
+// 返回:
+//
+//	int:  新的持锁AGV编号
+//	bool: 是否有AGV接手
+func (m *IntersectionLockManager) Release(name string, agvID int) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if holder, ok := m.holders[name]; !ok || holder != agvID {
+		// 未持锁时仅退出队列
+		m.removeQueued(name, agvID)
+		return 0, false
+	}
+	delete(m.holders, name)
+	queue := m.queues[name]
+	if len(queue) == 0 {
+		return 0, false
+	}
+	next := queue[0]
+	m.queues[name] = queue[1:]
+	m.holders[name] = next
+	return next, true
+}
+
+// removeQueued 将AGV移出等待队列
+func (m *IntersectionLockManager) removeQueued(name string, agvID int) {
+	queue := m.queues[name]
+	for i, id := range queue {
+		if id == agvID {
+			m.queues[name] = append(queue[:i:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// Holder 返回持有路口锁的AGV编号
+func (m *IntersectionLockManager) Holder(name string) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.holders[name]
+	return id, ok
+}
+
+// Queue 返回路口的等待队列
+func (m *IntersectionLockManager) Queue(name string) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int(nil), m.queues[name]...)
+}
+
+// Schedule 计算各AGV经过路口的先后顺序并下发调度动作
+// 当前持锁的AGV优先，其余按进入时间排序；后车需等前车离开路口并间隔safeGap后进入
+// 每次调度按排序结果重建等待队列：路口空闲时首车持锁，其余依次排队；
+// 本次车队中路径已不经过路口的AGV移出队列，不在本次车队中的排队AGV保留在队尾
+// 持锁的AGV不在本次车队中时，经过路口的AGV均下发 Hold 的WAIT，直到锁转交后再通行
+// 参数:
+//
+//	agvs:    AGV车队
+//	safeGap: 安全时间间隔 (s)
+//
+// 返回:
+//
+//	[]ScheduleAction: 每辆经过路口的AGV对应一个GO或WAIT动作
+func (m *IntersectionLockManager) Schedule(agvs []*AGV, safeGap float64) []ScheduleAction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.intersections))
+	for name := range m.intersections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	inFleet := make(map[int]bool, len(agvs))
+	for _, agv := range agvs {
+		inFleet[agv.Id] = true
+	}
+
+	var actions []ScheduleAction
+	for _, name := range names {
+		in := m.intersections[name]
+		var occ []IntersectionOccupancy
+		for _, agv := range agvs {
+			if agv.Floor != in.Floor {
+				continue
+			}
+			if o, ok := in.Occupancy(agv); ok {
+				occ = append(occ, o)
+			}
+		}
+
+		holder, held := m.holders[name]
+		sort.SliceStable(occ, func(i, j int) bool {
+			hi := held && occ[i].AGV.Id == holder
+			hj := held && occ[j].AGV.Id == holder
+			if hi != hj {
+				return hi
+			}
+			return occ[i].Enter < occ[j].Enter
+		})
+		if !held && len(occ) > 0 {
+			holder, held = occ[0].AGV.Id, true
+			m.holders[name] = holder
+		}
+		m.rebuildQueue(name, holder, occ, inFleet)
+
+		// 持锁的AGV不在本次车队中时，路口仍被占用
+		free := len(occ) > 0 && occ[0].AGV.Id == holder
+		releaseAt := 0.0
+		for i, o := range occ {
+			prev := o
+			if i > 0 {
+				prev = occ[i-1]
+			}
+			event := CollisionEvent{
+				AGV1:   prev.AGV,
+				AGV2:   o.AGV,
+				Point:  o.Point,
+				Time1:  prev.Enter,
+				Time2:  o.Enter,
+				DeltaT: math.Abs(o.Enter - prev.Enter),
+			}
+
+			if !free {
+				actions = append(actions, ScheduleAction{AGV: o.AGV, Action: ActionWait, Hold: true, Collision: event})
+				continue
+			}
+			if i == 0 {
+				actions = append(actions, ScheduleAction{AGV: o.AGV, Action: ActionGo, WaitTime: 0, Collision: event})
+				releaseAt = o.Exit
+				continue
+			}
+			wait := releaseAt + safeGap - o.Enter
+			start := o.Enter + math.Max(wait, 0)
+			releaseAt = start + (o.Exit - o.Enter)
+			if wait <= 0 {
+				actions = append(actions, ScheduleAction{AGV: o.AGV, Action: ActionGo, WaitTime: 0, Collision: event})
+				continue
+			}
+			actions = append(actions, ScheduleAction{AGV: o.AGV, Action: ActionWait, WaitTime: wait, Collision: event})
+		}
+	}
+	return actions
+}
+
+// rebuildQueue 按本次调度的通行顺序重建路口等待队列
+// 参数:
+//
+//	holder:  当前持锁AGV编号
+//	occ:     已排序的路口占用
+//	inFleet: 本次车队中的AGV编号
+func (m *IntersectionLockManager) rebuildQueue(name string, holder int, occ []IntersectionOccupancy, inFleet map[int]bool) {
+	var queue []int
+	for _, o := range occ {
+		if o.AGV.Id != holder {
+			queue = append(queue, o.AGV.Id)
+		}
+	}
+	for _, id := range m.queues[name] {
+		if !inFleet[id] && id != holder {
+			queue = append(queue, id)
+		}
+	}
+	if len(queue) == 0 {
+		delete(m.queues, name)
+		return
+	}
+	m.queues[name] = queue
+}
@@ -81,3 +81,24 @@ func rangeSearch(node *KDNode, target *AGV, r float64, results *[]*AGV) {
 		}
 	}
 }
+
+// floorIndex 按楼层划分的KD树，不同楼层的AGV互不作为邻居
+type floorIndex map[int]*KDNode
+
+// buildFloorIndex 按AGV所在楼层分别构建KD树
+func buildFloorIndex(agvs []*AGV) floorIndex {
+	groups := make(map[int][]*AGV)
+	for _, agv := range agvs {
+		groups[agv.Floor] = append(groups[agv.Floor], agv)
+	}
+	index := make(floorIndex, len(groups))
+	for floor, group := range groups {
+		index[floor] = buildKDTree(group, 0)
+	}
+	return index
+}
+
+// rangeSearch 在目标AGV所在楼层的KD树中查询半径r的邻居
+func (fi floorIndex) rangeSearch(target *AGV, r float64, results *[]*AGV) {
+	rangeSearch(fi[target.Floor], target, r, results)
+}
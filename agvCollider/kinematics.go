@@ -0,0 +1,254 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+)
+
+// ===================== 速度曲线 =====================
+
+// StopPoint 表示路径上的停靠点
+// - S:     停靠点的路径里程（m），从路径起点计算
+// - Dwell: 停留时长（s）
+type StopPoint struct {
+	S     float64
+	Dwell float64
+}
+
+// VelocityProfile 表示AGV沿路径行驶的梯形速度曲线
+// 每一段（起点→停靠点→…→终点）均按 加速→匀速→减速 行驶，路径太短时退化为三角形曲线
+// - Cruise:       巡航速度（m/s）
+// - Accel:        最大加速度（m/s²），为0时视为瞬时达到巡航速度
+// - Decel:        最大减速度（m/s²），为0时视为瞬时停车
+// - InitialSpeed: 起点处的初速度（m/s），停靠点之后均从静止起步
+// - Stops:        途中停靠点
+// - Length:       路径总长（m），终点处停车；为0时视为无终点
+type VelocityProfile struct {
+	Cruise       float64
+	Accel        float64
+	Decel        float64
+	InitialSpeed float64
+	Stops        []StopPoint
+	Length       float64
+}
+
+// profileLeg 速度曲线中两次停车之间的一段
+type profileLeg struct {
+	s0, t0 float64      // 该段起点的里程与时间
+	dwell  float64      // 该段终点的停留时长
+	p      speedProfile // 该段速度曲线
+}
+
+// duration 该段行驶时长（不含停留）
+func (l profileLeg) duration() float64 {
+	return l.p.ta + l.p.tc + l.p.td
+}
+
+// legs 将速度曲线按停靠点拆分为若干段
+func (vp VelocityProfile) legs() profileLegs {
+	length := vp.Length
+	if length <= 0 {
+		length = math.Inf(1)
+	}
+
+	stops := make([]StopPoint, 0, len(vp.Stops))
+	for _, st := range vp.Stops {
+		if st.S > 0 && st.S < length {
+			stops = append(stops, st)
+		}
+	}
+	sort.Slice(stops, func(i, j int) bool { return stops[i].S < stops[j].S })
+	stops = append(stops, StopPoint{S: length})
+
+	legs := make(profileLegs, 0, len(stops))
+	s0, t0, v0 := 0.0, 0.0, vp.InitialSpeed
+	for _, st := range stops {
+		decel := vp.Decel
+		if math.IsInf(st.S, 1) {
+			decel = 0
+		}
+		leg := profileLeg{
+			s0:    s0,
+			t0:    t0,
+			dwell: st.Dwell,
+			p:     newSpeedProfile(st.S-s0, v0, vp.Cruise, vp.Accel, decel),
+		}
+		legs = append(legs, leg)
+		s0, t0, v0 = st.S, t0+leg.duration()+st.Dwell, 0
+	}
+	return legs
+}
+
+// DistanceAt 计算t秒时沿路径行驶的里程
+// 同一速度曲线需要多次采样时，先调用 legs 拆分后使用 profileLegs.distanceAt
+func (vp VelocityProfile) DistanceAt(t float64) float64 {
+	if vp.Cruise <= 0 {
+		return 0 // 无法行驶，停在原地
+	}
+	return vp.legs().distanceAt(t)
+}
+
+// TimeAt 计算到达路径里程s所需的时间，无法到达时返回 +Inf
+func (vp VelocityProfile) TimeAt(s float64) float64 {
+	return vp.legs().timeAt(s)
+}
+
+// profileLegs 拆分好的速度曲线，可重复采样
+type profileLegs []profileLeg
+
+// distanceAt 计算t秒时沿路径行驶的里程
+// 某段无法行驶（巡航速度为0）时停在该段起点
+func (ls profileLegs) distanceAt(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	s := 0.0
+	for _, leg := range ls {
+		if leg.p.vp <= 0 && leg.p.length > 0 {
+			return leg.s0
+		}
+		if t < leg.t0+leg.duration() {
+			return leg.s0 + leg.p.distance(t-leg.t0)
+		}
+		s = leg.s0 + leg.p.length
+	}
+	return s
+}
+
+// timeAt 计算到达路径里程s所需的时间，无法到达时返回 +Inf
+func (ls profileLegs) timeAt(s float64) float64 {
+	if s <= 0 {
+		return 0
+	}
+	for _, leg := range ls {
+		if s <= leg.s0+leg.p.length {
+			return leg.t0 + leg.p.time(s-leg.s0)
+		}
+	}
+	return math.Inf(1)
+}
+
+// Profile 返回AGV沿长度为length的路径行驶的速度曲线
+func (agv *AGV) Profile(length float64) VelocityProfile {
+	return VelocityProfile{
+		Cruise:       agv.Speed,
+		Accel:        agv.MaxAccel,
+		Decel:        agv.MaxDecel,
+		InitialSpeed: agv.Velocity,
+		Stops:        agv.Stops,
+		Length:       length,
+	}
+}
+
+// ===================== 单段加减速运动学 =====================
+
+// speedProfile 单段梯形（或三角形）速度曲线
+// 从初速度加速到巡航速度，匀速行驶后在段终点前减速停车
+type speedProfile struct {
+	v0     float64 // 初速度
+	vp     float64 // 峰值速度
+	accel  float64 // 加速度，0表示瞬时达到
+	decel  float64 // 减速度，0表示瞬时停车
+	ta     float64 // 加速段时长
+	tc     float64 // 匀速段时长
+	td     float64 // 减速段时长
+	sa     float64 // 加速段距离
+	sc     float64 // 匀速段距离
+	length float64 // 总距离
+}
+
+// newSpeedProfile 构造行驶length距离的速度曲线
+// 参数:
+//
+//	length: 段长度，可为 +Inf
+//	v0:     初速度
+//	vmax:   巡航速度
+//	accel:  最大加速度，<=0表示瞬时加速
+//	decel:  最大减速度，<=0表示瞬时停车
+//
+// 返回:
+//
+//	speedProfile: 速度曲线
+func newSpeedProfile(length, v0, vmax, accel, decel float64) speedProfile {
+	p := speedProfile{length: length}
+	if vmax <= 0 || length <= 0 {
+		return p
+	}
+	if accel <= 0 {
+		// 无加速度限制 → 直接以巡航速度行驶
+		v0 = vmax
+	}
+	v0 = math.Max(0, math.Min(v0, vmax))
+
+	// 加减速度的倒数，0表示无限制
+	var ia, id float64
+	if accel > 0 {
+		ia = 1 / accel
+	}
+	if decel > 0 {
+		id = 1 / decel
+	}
+
+	vp := vmax
+	if (vmax*vmax-v0*v0)*ia/2+vmax*vmax*id/2 > length {
+		// 段太短无法达到巡航速度 → 三角形曲线
+		vp = math.Sqrt((2*length + v0*v0*ia) / (ia + id))
+		if vp < v0 {
+			// 以最大减速度也无法在终点前停下 → 按刚好停在终点的减速度制动
+			vp = v0
+			id = 2 * length / (v0 * v0)
+		}
+	}
+
+	p.v0, p.vp = v0, vp
+	if ia > 0 {
+		p.accel = 1 / ia
+		p.ta = (vp - v0) * ia
+	}
+	if id > 0 {
+		p.decel = 1 / id
+		p.td = vp * id
+	}
+	p.sa = (v0 + vp) / 2 * p.ta
+	p.sc = math.Max(0, length-p.sa-vp*p.td/2)
+	p.tc = p.sc / vp
+	return p
+}
+
+// distance 计算t秒内行驶的距离，不超过段长度
+func (p speedProfile) distance(t float64) float64 {
+	if t <= 0 || p.vp <= 0 {
+		return 0
+	}
+	switch {
+	case t < p.ta:
+		return p.v0*t + p.accel*t*t/2
+	case t < p.ta+p.tc:
+		return p.sa + p.vp*(t-p.ta)
+	case t < p.ta+p.tc+p.td:
+		tau := t - p.ta - p.tc
+		return math.Min(p.length, p.sa+p.sc+p.vp*tau-p.decel*tau*tau/2)
+	}
+	return p.length
+}
+
+// time 计算行驶距离s所需的时间，distance 的反函数
+func (p speedProfile) time(s float64) float64 {
+	if s <= 0 {
+		return 0
+	}
+	if p.vp <= 0 || s > p.length {
+		return math.Inf(1)
+	}
+	switch {
+	case s < p.sa:
+		// v0*t + a*t²/2 = s
+		return (math.Sqrt(p.v0*p.v0+2*p.accel*s) - p.v0) / p.accel
+	case s <= p.sa+p.sc:
+		return p.ta + (s-p.sa)/p.vp
+	}
+	// vp*τ - d*τ²/2 = r
+	r := s - p.sa - p.sc
+	tau := (p.vp - math.Sqrt(math.Max(0, p.vp*p.vp-2*p.decel*r))) / p.decel
+	return p.ta + p.tc + math.Min(tau, p.td)
+}
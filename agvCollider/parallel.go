@@ -0,0 +1,109 @@
+package agvCollider
+
+import (
+	"runtime"
+	"sync"
+)
+
+// pairBatchSize 每个任务包含的AGV对数，减少通道调度开销
+const pairBatchSize = 64
+
+// agvPair 待检测的AGV对（车队中的下标）
+type agvPair struct {
+	i, j int
+}
+
+// candidatePairs 使用空间索引找出可能碰撞的AGV对
+// 每对只出现一次，按车队下标排序，保证结果顺序稳定
+func candidatePairs(agvs []*AGV, radius float64) []agvPair {
+	pos := make(map[*AGV]int, len(agvs))
+	for i, agv := range agvs {
+		pos[agv] = i
+	}
+	index := buildNeighborIndex(append([]*AGV(nil), agvs...), radius)
+
+	var pairs []agvPair
+	seen := make(map[[2]int]bool)
+	for i, agv := range agvs {
+		neighbors := []*AGV{}
+		index.rangeSearch(agv, radius, &neighbors)
+		for _, other := range neighbors {
+			j := pos[other]
+			if j <= i {
+				continue
+			}
+			key := [2]int{agv.Id, other.Id}
+			if key[0] > key[1] {
+				key[0], key[1] = key[1], key[0]
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			pairs = append(pairs, agvPair{i: i, j: j})
+		}
+	}
+	return pairs
+}
+
+// PredictCollisionsForFleetParallel 使用工作池并发检测AGV车队中所有可能的碰撞
+// 空间索引筛选出候选AGV对后分批分发给各工作协程，结果按候选对顺序汇总
+// 每辆AGV的轨迹只计算一次，预测时不修改AGV状态；调用前需已生成SubPath
+// 参数:
+//
+//	agvs: AGV车队
+//	timeRange: 预测时间范围（秒）
+//	timeStep: 时间步长（秒）
+//	collisionThreshold: 碰撞距离阈值（米）
+//	workers: 工作协程数，<=0时使用CPU核数
+//
+// 返回:
+//
+//	[]CollisionPrediction: 所有预测的碰撞事件，AGV1/AGV2指向车队中的AGV
+func PredictCollisionsForFleetParallel(agvs []*AGV, timeRange, timeStep, collisionThreshold float64, workers int) []CollisionPrediction {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	// 每辆AGV的轨迹只计算一次，工作协程只读共享
+	trajs := make([]*Trajectory, len(agvs))
+	for i, agv := range agvs {
+		trajs[i] = agv.Trajectory(0)
+	}
+
+	pairs := candidatePairs(agvs, calculateOptimalSearchRadius(agvs, timeRange, collisionThreshold))
+	found := make([]bool, len(pairs))
+	results := make([]CollisionPrediction, len(pairs))
+
+	batches := make(chan [2]int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(pairs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				for k := b[0]; k < b[1]; k++ {
+					i, j := pairs[k].i, pairs[k].j
+					ok, c := agvs[i].predictCollision(agvs[j], trajs[i], trajs[j], timeRange, timeStep, collisionThreshold)
+					if !ok {
+						continue
+					}
+					// 每个下标只由一个协程写入，无需加锁
+					found[k], results[k] = true, c
+				}
+			}
+		}()
+	}
+	for lo := 0; lo < len(pairs); lo += pairBatchSize {
+		batches <- [2]int{lo, min(lo+pairBatchSize, len(pairs))}
+	}
+	close(batches)
+	wg.Wait()
+
+	var collisions []CollisionPrediction
+	for k, ok := range found {
+		if ok {
+			collisions = append(collisions, results[k])
+		}
+	}
+	return collisions
+}
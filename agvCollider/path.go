@@ -48,28 +48,33 @@ func checkPathIntersection(pathA, pathB []Point, width float64) (bool, Point) {
 
 // pathDistanceToPoint 计算路径起点到指定点的累计路径长度
 // 参数:
-//   path: 路径点集
+//   segs: 路径段（直线或圆弧）
 //   p   : 目标点（相交点）
 // 返回:
 //   float64: 从路径起点到目标点的距离
-func pathDistanceToPoint(path []Point, p Point) float64 {
+func pathDistanceToPoint(segs []pathSeg, p Point) float64 {
 	total := 0.0
-	for i := 0; i < len(path)-1; i++ {
-		seg := Segment{Start: path[i], End: path[i+1]}
-		// 判断p是否在该段上(在直线上且投影在段内)
-		// 方法: 向量叉积==0 且 点在范围内
-		if cross(p.X-seg.Start.X, p.Y-seg.Start.Y,
-			seg.End.X-seg.Start.X, seg.End.Y-seg.Start.Y) == 0 &&
-			p.X >= math.Min(seg.Start.X, seg.End.X)-1e-6 &&
-			p.X <= math.Max(seg.Start.X, seg.End.X)+1e-6 &&
-			p.Y >= math.Min(seg.Start.Y, seg.End.Y)-1e-6 &&
-			p.Y <= math.Max(seg.Start.Y, seg.End.Y)+1e-6 {
+	for _, seg := range segs {
+		// 判断p是否在该段上（到投影点的距离在容差内）
+		if q, ratio := seg.project(p); getDistance(p, q) <= arcEps {
 			// 点在该段
-			total += getDistance(seg.Start, p)
-			return total
+			return total + seg.length()*ratio
 		}
 		// 否则整段加入累计
-		total += getDistance(seg.Start, seg.End)
+		total += seg.length()
+	}
+	return total
+}
+
+// pathLength 计算路径总长度
+// 参数:
+//   segs: 路径段（直线或圆弧）
+// 返回:
+//   float64: 各段长度之和
+func pathLength(segs []pathSeg) float64 {
+	total := 0.0
+	for _, seg := range segs {
+		total += seg.length()
 	}
 	return total
 }
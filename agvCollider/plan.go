@@ -0,0 +1,159 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ===================== 调度计划 =====================
+
+// PlannedAction 调度计划中的单个动作
+// - ReleaseAt: 绝对放行时间（GO为计划时刻，WAIT为等待结束时刻，SLOW_DOWN为开始减速时刻）
+// - FromSeg:   受影响的路径段起始下标（含），无法定位时为-1
+// - ToSeg:     受影响的路径段结束下标（含），无法定位时为-1
+// - Event:     来源事件在 SchedulePlan.Events 中的下标
+type PlannedAction struct {
+	ScheduleAction
+	ReleaseAt time.Time
+	FromSeg   int
+	ToSeg     int
+	Event     int
+}
+
+// AGVPlan 单辆AGV的调度动作，按放行时间排序
+type AGVPlan struct {
+	AGV     *AGV
+	Actions []PlannedAction
+}
+
+// SchedulePlan 调度计划
+// - CreatedAt: 计划时刻
+// - AGVs:      每辆AGV的动作，按AGV编号排序
+// - Events:    产生计划的碰撞事件
+type SchedulePlan struct {
+	CreatedAt time.Time
+	AGVs      []AGVPlan
+	Events    []CollisionEvent
+}
+
+// NewSchedulePlan 由碰撞事件及对应的调度动作生成调度计划
+// 参数:
+//
+//	now:     计划时刻
+//	events:  碰撞事件
+//	actions: 调度动作，Collision字段需为events中的事件
+func NewSchedulePlan(now time.Time, events []CollisionEvent, actions []ScheduleAction) SchedulePlan {
+	plan := SchedulePlan{CreatedAt: now, Events: events}
+
+	byAGV := make(map[int]*AGVPlan)
+	var ids []int
+	for _, a := range actions {
+		if a.AGV == nil {
+			continue
+		}
+		p, ok := byAGV[a.AGV.Id]
+		if !ok {
+			p = &AGVPlan{AGV: a.AGV}
+			byAGV[a.AGV.Id] = p
+			ids = append(ids, a.AGV.Id)
+		}
+
+		other := a.Collision.AGV1
+		if other == nil || other.Id == a.AGV.Id {
+			other = a.Collision.AGV2
+		}
+		from, to := affectedRange(a.AGV, other, a.Collision.Point)
+		p.Actions = append(p.Actions, PlannedAction{
+			ScheduleAction: a,
+			ReleaseAt:      releaseAt(now, a),
+			FromSeg:        from,
+			ToSeg:          to,
+			Event:          eventIndex(events, a.Collision),
+		})
+	}
+
+	sort.Ints(ids)
+	for _, id := range ids {
+		p := byAGV[id]
+		sort.SliceStable(p.Actions, func(i, j int) bool {
+			ai, aj := p.Actions[i], p.Actions[j]
+			if ai.Hold != aj.Hold {
+				return aj.Hold
+			}
+			return ai.ReleaseAt.Before(aj.ReleaseAt)
+		})
+		plan.AGVs = append(plan.AGVs, *p)
+	}
+	return plan
+}
+
+// For 返回指定AGV的调度动作
+func (p SchedulePlan) For(agvID int) (AGVPlan, bool) {
+	i := sort.Search(len(p.AGVs), func(i int) bool { return p.AGVs[i].AGV.Id >= agvID })
+	if i < len(p.AGVs) && p.AGVs[i].AGV.Id == agvID {
+		return p.AGVs[i], true
+	}
+	return AGVPlan{}, false
+}
+
+// Actions 将计划展开为调度动作列表（按AGV编号、放行时间排序）
+func (p SchedulePlan) Actions() []ScheduleAction {
+	var out []ScheduleAction
+	for _, ap := range p.AGVs {
+		for _, a := range ap.Actions {
+			out = append(out, a.ScheduleAction)
+		}
+	}
+	return out
+}
+
+// affectedRange 计算AGV路径中受冲突影响的路径段下标范围
+// 与冲突点距离不超过两车半车身之和的路径段均视为受影响
+func affectedRange(agv, other *AGV, p Point) (int, int) {
+	reach := agv.extent() / 2
+	if other != nil {
+		reach = (agv.extent() + other.extent()) / 2
+	}
+	from, to := -1, -1
+	for i, seg := range agv.pathSegs(agv.Path) {
+		q, _ := seg.project(p)
+		if getDistance(p, q) > reach {
+			continue
+		}
+		if from < 0 {
+			from = i
+		}
+		to = i
+	}
+	return from, to
+}
+
+// eventIndex 查找事件下标，未找到时返回-1
+func eventIndex(events []CollisionEvent, e CollisionEvent) int {
+	for i, ev := range events {
+		if ev == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// seconds 将秒数转换为 time.Duration，负数按0处理，超出范围按最大值处理
+// releaseAt 计算动作的放行时刻，无限期等待（Hold）的动作放行时刻未知，为零值
+func releaseAt(now time.Time, a ScheduleAction) time.Time {
+	if a.Hold {
+		return time.Time{}
+	}
+	return now.Add(seconds(a.WaitTime))
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	if s >= float64(math.MaxInt64)/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(s * float64(time.Second))
+}
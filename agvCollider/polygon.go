@@ -0,0 +1,167 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+)
+
+// ===================== 凸多边形车体 =====================
+
+// Polygon 表示凸多边形，顶点按顺序排列（顺时针或逆时针均可）
+// 作为AGV车体时顶点使用车体坐标系：原点为AGV位姿点，x轴指向航向，y轴指向左侧
+type Polygon []Point
+
+// Transform 将车体坐标系下的多边形变换到位姿所在的世界坐标系
+func (p Polygon) Transform(pose Pose) Polygon {
+	c, s := math.Cos(pose.T), math.Sin(pose.T)
+	out := make(Polygon, len(p))
+	for i, v := range p {
+		out[i] = Point{
+			X:     pose.X + v.X*c - v.Y*s,
+			Y:     pose.Y + v.X*s + v.Y*c,
+			Floor: pose.Floor,
+		}
+	}
+	return out
+}
+
+// Intersects 使用分离轴定理判断两个凸多边形是否重叠（接触也算重叠）
+func (p Polygon) Intersects(q Polygon) bool {
+	return polygonsWithin(p, q, 0)
+}
+
+// Radius 顶点到原点的最大距离，用于估算车体尺寸
+func (p Polygon) Radius() float64 {
+	r := 0.0
+	for _, v := range p {
+		r = math.Max(r, math.Hypot(v.X, v.Y))
+	}
+	return r
+}
+
+// Polygon 返回OBB的四个顶点组成的多边形
+func (o OBB) Polygon() Polygon {
+	c := o.Corners()
+	return Polygon(c[:])
+}
+
+// polygonsWithin 判断两个凸多边形的间距是否不超过gap
+// 在双方所有边的法向上投影，任一轴上两投影区间的间隔大于gap即分离；
+// gap>0时相当于两车体各外扩gap/2后判断重叠（轴上精确，拐角处略保守）
+func polygonsWithin(p, q Polygon, gap float64) bool {
+	if len(p) == 0 || len(q) == 0 {
+		return false
+	}
+	for _, poly := range []Polygon{p, q} {
+		n := len(poly)
+		for i := 0; i < n; i++ {
+			a, b := poly[i], poly[(i+1)%n]
+			// 边的法向量
+			ax, ay := -(b.Y - a.Y), b.X-a.X
+			l := math.Hypot(ax, ay)
+			if l == 0 {
+				continue
+			}
+			ax, ay = ax/l, ay/l
+
+			minA, maxA := projectPolygon(p, ax, ay)
+			minB, maxB := projectPolygon(q, ax, ay)
+			if minB-maxA > gap || minA-maxB > gap {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// projectPolygon 计算多边形在单位轴(ax, ay)上的投影区间
+func projectPolygon(p Polygon, ax, ay float64) (float64, float64) {
+	lo, hi := math.MaxFloat64, -math.MaxFloat64
+	for _, v := range p {
+		d := dot(v.X, v.Y, ax, ay)
+		lo = math.Min(lo, d)
+		hi = math.Max(hi, d)
+	}
+	return lo, hi
+}
+
+// convexHull 计算点集的凸包（Andrew单调链），结果按逆时针排列
+func convexHull(points []Point) Polygon {
+	pts := make([]Point, len(points))
+	copy(pts, points)
+	sort.Slice(pts, func(i, j int) bool {
+		if pts[i].X != pts[j].X {
+			return pts[i].X < pts[j].X
+		}
+		return pts[i].Y < pts[j].Y
+	})
+	if len(pts) < 3 {
+		return pts
+	}
+
+	turn := func(o, a, b Point) float64 {
+		return cross(a.X-o.X, a.Y-o.Y, b.X-o.X, b.Y-o.Y)
+	}
+
+	hull := make(Polygon, 0, 2*len(pts))
+	// 下凸包
+	for _, p := range pts {
+		for len(hull) >= 2 && turn(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	// 上凸包
+	lower := len(hull) + 1
+	for i := len(pts) - 2; i >= 0; i-- {
+		p := pts[i]
+		for len(hull) >= lower && turn(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
+
+// ===================== AGV多边形车体 =====================
+
+// hasPolygon 是否设置了多边形车体
+func (agv *AGV) hasPolygon() bool {
+	return len(agv.Polygon) >= 3
+}
+
+// Shape 返回AGV在指定位姿下的车体多边形
+// 设置了Polygon时取其凸包（非凸轮廓按凸包保守处理），否则使用矩形车体 Footprint
+func (agv *AGV) Shape(pose Pose) Polygon {
+	if agv.hasPolygon() {
+		return convexHull(agv.Polygon).Transform(pose)
+	}
+	return agv.Footprint(pose).Polygon()
+}
+
+// sweptShape 返回AGV沿线段行驶扫过的区域（航向取线段方向，为起终点车体的凸包）
+func (agv *AGV) sweptShape(seg Segment) Polygon {
+	t := math.Atan2(seg.End.Y-seg.Start.Y, seg.End.X-seg.Start.X)
+	start := agv.Shape(Pose{X: seg.Start.X, Y: seg.Start.Y, T: t, Floor: seg.Start.Floor})
+	end := agv.Shape(Pose{X: seg.End.X, Y: seg.End.Y, T: t, Floor: seg.End.Floor})
+	return convexHull(append(start, end...))
+}
+
+// polygonChecker 按两车的多边形车体检测线段冲突
+// 圆弧按弦拆分后逐段检测，间距容差取两段弓高之和
+func polygonChecker(a, b *AGV) segmentChecker {
+	return func(s1, s2 pathSeg) (bool, Point, Point) {
+		pieces1, sag1 := s1.pieces()
+		pieces2, sag2 := s2.pieces()
+		for _, c1 := range pieces1 {
+			shape1 := a.sweptShape(c1)
+			for _, c2 := range pieces2 {
+				if polygonsWithin(shape1, b.sweptShape(c2), sag1+sag2) {
+					p1, p2 := closestPoints(s1, s2)
+					return true, p1, p2
+				}
+			}
+		}
+		return false, Point{}, Point{}
+	}
+}
@@ -0,0 +1,79 @@
+package agvCollider
+
+// ===================== 让行优先级策略 =====================
+
+// PriorityInput 冲突双方参与优先级判断的信息
+type PriorityInput struct {
+	AGV          *AGV    // AGV
+	Arrival      float64 // 到达冲突点的时间 (s)
+	TaskPriority int     // 任务优先级
+	Loaded       bool    // 是否载货
+	Battery      float64 // 剩余电量比例
+	Waited       float64 // 已累计等待时长 (s)
+}
+
+// newPriorityInput 由AGV和到达时间构造优先级输入
+func newPriorityInput(agv *AGV, arrival float64) PriorityInput {
+	return PriorityInput{
+		AGV:          agv,
+		Arrival:      arrival,
+		TaskPriority: agv.Priority,
+		Loaded:       agv.Loaded,
+		Battery:      agv.Battery,
+		Waited:       agv.Waited,
+	}
+}
+
+// PriorityPolicy 冲突让行策略
+type PriorityPolicy interface {
+	// Prefer a是否优先于b通过
+	Prefer(a, b PriorityInput) bool
+}
+
+// PriorityPolicyFunc 函数形式的让行策略
+type PriorityPolicyFunc func(a, b PriorityInput) bool
+
+// Prefer 实现 PriorityPolicy
+func (f PriorityPolicyFunc) Prefer(a, b PriorityInput) bool {
+	return f(a, b)
+}
+
+// ArrivalPolicy 先到先行：先到达冲突点的AGV优先
+type ArrivalPolicy struct{}
+
+// Prefer 实现 PriorityPolicy
+func (ArrivalPolicy) Prefer(a, b PriorityInput) bool {
+	return a.Arrival <= b.Arrival
+}
+
+// DefaultPriorityPolicy 默认让行策略（先到先行）
+var DefaultPriorityPolicy PriorityPolicy = ArrivalPolicy{}
+
+// WeightedPolicy 按加权得分决定让行，得分相同时先到先行
+// 得分 = TaskWeight*任务优先级 + LoadedWeight*是否载货 + LowBatteryWeight*(1-电量) + WaitWeight*已等待时长
+type WeightedPolicy struct {
+	TaskWeight       float64
+	LoadedWeight     float64
+	LowBatteryWeight float64
+	WaitWeight       float64
+}
+
+// score 计算优先级得分
+func (w WeightedPolicy) score(in PriorityInput) float64 {
+	s := w.TaskWeight*float64(in.TaskPriority) +
+		w.LowBatteryWeight*(1-in.Battery) +
+		w.WaitWeight*in.Waited
+	if in.Loaded {
+		s += w.LoadedWeight
+	}
+	return s
+}
+
+// Prefer 实现 PriorityPolicy
+func (w WeightedPolicy) Prefer(a, b PriorityInput) bool {
+	sa, sb := w.score(a), w.score(b)
+	if sa != sb {
+		return sa > sb
+	}
+	return a.Arrival <= b.Arrival
+}
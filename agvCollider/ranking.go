@@ -0,0 +1,132 @@
+package agvCollider
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// CollisionOrder 碰撞结果排序方式
+type CollisionOrder int
+
+const (
+	OrderByTime CollisionOrder = iota // 按碰撞时间升序
+	OrderByRisk                       // 按风险从高到低
+)
+
+// riskRank 风险等级序号，越小风险越高（与 GetCollisionRiskLevel 的划分一致）
+func riskRank(c *CollisionPrediction) int {
+	switch {
+	case c.CollisionTime <= 1.0:
+		return 0
+	case c.CollisionTime <= 3.0:
+		return 1
+	case c.CollisionTime <= 5.0:
+		return 2
+	}
+	return 3
+}
+
+// penetration 碰撞时刻两车中心距离与阈值之比，越小表示重叠越深
+func penetration(c *CollisionPrediction) float64 {
+	if c.CollisionThreshold <= 0 {
+		return 0
+	}
+	return c.Distance / c.CollisionThreshold
+}
+
+// collisionLess 判断a是否应排在b之前
+// 按风险排序时：先比较风险等级，同等级时重叠越深越靠前，再按碰撞时间
+// 最后按AGV编号保证结果稳定
+func collisionLess(a, b *CollisionPrediction, order CollisionOrder) bool {
+	if order == OrderByRisk {
+		if ra, rb := riskRank(a), riskRank(b); ra != rb {
+			return ra < rb
+		}
+		if pa, pb := penetration(a), penetration(b); pa != pb {
+			return pa < pb
+		}
+	}
+	if a.CollisionTime != b.CollisionTime {
+		return a.CollisionTime < b.CollisionTime
+	}
+	if a.AGV1.Id != b.AGV1.Id {
+		return a.AGV1.Id < b.AGV1.Id
+	}
+	return a.AGV2.Id < b.AGV2.Id
+}
+
+// SortCollisions 按指定方式对碰撞预测结果原地排序
+func SortCollisions(collisions []CollisionPrediction, order CollisionOrder) {
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisionLess(&collisions[i], &collisions[j], order)
+	})
+}
+
+// collisionHeap 保留前K个结果的大顶堆，堆顶为当前排名最靠后的结果
+type collisionHeap struct {
+	items []CollisionPrediction
+	order CollisionOrder
+}
+
+func (h *collisionHeap) Len() int { return len(h.items) }
+func (h *collisionHeap) Less(i, j int) bool {
+	return collisionLess(&h.items[j], &h.items[i], h.order)
+}
+func (h *collisionHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *collisionHeap) Push(x any)    { h.items = append(h.items, x.(CollisionPrediction)) }
+func (h *collisionHeap) Pop() any {
+	n := len(h.items)
+	x := h.items[n-1]
+	h.items = h.items[:n-1]
+	return x
+}
+
+// TopCollisions 返回排序后的前k个碰撞预测结果，不修改输入
+// 使用大小为k的堆，复杂度 O(n log k)
+// 参数:
+//
+//	collisions: 碰撞预测结果
+//	order: 排序方式
+//	k: 返回数量，<=0时返回全部
+//
+// 返回:
+//
+//	[]CollisionPrediction: 排序后的结果
+func TopCollisions(collisions []CollisionPrediction, order CollisionOrder, k int) []CollisionPrediction {
+	if k <= 0 || k >= len(collisions) {
+		out := append([]CollisionPrediction(nil), collisions...)
+		SortCollisions(out, order)
+		return out
+	}
+	h := &collisionHeap{items: make([]CollisionPrediction, 0, k), order: order}
+	for i := range collisions {
+		if h.Len() < k {
+			heap.Push(h, collisions[i])
+			continue
+		}
+		if collisionLess(&collisions[i], &h.items[0], order) {
+			h.items[0] = collisions[i]
+			heap.Fix(h, 0)
+		}
+	}
+	SortCollisions(h.items, order)
+	return h.items
+}
+
+// PredictNextCollisions 预测车队碰撞并返回排序后的前k个结果
+// 参数:
+//
+//	agvs: AGV车队
+//	timeRange: 预测时间范围（秒）
+//	timeStep: 时间步长（秒）
+//	collisionThreshold: 碰撞距离阈值（米）
+//	order: 排序方式
+//	k: 返回数量，<=0时返回全部
+//
+// 返回:
+//
+//	[]CollisionPrediction: 排序后的碰撞事件
+func PredictNextCollisions(agvs []*AGV, timeRange, timeStep, collisionThreshold float64, order CollisionOrder, k int) []CollisionPrediction {
+	collisions := PredictCollisionsForFleetOptimized(agvs, timeRange, timeStep, collisionThreshold, true)
+	return TopCollisions(collisions, order, k)
+}
@@ -0,0 +1,45 @@
+package agvCollider
+
+// ===================== 重规划 =====================
+
+// Replanner 外部路径规划器
+type Replanner interface {
+	// PlanPath 规划从from到to、避开blocked路径段的新路径，无法规划时返回nil
+	PlanPath(from Pose, to Point, blocked []Segment) []Point
+}
+
+// ReplannerFunc 函数形式的路径规划器
+type ReplannerFunc func(from Pose, to Point, blocked []Segment) []Point
+
+// PlanPath 实现 Replanner
+func (f ReplannerFunc) PlanPath(from Pose, to Point, blocked []Segment) []Point {
+	return f(from, to, blocked)
+}
+
+// blockedSegments 返回优先车路径中靠近冲突点的路径段，作为让行车重规划时需要避开的区域
+func blockedSegments(winner, yielder *AGV, p Point) []Segment {
+	reach := (winner.extent() + yielder.extent()) / 2
+	var out []Segment
+	for _, seg := range winner.pathSegs(winner.Path) {
+		pieces, sag := seg.pieces()
+		for _, piece := range pieces {
+			if getDistance(closestPointOnSegment(p, piece), p) <= reach+sag {
+				out = append(out, piece)
+			}
+		}
+	}
+	return out
+}
+
+// reroute 为让行车重规划路径
+// 返回:
+//
+//	[]Point: 新路径
+//	bool:    是否规划成功
+func reroute(planner Replanner, winner, yielder *AGV, p Point) ([]Point, bool) {
+	if planner == nil || len(yielder.Path) == 0 {
+		return nil, false
+	}
+	route := planner.PlanPath(yielder.Pose, yielder.Path[len(yielder.Path)-1], blockedSegments(winner, yielder, p))
+	return route, len(route) >= 2
+}
@@ -0,0 +1,283 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// ===================== 时间窗预约 =====================
+
+// Reservation 表示AGV对一段路径的时间窗占用
+// - AGV:     预约的AGV
+// - Index:   路径段下标
+// - Segment: 路径段起终点（圆弧段为弦）
+// - Start:   开始占用时间（车头进入路径段）
+// - End:     结束占用时间（车尾离开路径段）
+// 路径终点额外生成一个停车预约（Index为路径段数，Segment起终点均为终点），结束时间为 +Inf
+type Reservation struct {
+	AGV     *AGV
+	Index   int
+	Segment Segment
+	Start   float64
+	End     float64
+
+	seg  pathSeg
+	bbox [4]float64 // minX, minY, maxX, maxY（含车体尺寸）
+}
+
+// ReservationConflict 表示两个预约的时空冲突
+type ReservationConflict struct {
+	A     Reservation // 先开始占用的预约
+	B     Reservation // 后开始占用的预约
+	Point Point       // 冲突点
+}
+
+// Event 转换为碰撞事件，以两车开始占用冲突路径段的时间作为到达时间
+func (c ReservationConflict) Event() CollisionEvent {
+	return CollisionEvent{
+		AGV1:   c.A.AGV,
+		AGV2:   c.B.AGV,
+		Point:  c.Point,
+		Time1:  c.A.Start,
+		Time2:  c.B.Start,
+		DeltaT: math.Abs(c.A.Start - c.B.Start),
+	}
+}
+
+// Reservations 按速度曲线计算AGV沿路径各段的占用时间窗
+// 车头进入路径段前半个车身即开始占用，车尾离开后结束占用
+// 参数:
+//
+//	t0: 起始时刻，返回的时间窗均加上该偏移
+//
+// 返回:
+//
+//	[]Reservation: 各路径段的时间窗
+func (agv *AGV) Reservations(t0 float64) []Reservation {
+	segs := agv.pathSegs(agv.Path)
+	total := pathLength(segs)
+	profile := agv.Profile(total).legs()
+	half := agv.extent() / 2
+
+	out := make([]Reservation, 0, len(segs))
+	s0 := 0.0
+	for i, seg := range segs {
+		s1 := s0 + seg.length()
+		out = append(out, Reservation{
+			AGV:     agv,
+			Index:   i,
+			Segment: seg.Segment,
+			Start:   t0 + profile.timeAt(math.Max(s0-half, 0)),
+			End:     t0 + profile.timeAt(math.Min(s1+half, total)),
+			seg:     seg,
+			bbox:    segBBox(seg, half),
+		})
+		s0 = s1
+	}
+
+	// 终点停车占用
+	if len(segs) > 0 {
+		end := segs[len(segs)-1].End
+		park := pathSeg{Segment: Segment{Start: end, End: end}}
+		out = append(out, Reservation{
+			AGV:     agv,
+			Index:   len(segs),
+			Segment: park.Segment,
+			Start:   t0 + profile.timeAt(math.Max(total-half, 0)),
+			End:     math.Inf(1),
+			seg:     park,
+			bbox:    segBBox(park, half),
+		})
+	}
+	return out
+}
+
+// segBBox 计算路径段外扩margin后的包围盒
+func segBBox(seg pathSeg, margin float64) [4]float64 {
+	pieces, sag := seg.pieces()
+	b := [4]float64{math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64}
+	for _, p := range pieces {
+		for _, pt := range []Point{p.Start, p.End} {
+			b[0], b[1] = math.Min(b[0], pt.X), math.Min(b[1], pt.Y)
+			b[2], b[3] = math.Max(b[2], pt.X), math.Max(b[3], pt.Y)
+		}
+	}
+	m := margin + sag
+	return [4]float64{b[0] - m, b[1] - m, b[2] + m, b[3] + m}
+}
+
+// conflictWith 判断两个预约是否冲突
+// 时间窗（含安全间隔gap）重叠、同一楼层且路径段按车体检测冲突
+func (r Reservation) conflictWith(o Reservation, gap float64) (bool, Point) {
+	if r.AGV.Id == o.AGV.Id {
+		return false, Point{}
+	}
+	if r.Start >= o.End+gap || o.Start >= r.End+gap {
+		return false, Point{}
+	}
+	f1, ok1 := r.seg.floor()
+	f2, ok2 := o.seg.floor()
+	if !ok1 || !ok2 || f1 != f2 {
+		return false, Point{}
+	}
+	if r.bbox[0] > o.bbox[2] || o.bbox[0] > r.bbox[2] || r.bbox[1] > o.bbox[3] || o.bbox[1] > r.bbox[3] {
+		return false, Point{}
+	}
+	// 停车预约按车体尺寸判断距离
+	if r.seg.Start == r.seg.End || o.seg.Start == o.seg.End {
+		p1, p2 := closestPoints(r.seg, o.seg)
+		if getDistance(p1, p2) > (r.AGV.extent()+o.AGV.extent())/2 {
+			return false, Point{}
+		}
+		return true, interpolate(p1, p2, 0.5)
+	}
+	ok, p1, p2 := r.AGV.segmentChecker(o.AGV)(r.seg, o.seg)
+	if !ok {
+		return false, Point{}
+	}
+	return true, interpolate(p1, p2, 0.5)
+}
+
+// sweepConflicts 按开始时间扫描预约，找出所有冲突
+// 排序O(n log n)，扫描时只与时间窗仍可能重叠的活动预约比较
+func sweepConflicts(items []Reservation, gap float64) []ReservationConflict {
+	sorted := make([]Reservation, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var out []ReservationConflict
+	var active []Reservation
+	for _, r := range sorted {
+		// 剔除已结束的预约
+		kept := active[:0]
+		for _, a := range active {
+			if a.End+gap > r.Start {
+				kept = append(kept, a)
+			}
+		}
+		active = kept
+
+		for _, a := range active {
+			if ok, p := a.conflictWith(r, gap); ok {
+				out = append(out, ReservationConflict{A: a, B: r, Point: p})
+			}
+		}
+		active = append(active, r)
+	}
+	return out
+}
+
+// FindReservationConflicts 计算车队所有AGV的时间窗并检测冲突
+// 参数:
+//
+//	agvs:    AGV车队
+//	safeGap: 安全时间间隔 (s)
+//
+// 返回:
+//
+//	[]ReservationConflict: 所有冲突，按先占用方开始时间排序
+func FindReservationConflicts(agvs []*AGV, safeGap float64) []ReservationConflict {
+	var items []Reservation
+	for _, agv := range agvs {
+		items = append(items, agv.Reservations(0)...)
+	}
+	return sweepConflicts(items, safeGap)
+}
+
+// ===================== 预约表 =====================
+
+// ReservationTable 路径时间窗预约表，用于准入控制
+// 新的路径预约与已有预约冲突时拒绝，调度方可调整出发时间后重试
+type ReservationTable struct {
+	mu      sync.Mutex
+	safeGap float64
+	items   map[int][]Reservation
+}
+
+// NewReservationTable 创建预约表
+// 参数:
+//
+//	safeGap: 安全时间间隔 (s)
+func NewReservationTable(safeGap float64) *ReservationTable {
+	return &ReservationTable{
+		safeGap: safeGap,
+		items:   make(map[int][]Reservation),
+	}
+}
+
+// Reserve 为AGV从t0时刻出发的路径申请预约
+// 该AGV原有的预约会被替换；与其他AGV的预约冲突时不写入
+// 返回:
+//
+//	[]ReservationConflict: 冲突列表
+//	bool: 是否预约成功
+func (rt *ReservationTable) Reserve(agv *AGV, t0 float64) ([]ReservationConflict, bool) {
+	windows := agv.Reservations(t0)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	var conflicts []ReservationConflict
+	for id, items := range rt.items {
+		if id == agv.Id {
+			continue
+		}
+		for _, other := range items {
+			for _, w := range windows {
+				if ok, p := other.conflictWith(w, rt.safeGap); ok {
+					conflicts = append(conflicts, ReservationConflict{A: other, B: w, Point: p})
+				}
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].B.Start < conflicts[j].B.Start })
+		return conflicts, false
+	}
+	rt.items[agv.Id] = windows
+	return nil, true
+}
+
+// Release 释放AGV的全部预约
+func (rt *ReservationTable) Release(agvID int) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	delete(rt.items, agvID)
+}
+
+// ReleaseBefore 清理结束时间早于t的预约
+func (rt *ReservationTable) ReleaseBefore(t float64) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	for id, items := range rt.items {
+		kept := items[:0]
+		for _, r := range items {
+			if r.End >= t {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == 0 {
+			delete(rt.items, id)
+			continue
+		}
+		rt.items[id] = kept
+	}
+}
+
+// Reservations 返回预约表中的全部预约
+func (rt *ReservationTable) Reservations() []Reservation {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	var out []Reservation
+	for _, items := range rt.items {
+		out = append(out, items...)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out
+}
+
+// Conflicts 检测预约表中已有预约之间的冲突
+func (rt *ReservationTable) Conflicts() []ReservationConflict {
+	return sweepConflicts(rt.Reservations(), rt.safeGap)
+}
@@ -1,36 +1,67 @@
 package agvCollider
 
+import "time"
+
+// ActionType 调度动作类型
+type ActionType string
+
+// 调度动作类型
+const (
+	ActionGo            ActionType = "GO"             // 按原计划通行
+	ActionWait          ActionType = "WAIT"           // 停车等待 WaitTime 后通行（Hold时等待放行）
+	ActionSlowDown      ActionType = "SLOW_DOWN"      // 以 TargetSpeed 行驶 Duration 后恢复巡航速度
+	ActionReroute       ActionType = "REROUTE"        // 改走 Route
+	ActionEmergencyStop ActionType = "EMERGENCY_STOP" // 立即停车，等待人工或上层处理
+)
+
 // ScheduleAction 调度动作
 type ScheduleAction struct {
-	AGV       *AGV
-	Action    string         // "GO" 或 "WAIT"
-	WaitTime  float64        // 等待时间 (s)
-	Collision CollisionEvent // 对应的冲突事件
+	AGV         *AGV
+	Action      ActionType     // 动作类型
+	WaitTime    float64        // 等待时间 (s)，WAIT时有效
+	Hold        bool           // 无限期等待直到收到放行（如路口锁转交），WAIT时有效，此时WaitTime为0
+	TargetSpeed float64        // 减速后的目标速度 (m/s)，SLOW_DOWN时有效
+	Duration    float64        // 减速持续时间 (s)，SLOW_DOWN时有效，之后恢复巡航速度
+	Route       []Point        // 重规划后的路径，REROUTE时有效
+	Collision   CollisionEvent // 对应的冲突事件
 }
 
-// ResolveCollision 自动调度决策
+// ResolveCollision 自动调度决策，先到达冲突点的AGV优先通过
 // 参数：
 //   e: 碰撞事件
 //   safeGap: 安全时间间隔 (秒)，要求后一辆车至少等待这么久
 // 返回：两个调度动作（一个GO，一个WAIT）
 func ResolveCollision(e CollisionEvent, safeGap float64) (ScheduleAction, ScheduleAction) {
+	return ResolveCollisionWith(e, safeGap, DefaultPriorityPolicy)
+}
+
+// ResolveCollisionWith 按让行策略进行调度决策
+// 参数：
+//   e: 碰撞事件
+//   safeGap: 安全时间间隔 (秒)，让行车需在优先车通过后间隔这么久再到达
+//   policy: 让行策略，为nil时使用 DefaultPriorityPolicy
+// 返回：两个调度动作（一个GO，一个WAIT）
+func ResolveCollisionWith(e CollisionEvent, safeGap float64, policy PriorityPolicy) (ScheduleAction, ScheduleAction) {
+	if policy == nil {
+		policy = DefaultPriorityPolicy
+	}
 
 	// 决定谁优先通过
-	if e.Time1 <= e.Time2 {
-		// AGV1先到 → GO，AGV2等待
+	if policy.Prefer(newPriorityInput(e.AGV1, e.Time1), newPriorityInput(e.AGV2, e.Time2)) {
+		// AGV1优先 → GO，AGV2等待
 		return ScheduleAction{
-				AGV: e.AGV1, Action: "GO", WaitTime: 0, Collision: e,
+				AGV: e.AGV1, Action: ActionGo, WaitTime: 0, Collision: e,
 			}, ScheduleAction{
-				AGV: e.AGV2, Action: "WAIT",
+				AGV: e.AGV2, Action: ActionWait,
 				WaitTime:  (e.Time1 + safeGap) - e.Time2,
 				Collision: e,
 			}
 	} else {
-		// AGV2先到 → GO，AGV1等待
+		// AGV2优先 → GO，AGV1等待
 		return ScheduleAction{
-				AGV: e.AGV2, Action: "GO", WaitTime: 0, Collision: e,
+				AGV: e.AGV2, Action: ActionGo, WaitTime: 0, Collision: e,
 			}, ScheduleAction{
-				AGV: e.AGV1, Action: "WAIT",
+				AGV: e.AGV1, Action: ActionWait,
 				WaitTime:  (e.Time2 + safeGap) - e.Time1,
 				Collision: e,
 			}
@@ -38,12 +69,139 @@ func ResolveCollision(e CollisionEvent, safeGap float64) (ScheduleAction, Schedu
 }
 
 // DetectAndSchedule 使用KD树检测潜在碰撞并下发调度建议
+//
+// Deprecated: 使用 Scheduler.Schedule 获取结构化的 SchedulePlan
 func DetectAndSchedule(agvs []*AGV, tol, radius, safeGap float64) []ScheduleAction {
+	return DetectAndScheduleWith(agvs, tol, radius, safeGap, DefaultPriorityPolicy)
+}
+
+// DetectAndScheduleWith 使用KD树检测潜在碰撞并按让行策略下发调度建议
+//
+// Deprecated: 使用 Scheduler.Schedule 获取结构化的 SchedulePlan
+func DetectAndScheduleWith(agvs []*AGV, tol, radius, safeGap float64, policy PriorityPolicy) []ScheduleAction {
+	events := DetectCollisionsWithKDTree(agvs, tol, radius)
+	actions := []ScheduleAction{}
+	for _, e := range events {
+		a1, a2 := ResolveCollisionWith(e, safeGap, policy)
+		actions = append(actions, a1, a2)
+	}
+	return actions
+}
+
+// Scheduler 冲突调度器
+// - SafeGap:       安全时间间隔 (s)
+// - Policy:        让行策略，为nil时使用 DefaultPriorityPolicy
+// - MinSpeedRatio: 大于0时让行车优先减速，目标速度不低于巡航速度的该比例，否则停车等待
+// - MaxWait:       等待时间超过该值且设置了Replanner时，为让行车重规划路径 (s)
+// - Replanner:     外部路径规划器
+// - EmergencyTime: 让行车距冲突点的到达时间不超过该值时来不及常规避让，下发EMERGENCY_STOP (s)
+type Scheduler struct {
+	SafeGap       float64
+	Policy        PriorityPolicy
+	MinSpeedRatio float64
+	MaxWait       float64
+	Replanner     Replanner
+	EmergencyTime float64
+}
+
+// Resolve 对单个碰撞事件进行调度决策
+// 返回：两个调度动作（优先车GO，让行车GO/WAIT/SLOW_DOWN/REROUTE/EMERGENCY_STOP）
+func (s *Scheduler) Resolve(e CollisionEvent) (ScheduleAction, ScheduleAction) {
+	var goAct, yield ScheduleAction
+	if s.MinSpeedRatio > 0 {
+		goAct, yield = ResolveCollisionSlowdown(e, s.SafeGap, s.MinSpeedRatio, s.Policy)
+	} else {
+		goAct, yield = ResolveCollisionWith(e, s.SafeGap, s.Policy)
+	}
+
+	arrival := e.Time1
+	if yield.AGV == e.AGV2 {
+		arrival = e.Time2
+	}
+	if yield.Action != ActionGo && s.EmergencyTime > 0 && arrival <= s.EmergencyTime {
+		yield.Action = ActionEmergencyStop
+		yield.WaitTime, yield.TargetSpeed, yield.Duration = 0, 0, 0
+		return goAct, yield
+	}
+
+	if yield.Action == ActionWait && s.MaxWait > 0 && yield.WaitTime > s.MaxWait {
+		if route, ok := reroute(s.Replanner, goAct.AGV, yield.AGV, e.Point); ok {
+			yield.Action = ActionReroute
+			yield.Route = route
+			yield.WaitTime = 0
+		}
+	}
+	return goAct, yield
+}
+
+// Schedule 使用KD树检测潜在碰撞并生成调度计划
+// 参数:
+//   now: 调度时刻，计划中的放行时间以此为基准
+func (s *Scheduler) Schedule(agvs []*AGV, tol, radius float64, now time.Time) SchedulePlan {
 	events := DetectCollisionsWithKDTree(agvs, tol, radius)
+	return NewSchedulePlan(now, events, s.resolveAll(events))
+}
+
+// resolveAll 依次对碰撞事件进行调度决策
+func (s *Scheduler) resolveAll(events []CollisionEvent) []ScheduleAction {
 	actions := []ScheduleAction{}
 	for _, e := range events {
-		a1, a2 := ResolveCollision(e, safeGap)
+		a1, a2 := s.Resolve(e)
 		actions = append(actions, a1, a2)
 	}
 	return actions
 }
+
+// ResolveCollisionSlowdown 按让行策略进行调度决策，让行车优先减速而非停车
+// 让行车以恒定低速行驶至冲突点，使到达时间推迟到优先车到达后safeGap：
+// 目标速度 = 巡航速度 × 原到达时间 / 要求到达时间，这是满足间隔的最小减速幅度；
+// 目标速度低于巡航速度的minSpeedRatio倍时仍下发WAIT
+// 参数：
+//   e: 碰撞事件
+//   safeGap: 安全时间间隔 (秒)
+//   minSpeedRatio: 允许的最低速度比例 (0~1)
+//   policy: 让行策略，为nil时使用 DefaultPriorityPolicy
+// 返回：两个调度动作（一个GO，一个WAIT/SLOW_DOWN；时间间隔已满足时均为GO）
+func ResolveCollisionSlowdown(e CollisionEvent, safeGap, minSpeedRatio float64, policy PriorityPolicy) (ScheduleAction, ScheduleAction) {
+	goAct, yield := ResolveCollisionWith(e, safeGap, policy)
+	if yield.WaitTime <= 0 {
+		yield.Action = ActionGo
+		yield.WaitTime = 0
+		return goAct, yield
+	}
+
+	arrival := e.Time1
+	if yield.AGV == e.AGV2 {
+		arrival = e.Time2
+	}
+	required := arrival + yield.WaitTime
+	if arrival <= 0 || yield.AGV.Speed <= 0 {
+		return goAct, yield
+	}
+	ratio := arrival / required
+	if ratio < minSpeedRatio {
+		return goAct, yield
+	}
+
+	yield.Action = ActionSlowDown
+	yield.TargetSpeed = yield.AGV.Speed * ratio
+	yield.Duration = required
+	yield.WaitTime = 0
+	return goAct, yield
+}
+
+// DetectAndScheduleSlowdown 使用KD树检测潜在碰撞并下发调度建议，让行车优先减速
+//
+// Deprecated: 使用 Scheduler.Schedule 获取结构化的 SchedulePlan
+func DetectAndScheduleSlowdown(agvs []*AGV, tol, radius, safeGap, minSpeedRatio float64, policy PriorityPolicy) []ScheduleAction {
+	s := &Scheduler{SafeGap: safeGap, Policy: policy, MinSpeedRatio: minSpeedRatio}
+	return s.resolveAll(DetectCollisionsWithKDTree(agvs, tol, radius))
+}
+
+// DetectAndScheduleReroute 使用KD树检测潜在碰撞并下发调度建议，等待超过maxWait时调用planner重规划
+//
+// Deprecated: 使用 Scheduler.Schedule 获取结构化的 SchedulePlan
+func DetectAndScheduleReroute(agvs []*AGV, tol, radius, safeGap, maxWait float64, planner Replanner) []ScheduleAction {
+	s := &Scheduler{SafeGap: safeGap, MaxWait: maxWait, Replanner: planner}
+	return s.resolveAll(DetectCollisionsWithKDTree(agvs, tol, radius))
+}
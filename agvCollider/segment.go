@@ -32,7 +32,7 @@ func segmentIntersect(s1, s2 Segment, width float64) (bool, Point) {
 					overlapEnd := math.Min(maxA, maxB)
 					ix := (overlapStart + overlapEnd) / 2
 					iy := s1.Start.Y
-					return true, Point{ix, iy}
+					return true, Point{X: ix, Y: iy, Floor: s1.Start.Floor}
 				}
 			} else { // 纵向线段
 				minA, maxA := math.Min(s1.Start.Y, s1.End.Y), math.Max(s1.Start.Y, s1.End.Y)
@@ -42,7 +42,7 @@ func segmentIntersect(s1, s2 Segment, width float64) (bool, Point) {
 					overlapEnd := math.Min(maxA, maxB)
 					iy := (overlapStart + overlapEnd) / 2
 					ix := s1.Start.X
-					return true, Point{ix, iy}
+					return true, Point{X: ix, Y: iy, Floor: s1.Start.Floor}
 				}
 			}
 		}
@@ -57,7 +57,7 @@ func segmentIntersect(s1, s2 Segment, width float64) (bool, Point) {
 	if t >= 0 && t <= 1 && u >= 0 && u <= 1 {
 		ix := s1.Start.X + t*dx1
 		iy := s1.Start.Y + t*dy1
-		return true, Point{ix, iy}
+		return true, Point{X: ix, Y: iy, Floor: s1.Start.Floor}
 	}
 
 	return false, Point{}
@@ -81,7 +81,7 @@ func closestPointOnSegment(p Point, seg Segment) Point {
 	if t > 1 {
 		t = 1
 	}
-	return Point{seg.Start.X + abx*t, seg.Start.Y + aby*t}
+	return Point{X: seg.Start.X + abx*t, Y: seg.Start.Y + aby*t, Floor: seg.Start.Floor}
 }
 
 // segmentDistance 两Segment最近距离及接触点
@@ -0,0 +1,121 @@
+package agvCollider
+
+import "math"
+
+// DefaultSmoothStep 平滑路径默认重采样间距（m）
+const DefaultSmoothStep = 0.1
+
+// bezierSamples 每段拐角贝塞尔曲线的采样点数
+const bezierSamples = 32
+
+// SmoothParams 路径平滑参数
+// - MinRadius: 最小转弯半径（m），拐角处按该半径约束曲率；为0时不平滑，仅重采样
+// - Step:      重采样间距（m），为0时使用 DefaultSmoothStep
+type SmoothParams struct {
+	MinRadius float64
+	Step      float64
+}
+
+// SmoothPath 平滑路径并按固定间距重采样
+// 每个拐角用二次贝塞尔曲线过渡（控制点为拐角点），切入距离按最小转弯半径计算；
+// 切入距离不超过相邻线段长度的一半，线段过短时曲率约束尽量满足
+// 参数:
+//
+//	points: 原始路径点（如栅格规划输出）
+//	params: 平滑参数
+//
+// 返回:
+//
+//	[]Point: 平滑并重采样后的路径点，首尾点与原路径一致
+func SmoothPath(points []Point, params SmoothParams) []Point {
+	pts := dedupPoints(points)
+	if len(pts) < 2 {
+		return pts
+	}
+	step := params.Step
+	if step <= 0 {
+		step = DefaultSmoothStep
+	}
+
+	dense := []Point{pts[0]}
+	for i := 1; i < len(pts)-1; i++ {
+		prev, corner, next := pts[i-1], pts[i], pts[i+1]
+		d := cornerCut(prev, corner, next, params.MinRadius)
+		if d <= 0 {
+			dense = append(dense, corner)
+			continue
+		}
+		// 切入点与切出点
+		p0 := interpolate(corner, prev, d/getDistance(corner, prev))
+		p2 := interpolate(corner, next, d/getDistance(corner, next))
+		for k := 0; k <= bezierSamples; k++ {
+			dense = append(dense, quadBezier(p0, corner, p2, float64(k)/bezierSamples))
+		}
+	}
+	dense = append(dense, pts[len(pts)-1])
+
+	return resamplePath(dense, step)
+}
+
+// cornerCut 计算拐角处二次贝塞尔曲线的切入距离
+// 对称二次贝塞尔曲线在顶点处曲率最大：κ = tan(φ/2) / (d·cos(φ/2))，φ为转角
+func cornerCut(prev, corner, next Point, minRadius float64) float64 {
+	if minRadius <= 0 {
+		return 0
+	}
+	ax, ay := corner.X-prev.X, corner.Y-prev.Y
+	bx, by := next.X-corner.X, next.Y-corner.Y
+	phi := math.Abs(math.Atan2(cross(ax, ay, bx, by), dot(ax, ay, bx, by)))
+	if phi < 1e-9 {
+		return 0 // 直线，无需平滑
+	}
+	d := minRadius * math.Tan(phi/2) / math.Cos(phi/2)
+	limit := math.Min(getDistance(prev, corner), getDistance(corner, next)) / 2
+	if math.IsInf(d, 0) || math.IsNaN(d) || d > limit {
+		d = limit
+	}
+	return d
+}
+
+// quadBezier 二次贝塞尔曲线插值，楼层取控制点p1所在楼层
+func quadBezier(p0, p1, p2 Point, t float64) Point {
+	u := 1 - t
+	return Point{
+		X:     u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+		Y:     u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+		Floor: p1.Floor,
+	}
+}
+
+// dedupPoints 去除连续重复的路径点
+func dedupPoints(points []Point) []Point {
+	out := make([]Point, 0, len(points))
+	for _, p := range points {
+		if len(out) == 0 || out[len(out)-1] != p {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// resamplePath 按弧长等间距重采样折线，保留终点
+func resamplePath(path []Point, step float64) []Point {
+	out := []Point{path[0]}
+	next := step // 下一个采样点的累计里程
+	s := 0.0
+	for i := 0; i < len(path)-1; i++ {
+		segLen := getDistance(path[i], path[i+1])
+		for segLen > 0 && next <= s+segLen {
+			out = append(out, interpolate(path[i], path[i+1], (next-s)/segLen))
+			next += step
+		}
+		s += segLen
+	}
+	last := path[len(path)-1]
+	if getDistance(out[len(out)-1], last) > step*1e-3 {
+		out = append(out, last)
+	} else {
+		out[len(out)-1] = last
+	}
+	return out
+}
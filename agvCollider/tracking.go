@@ -0,0 +1,210 @@
+package agvCollider
+
+import (
+	"math"
+	"sync"
+)
+
+// 位姿滤波默认参数
+const (
+	DefaultHistorySize = 32  // 默认保留的历史位姿数量
+	DefaultFilterAlpha = 0.5 // 默认位置修正系数
+	DefaultFilterBeta  = 0.1 // 默认速度修正系数
+)
+
+// ===================== 位姿历史 =====================
+
+// PoseSample 一次位姿上报
+// - Pose: 上报的位姿（含噪声）
+// - Time: 上报时刻（s）
+type PoseSample struct {
+	Pose Pose
+	Time float64
+}
+
+// FilteredState 滤波后的运动状态
+// - Pose:  平滑后的位姿
+// - VX/VY: 速度分量（m/s）
+// - Speed: 速度大小（m/s）
+// - Omega: 角速度（rad/s）
+// - Time:  状态对应的时刻（s）
+type FilteredState struct {
+	Pose  Pose
+	VX    float64
+	VY    float64
+	Speed float64
+	Omega float64
+	Time  float64
+}
+
+// PoseHistory AGV位姿上报的环形缓冲区，写入时同步更新 alpha-beta 滤波状态
+// alpha-beta滤波为匀速模型下的稳态卡尔曼滤波：
+//
+//	预测 x' = x + v·dt，残差 r = z - x'
+//	修正 x = x' + α·r，v = v + β·r/dt
+//
+// 航向角按同样方式滤波，残差归一化到(-π, π]
+type PoseHistory struct {
+	mu      sync.Mutex
+	alpha   float64
+	beta    float64
+	samples []PoseSample
+	head    int // 最早样本的下标
+	count   int
+	state   FilteredState
+	ready   bool
+}
+
+// NewPoseHistory 创建位姿历史
+// 参数:
+//
+//	size: 保留的历史位姿数量，<=0时使用 DefaultHistorySize
+//	alpha: 位置修正系数(0,1]，越大越信任上报值；<=0时使用 DefaultFilterAlpha
+//	beta: 速度修正系数(0,2)，越大速度响应越快；<=0时使用 DefaultFilterBeta
+func NewPoseHistory(size int, alpha, beta float64) *PoseHistory {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	if alpha <= 0 {
+		alpha = DefaultFilterAlpha
+	}
+	if beta <= 0 {
+		beta = DefaultFilterBeta
+	}
+	return &PoseHistory{
+		alpha:   alpha,
+		beta:    beta,
+		samples: make([]PoseSample, size),
+	}
+}
+
+// Add 写入一次位姿上报并返回更新后的滤波状态
+// 早于或等于最新样本时刻的上报视为乱序，丢弃；楼层变化时重新初始化滤波
+func (h *PoseHistory) Add(s PoseSample) FilteredState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ready && s.Time <= h.state.Time {
+		return h.state
+	}
+	h.push(s)
+
+	if !h.ready || s.Pose.Floor != h.state.Pose.Floor {
+		h.state = FilteredState{Pose: s.Pose, Time: s.Time}
+		h.ready = true
+		return h.state
+	}
+	h.state = h.update(h.state, s)
+	return h.state
+}
+
+// push 写入环形缓冲区，满时覆盖最早的样本
+func (h *PoseHistory) push(s PoseSample) {
+	n := len(h.samples)
+	if h.count < n {
+		h.samples[(h.head+h.count)%n] = s
+		h.count++
+		return
+	}
+	h.samples[h.head] = s
+	h.head = (h.head + 1) % n
+}
+
+// update alpha-beta滤波的一步预测与修正
+func (h *PoseHistory) update(st FilteredState, s PoseSample) FilteredState {
+	dt := s.Time - st.Time
+
+	// 位置
+	px, py := st.Pose.X+st.VX*dt, st.Pose.Y+st.VY*dt
+	rx, ry := s.Pose.X-px, s.Pose.Y-py
+	st.Pose.X, st.Pose.Y = px+h.alpha*rx, py+h.alpha*ry
+	st.VX += h.beta * rx / dt
+	st.VY += h.beta * ry / dt
+
+	// 航向
+	pt := st.Pose.T + st.Omega*dt
+	rt := normalizeAngle(s.Pose.T - pt)
+	st.Pose.T = normalizeAngle(pt + h.alpha*rt)
+	st.Omega += h.beta * rt / dt
+
+	st.Speed = math.Hypot(st.VX, st.VY)
+	st.Time = s.Time
+	return st
+}
+
+// State 返回当前滤波状态
+// 返回:
+//
+//	FilteredState: 滤波状态
+//	bool: 是否已有上报
+func (h *PoseHistory) State() (FilteredState, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state, h.ready
+}
+
+// Predict 按匀速模型外推t时刻的滤波状态
+func (h *PoseHistory) Predict(t float64) (FilteredState, bool) {
+	st, ok := h.State()
+	if !ok {
+		return st, false
+	}
+	dt := t - st.Time
+	st.Pose.X += st.VX * dt
+	st.Pose.Y += st.VY * dt
+	st.Pose.T = normalizeAngle(st.Pose.T + st.Omega*dt)
+	st.Time = t
+	return st, true
+}
+
+// Samples 按时间顺序返回缓冲区中的上报
+func (h *PoseHistory) Samples() []PoseSample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]PoseSample, h.count)
+	for i := range out {
+		out[i] = h.samples[(h.head+i)%len(h.samples)]
+	}
+	return out
+}
+
+// Reset 清空历史和滤波状态
+func (h *PoseHistory) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.head, h.count = 0, 0
+	h.state, h.ready = FilteredState{}, false
+}
+
+// normalizeAngle 将角度归一化到(-π, π]
+func normalizeAngle(a float64) float64 {
+	a = math.Mod(a+math.Pi, 2*math.Pi)
+	if a <= 0 {
+		a += 2 * math.Pi
+	}
+	return a - math.Pi
+}
+
+// ===================== AGV遥测 =====================
+
+// Observe 接收一次位姿上报，用滤波后的状态更新AGV
+// 首次调用时按默认参数创建 History；Pose、Floor更新为平滑位姿，Velocity更新为滤波速度，
+// 之后的子路径投影与碰撞预测均从滤波状态开始
+// 参数:
+//
+//	pose: 上报的位姿
+//	t: 上报时刻（s）
+//
+// 返回:
+//
+//	FilteredState: 滤波后的运动状态
+func (agv *AGV) Observe(pose Pose, t float64) FilteredState {
+	if agv.History == nil {
+		agv.History = NewPoseHistory(0, 0, 0)
+	}
+	st := agv.History.Add(PoseSample{Pose: pose, Time: t})
+	agv.Pose = st.Pose
+	agv.Floor = st.Pose.Floor
+	agv.Velocity = st.Speed
+	return st
+}
@@ -0,0 +1,68 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+)
+
+// ===================== 轨迹 =====================
+
+// Trajectory AGV沿子路径行驶的轨迹，位姿为绝对时间的函数
+// 由当前子路径和速度曲线一次性计算，采样时不修改AGV状态，可在多个预测中复用
+type Trajectory struct {
+	T0    float64     // 出发时刻
+	Start Pose        // 无子路径时的静止位姿
+	segs  []pathSeg   // 子路径段（直线或圆弧）
+	cum   []float64   // 各路径点的累计里程
+	legs  profileLegs // 速度曲线（只拆分一次）
+}
+
+// Trajectory 按当前子路径和速度曲线计算AGV轨迹
+// 参数:
+//
+//	t0: 出发时刻，At的参数为绝对时间
+func (agv *AGV) Trajectory(t0 float64) *Trajectory {
+	tr := &Trajectory{T0: t0, Start: agv.Pose}
+	if len(agv.SubPath) < 2 {
+		return tr
+	}
+	tr.segs = agv.pathSegs(agv.SubPath)
+	tr.cum = make([]float64, len(tr.segs)+1)
+	for i, seg := range tr.segs {
+		tr.cum[i+1] = tr.cum[i] + seg.length()
+	}
+	tr.legs = agv.Profile(tr.Length()).legs()
+	return tr
+}
+
+// Length 子路径总长
+func (tr *Trajectory) Length() float64 {
+	if len(tr.cum) == 0 {
+		return 0
+	}
+	return tr.cum[len(tr.cum)-1]
+}
+
+// At 计算绝对时刻t的位姿，t早于出发时刻时为起点位姿，到达终点后停在终点
+func (tr *Trajectory) At(t float64) Pose {
+	n := len(tr.segs)
+	if n == 0 {
+		return tr.Start
+	}
+	s := tr.legs.distanceAt(t - tr.T0)
+
+	// 超出路径总长，停在终点，航向取最后一段终点处的方向
+	if s >= tr.Length() {
+		last := tr.segs[n-1].End
+		return Pose{X: last.X, Y: last.Y, T: tr.segs[n-1].headingAt(1), Floor: last.Floor}
+	}
+
+	// 找出s所在的路径段并插值
+	i := sort.SearchFloat64s(tr.cum[1:], s)
+	ratio := 0.0
+	if length := tr.cum[i+1] - tr.cum[i]; length > 0 {
+		ratio = math.Max(0, (s-tr.cum[i])/length)
+	}
+	pt := tr.segs[i].pointAt(ratio)
+	return Pose{X: pt.X, Y: pt.Y, T: tr.segs[i].headingAt(ratio), Floor: pt.Floor}
+}
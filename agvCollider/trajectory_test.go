@@ -0,0 +1,100 @@
+package agvCollider
+
+import (
+	"math"
+	"testing"
+)
+
+const poseTolerance = 1e-9
+
+func TestTrajectoryAt(t *testing.T) {
+	straight := &AGV{
+		Pose:    Pose{X: 0, Y: 0},
+		Speed:   2,
+		SubPath: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}},
+	}
+	turn := &AGV{
+		Pose:    Pose{X: 0, Y: 0},
+		Speed:   1,
+		SubPath: []Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 3}},
+	}
+	accel := &AGV{
+		Pose:     Pose{X: 0, Y: 0},
+		Speed:    2,
+		MaxAccel: 1,
+		SubPath:  []Point{{X: 0, Y: 0}, {X: 20, Y: 0}},
+	}
+	dwell := &AGV{
+		Pose:    Pose{X: 0, Y: 0},
+		Speed:   1,
+		Stops:   []StopPoint{{S: 5, Dwell: 3}},
+		SubPath: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}},
+	}
+	parked := &AGV{
+		Pose:  Pose{X: 3, Y: 4, T: 1, Floor: 2},
+		Speed: 1,
+	}
+
+	tests := []struct {
+		name string
+		agv  *AGV
+		t0   float64
+		t    float64
+		want Pose
+	}{
+		{"出发前停在起点", straight, 0, -1, Pose{X: 0, Y: 0}},
+		{"直线匀速", straight, 0, 2.5, Pose{X: 5, Y: 0}},
+		{"到达终点后停在终点", straight, 0, 10, Pose{X: 10, Y: 0}},
+		{"出发时刻偏移", straight, 2, 3, Pose{X: 2, Y: 0}},
+		{"折线第一段", turn, 0, 2, Pose{X: 2, Y: 0}},
+		{"折线拐点后", turn, 0, 5, Pose{X: 4, Y: 1, T: math.Pi / 2}},
+		{"折线终点保持末段航向", turn, 0, 100, Pose{X: 4, Y: 3, T: math.Pi / 2}},
+		{"加速段", accel, 0, 1, Pose{X: 0.5, Y: 0}},
+		{"加速后匀速", accel, 0, 3, Pose{X: 4, Y: 0}},
+		{"停靠点停留", dwell, 0, 6.5, Pose{X: 5, Y: 0}},
+		{"停留后继续行驶", dwell, 0, 9, Pose{X: 6, Y: 0}},
+		{"无子路径时静止", parked, 0, 5, Pose{X: 3, Y: 4, T: 1, Floor: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.agv.Trajectory(tt.t0).At(tt.t)
+			if !posesEqual(got, tt.want) {
+				t.Errorf("At(%v) = %+v, want %+v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrajectoryAtStateless(t *testing.T) {
+	agv := &AGV{
+		Pose:    Pose{X: 0, Y: 0},
+		Speed:   1,
+		SubPath: []Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 3}},
+	}
+	pose := agv.Pose
+	subPath := append([]Point(nil), agv.SubPath...)
+
+	tr := agv.Trajectory(0)
+	// 乱序采样的结果与顺序无关
+	for _, at := range []float64{6, 1, 6, 3} {
+		want := agv.Trajectory(0).At(at)
+		if got := tr.At(at); !posesEqual(got, want) {
+			t.Errorf("At(%v) = %+v, want %+v", at, got, want)
+		}
+	}
+	if agv.Pose != pose {
+		t.Errorf("At修改了AGV位姿: %+v", agv.Pose)
+	}
+	for i, p := range subPath {
+		if agv.SubPath[i] != p {
+			t.Fatalf("At修改了子路径: %+v", agv.SubPath)
+		}
+	}
+}
+
+func posesEqual(a, b Pose) bool {
+	return a.Floor == b.Floor &&
+		math.Abs(a.X-b.X) <= poseTolerance &&
+		math.Abs(a.Y-b.Y) <= poseTolerance &&
+		math.Abs(a.T-b.T) <= poseTolerance
+}
@@ -0,0 +1,198 @@
+package agvCollider
+
+import (
+	"math"
+	"sort"
+)
+
+// 区域违规类型
+const (
+	ViolationNoGo      = "NO_GO"      // 进入禁行区
+	ViolationOverSpeed = "OVER_SPEED" // 超过区域限速
+	ViolationWrongWay  = "WRONG_WAY"  // 逆向驶入单行区
+)
+
+// ===================== 区域定义 =====================
+
+// Zone 表示地图上的限制区域
+// - Id:         区域编号
+// - Floor:      所在楼层
+// - Polygon:    区域轮廓（世界坐标，简单多边形，可为凹多边形）
+// - NoGo:       是否禁行
+// - SpeedLimit: 区域限速（m/s），为0时不限速
+// - OneWay:     是否单行
+// - Direction:  单行区允许的行驶方向，单位弧度；与之夹角超过90°视为逆行
+type Zone struct {
+	Id         string
+	Floor      int
+	Polygon    Polygon
+	NoGo       bool
+	SpeedLimit float64
+	OneWay     bool
+	Direction  float64
+}
+
+// ZoneViolation 表示AGV路径违反区域限制的信息
+type ZoneViolation struct {
+	AGV      *AGV    // 违规AGV
+	Zone     *Zone   // 违规区域
+	Type     string  // 违规类型：NO_GO / OVER_SPEED / WRONG_WAY
+	Point    Point   // 进入区域的位置
+	Distance float64 // 从路径起点到进入位置的里程
+	Time     float64 // 按速度曲线到达进入位置的时间
+}
+
+// Contains 判断点是否位于多边形内部（射线法，适用于凹多边形）
+func (p Polygon) Contains(pt Point) bool {
+	inside := false
+	n := len(p)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Y > pt.Y) != (b.Y > pt.Y) &&
+			pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// segmentEntry 计算线段首次进入多边形区域的位置
+// 线段与区域轮廓的距离不超过margin即视为进入（margin取车宽一半）
+// 返回:
+//
+//	Point:   进入位置
+//	float64: 进入位置在线段上的比例
+//	bool:    是否进入区域
+func (p Polygon) segmentEntry(seg Segment, margin float64) (Point, float64, bool) {
+	if p.Contains(seg.Start) {
+		return seg.Start, 0, true
+	}
+
+	best := math.MaxFloat64
+	var entry Point
+	n := len(p)
+	for i := 0; i < n; i++ {
+		edge := Segment{Start: p[i], End: p[(i+1)%n]}
+		a, b := closestSegmentPoints(seg, edge)
+		if getDistance(a, b) > margin {
+			continue
+		}
+		_, ratio := projectPointOnSegment(Pose{X: a.X, Y: a.Y, Floor: a.Floor}, seg)
+		if ratio < best {
+			best = ratio
+			entry = a
+		}
+	}
+	if best == math.MaxFloat64 {
+		if p.Contains(seg.End) {
+			return seg.End, 1, true
+		}
+		return Point{}, 0, false
+	}
+	return entry, best, true
+}
+
+// violationType 根据区域属性判断进入区域是否违规
+func (z *Zone) violationType(agv *AGV, heading float64) (string, bool) {
+	switch {
+	case z.NoGo:
+		return ViolationNoGo, true
+	case z.SpeedLimit > 0 && agv.Speed > z.SpeedLimit:
+		return ViolationOverSpeed, true
+	case z.OneWay && math.Cos(heading-z.Direction) < 0:
+		return ViolationWrongWay, true
+	}
+	return "", false
+}
+
+// ===================== 区域检查 =====================
+
+// CheckZones 检查AGV路径是否违反区域限制
+// 每个区域只报告首次违规的位置，圆弧路径按弦拆分后检查
+// 参数:
+//
+//	agv:   待检查的AGV
+//	zones: 区域列表
+//
+// 返回:
+//
+//	[]ZoneViolation: 违规信息，按到达时间排序
+func (agv *AGV) CheckZones(zones []Zone) []ZoneViolation {
+	segs := agv.pathSegs(agv.Path)
+	profile := agv.Profile(pathLength(segs)).legs()
+	margin := agv.Width / 2
+
+	var out []ZoneViolation
+	for zi := range zones {
+		z := &zones[zi]
+		s0 := 0.0
+	segLoop:
+		for _, seg := range segs {
+			if f, ok := seg.floor(); !ok || f != z.Floor {
+				s0 += seg.length()
+				continue
+			}
+			pieces, _ := seg.pieces()
+			for k, piece := range pieces {
+				p, r, ok := z.Polygon.segmentEntry(piece, margin)
+				if !ok {
+					continue
+				}
+				ratio := (float64(k) + r) / float64(len(pieces))
+				typ, bad := z.violationType(agv, seg.headingAt(ratio))
+				if !bad {
+					continue
+				}
+				s := s0 + seg.length()*ratio
+				out = append(out, ZoneViolation{
+					AGV:      agv,
+					Zone:     z,
+					Type:     typ,
+					Point:    p,
+					Distance: s,
+					Time:     profile.timeAt(s),
+				})
+				break segLoop
+			}
+			s0 += seg.length()
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time < out[j].Time })
+	return out
+}
+
+// CheckZonesForFleet 检查车队所有AGV的区域违规
+func CheckZonesForFleet(agvs []*AGV, zones []Zone) []ZoneViolation {
+	var out []ZoneViolation
+	for _, agv := range agvs {
+		out = append(out, agv.CheckZones(zones)...)
+	}
+	return out
+}
+
+// ===================== 车队检测汇总 =====================
+
+// FleetReport 车队检测结果
+type FleetReport struct {
+	Collisions []CollisionPrediction // 预测碰撞
+	Violations []ZoneViolation       // 区域违规
+}
+
+// AnalyzeFleet 对车队进行碰撞预测和区域检查
+// 参数:
+//
+//	agvs: AGV车队
+//	zones: 区域列表
+//	timeRange: 预测时间范围（秒）
+//	timeStep: 时间步长（秒）
+//	collisionThreshold: 碰撞距离阈值（米）
+//
+// 返回:
+//
+//	FleetReport: 碰撞与区域违规汇总
+func AnalyzeFleet(agvs []*AGV, zones []Zone, timeRange, timeStep, collisionThreshold float64) FleetReport {
+	return FleetReport{
+		Collisions: PredictCollisionsForFleetOptimized(agvs, timeRange, timeStep, collisionThreshold, true),
+		Violations: CheckZonesForFleet(agvs, zones),
+	}
+}
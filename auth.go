@@ -0,0 +1,213 @@
+package common
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/middleware/selector"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// 默认JWKS刷新间隔
+	DefaultJWKSRefresh = 10 * time.Minute
+
+	// 两次JWKS拉取的最小间隔，期间未知kid直接拒绝，避免伪造kid触发大量请求
+	DefaultJWKSMinRefresh = 30 * time.Second
+)
+
+// JWTAuth 服务端JWT认证中间件，使用RS256校验Bearer令牌并将claims注入上下文
+// allowlist中的operation无需认证，以*结尾表示前缀匹配，如 /api.health.v1.Health/*
+func JWTAuth(keyFunc jwtv5.Keyfunc, allowlist ...string) middleware.Middleware {
+	auth := jwt.Server(keyFunc,
+		jwt.WithSigningMethod(jwtv5.SigningMethodRS256),
+		jwt.WithClaims(func() jwtv5.Claims { return jwtv5.MapClaims{} }),
+	)
+	if len(allowlist) == 0 {
+		return auth
+	}
+
+	return selector.Server(auth).Match(func(_ context.Context, operation string) bool {
+		return !allowed(allowlist, operation)
+	}).Build()
+}
+
+// ClaimsFromContext 获取认证中间件注入的claims
+func ClaimsFromContext(ctx context.Context) (jwtv5.MapClaims, bool) {
+	c, ok := jwt.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := c.(jwtv5.MapClaims)
+	return claims, ok
+}
+
+// RSAKeyFunc 使用RSAKeyManager中的公钥校验令牌
+func RSAKeyFunc(km *RSAKeyManager) (jwtv5.Keyfunc, error) {
+	pub, err := km.LoadPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return func(*jwtv5.Token) (interface{}, error) {
+		return pub, nil
+	}, nil
+}
+
+// SignJWT 使用RSA私钥签发RS256令牌
+func SignJWT(key *rsa.PrivateKey, claims jwtv5.Claims) (string, error) {
+	return jwtv5.NewWithClaims(jwtv5.SigningMethodRS256, claims).SignedString(key)
+}
+
+// JWKSKeyFunc 从JWKS地址获取公钥并按令牌头中的kid选择
+// 公钥按refresh间隔缓存，遇到未知kid时立即刷新，refresh<=0时使用默认值10分钟；
+// 两次拉取至少间隔 DefaultJWKSMinRefresh，并发请求共享同一次拉取
+func JWKSKeyFunc(url string, refresh time.Duration) jwtv5.Keyfunc {
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+	j := &jwks{url: url, refresh: refresh, minRefresh: DefaultJWKSMinRefresh, client: &http.Client{Timeout: 10 * time.Second}}
+	return j.keyFunc
+}
+
+// jwks JWKS公钥缓存
+type jwks struct {
+	url        string
+	refresh    time.Duration
+	minRefresh time.Duration
+	client     *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetched   time.Time     // 上次拉取成功的时间
+	attempted time.Time     // 上次开始拉取的时间
+	inflight  chan struct{} // 正在进行的拉取，结束时关闭
+}
+
+// keyFunc 按kid返回公钥
+func (j *jwks) keyFunc(token *jwtv5.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	j.mu.Lock()
+	key, ok := j.keys[kid]
+	fresh := time.Since(j.fetched) < j.refresh
+	j.mu.Unlock()
+	if ok && fresh {
+		return key, nil
+	}
+
+	err := j.refreshKeys()
+
+	j.mu.Lock()
+	key, ok = j.keys[kid]
+	j.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("JWKS中不存在kid: %s", kid)
+}
+
+// refreshKeys 拉取JWKS，距上次拉取不足minRefresh时跳过；已有拉取进行中时等待其结束
+func (j *jwks) refreshKeys() error {
+	j.mu.Lock()
+	if wait := j.inflight; wait != nil {
+		j.mu.Unlock()
+		<-wait
+		return nil
+	}
+	if !j.attempted.IsZero() && time.Since(j.attempted) < j.minRefresh {
+		j.mu.Unlock()
+		return nil
+	}
+	done := make(chan struct{})
+	j.inflight = done
+	j.attempted = time.Now()
+	j.mu.Unlock()
+
+	keys, err := j.fetch()
+
+	j.mu.Lock()
+	if err == nil {
+		j.keys = keys
+		j.fetched = time.Now()
+	}
+	j.inflight = nil
+	j.mu.Unlock()
+	close(done)
+	return err
+}
+
+// fetch 拉取并解析JWKS
+func (j *jwks) fetch() (map[string]*rsa.PublicKey, error) {
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return nil, fmt.Errorf("获取JWKS失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取JWKS失败: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("解析JWKS失败: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS中没有可用的RSA公钥")
+	}
+
+	return keys, nil
+}
+
+// allowed 判断operation是否匹配列表中的规则，以*结尾的规则按前缀匹配
+func allowed(allowlist []string, operation string) bool {
+	for _, p := range allowlist {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(operation, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if p == operation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// 默认引导配置文件名
+	DefaultBootstrapFile = "config.yaml"
+
+	// 引入其他引导配置文件的键
+	BootstrapIncludeKey = "include"
+)
+
+// loadBootstrap 加载引导配置文件并返回合并后的值
+// - 文件中的 include 列出的文件先加载(相对路径基于当前文件所在目录)，当前文件覆盖其中的同名键，可嵌套引入；
+// - 文件内容中的 ${NAME} 和 ${NAME:default} 先用环境变量展开再解析；
+// - profile非空时再用同目录的 {name}.{profile}{ext} 覆盖(不存在时忽略)
+func loadBootstrap(path, profile string) (map[string]interface{}, error) {
+	values, err := loadBootstrapFile(path, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	if profile != "" {
+		ext := filepath.Ext(path)
+		p := strings.TrimSuffix(path, ext) + "." + profile + ext
+		if _, err := os.Stat(p); err == nil {
+			overlay, err := loadBootstrapFile(p, map[string]bool{})
+			if err != nil {
+				return nil, err
+			}
+			mergeValues(values, overlay)
+		}
+	}
+	return values, nil
+}
+
+// loadBootstrapFile 加载单个文件及其引入的文件，visiting用于检测循环引入
+func loadBootstrapFile(path string, visiting map[string]bool) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if visiting[abs] {
+		return nil, fmt.Errorf("引导配置循环引入: %s", path)
+	}
+	visiting[abs] = true
+	defer delete(visiting, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = []byte(expandEnv(string(data)))
+
+	cur := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &cur); err != nil {
+		return nil, fmt.Errorf("解析引导配置失败(%s): %w", path, err)
+	}
+
+	includes, err := bootstrapIncludes(cur[BootstrapIncludeKey])
+	if err != nil {
+		return nil, fmt.Errorf("引导配置%s的include无效: %w", path, err)
+	}
+	delete(cur, BootstrapIncludeKey)
+
+	values := make(map[string]interface{})
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		sub, err := loadBootstrapFile(inc, visiting)
+		if err != nil {
+			return nil, err
+		}
+		mergeValues(values, sub)
+	}
+	mergeValues(values, cur)
+	return values, nil
+}
+
+// bootstrapIncludes 解析include，支持单个字符串或字符串列表
+func bootstrapIncludes(v interface{}) ([]string, error) {
+	switch vt := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{vt}, nil
+	case []interface{}:
+		files := make([]string, 0, len(vt))
+		for _, f := range vt {
+			s, ok := f.(string)
+			if !ok {
+				return nil, fmt.Errorf("文件名必须是字符串: %v", f)
+			}
+			files = append(files, s)
+		}
+		return files, nil
+	default:
+		return nil, fmt.Errorf("不支持的类型%T", v)
+	}
+}
+
+// expandEnv 用环境变量展开 ${NAME} 和 ${NAME:default}
+func expandEnv(s string) string {
+	return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		args := strings.SplitN(strings.TrimSpace(m[2:len(m)-1]), ":", 2)
+		if v, ok := os.LookupEnv(args[0]); ok {
+			return v
+		}
+		if len(args) > 1 {
+			return args[1]
+		}
+		return ""
+	})
+}
+
+// mergeValues 将src深度合并到dst，map递归合并，其他类型直接覆盖
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		sm, ok := v.(map[string]interface{})
+		if dm, exists := dst[k].(map[string]interface{}); ok && exists {
+			mergeValues(dm, sm)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// decodeSection 将节点按json标签解码到out，节点不存在时不处理
+func decodeSection(values map[string]interface{}, key string, out interface{}) error {
+	v, ok := values[key]
+	if !ok || v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("解析引导配置%s失败: %w", key, err)
+	}
+	return nil
+}
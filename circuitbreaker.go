@@ -0,0 +1,160 @@
+package common
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/go-kratos/aegis/circuitbreaker/sre"
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	kratosbreaker "github.com/go-kratos/kratos/v2/middleware/circuitbreaker"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// 熔断配置键
+const CircuitBreakerConfigKey = "circuitbreaker"
+
+// CircuitBreakerRule 熔断阈值，零值字段使用默认值
+type CircuitBreakerRule struct {
+	Success float64  `json:"success"` // 成功率阈值，默认0.6
+	Request int64    `json:"request"` // 窗口内最少请求数，默认100
+	Window  Duration `json:"window"`  // 统计窗口，默认3s
+	Bucket  int      `json:"bucket"`  // 窗口分桶数，默认10
+}
+
+// CircuitBreakerConfig 熔断配置，对应配置中的 circuitbreaker 节点
+type CircuitBreakerConfig struct {
+	Default CircuitBreakerRule            `json:"default"` // 默认阈值
+	Targets map[string]CircuitBreakerRule `json:"targets"` // 按目标服务覆盖的阈值
+}
+
+// CircuitBreaker 出站调用熔断器，按 目标服务+operation 维护独立的熔断状态
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	cfg      CircuitBreakerConfig
+	breakers map[string]circuitbreaker.CircuitBreaker
+
+	rejected *Counter
+	failed   *Counter
+}
+
+// NewCircuitBreaker 创建熔断器，metrics为nil时不记录指标
+func NewCircuitBreaker(cfg CircuitBreakerConfig, metrics *Metrics) (*CircuitBreaker, error) {
+	cb := &CircuitBreaker{
+		cfg:      cfg,
+		breakers: make(map[string]circuitbreaker.CircuitBreaker),
+	}
+	if metrics != nil {
+		var err error
+		cb.rejected, err = metrics.Counter("client_circuitbreaker_rejected_total", "熔断拒绝的出站请求数", "target", "operation")
+		if err != nil {
+			return nil, err
+		}
+		cb.failed, err = metrics.Counter("client_circuitbreaker_failed_total", "计入熔断统计的失败请求数", "target", "operation")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cb, nil
+}
+
+// Update 按新配置重置所有熔断状态
+func (cb *CircuitBreaker) Update(cfg CircuitBreakerConfig) {
+	cb.mu.Lock()
+	cb.cfg = cfg
+	cb.breakers = make(map[string]circuitbreaker.CircuitBreaker)
+	cb.mu.Unlock()
+}
+
+// Client 客户端熔断中间件
+// 熔断时直接返回503，下游返回500/503/504时计为失败
+func (cb *CircuitBreaker) Client(target string) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var operation string
+			if info, ok := transport.FromClientContext(ctx); ok {
+				operation = info.Operation()
+			}
+
+			breaker := cb.breaker(target, operation)
+			if err := breaker.Allow(); err != nil {
+				// 本地拒绝也计入失败，使拒绝率随之升高
+				breaker.MarkFailed()
+				if cb.rejected != nil {
+					cb.rejected.Inc(ctx, target, operation)
+				}
+				return nil, kratosbreaker.ErrNotAllowed
+			}
+
+			reply, err := handler(ctx, req)
+			if err != nil && (errors.IsInternalServer(err) || errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err)) {
+				breaker.MarkFailed()
+				if cb.failed != nil {
+					cb.failed.Inc(ctx, target, operation)
+				}
+			} else {
+				breaker.MarkSuccess()
+			}
+			return reply, err
+		}
+	}
+}
+
+// NewCircuitBreakerFromConfig 从配置的 circuitbreaker 节点创建熔断器，并在配置变化时热更新
+func NewCircuitBreakerFromConfig(r *appResult) (*CircuitBreaker, error) {
+	var cfg CircuitBreakerConfig
+	if err := r.Cfg.Value(CircuitBreakerConfigKey).Scan(&cfg); err != nil && err != config.ErrNotFound {
+		return nil, err
+	}
+
+	cb, err := NewCircuitBreaker(cfg, r.Metrics)
+	if err != nil {
+		return nil, err
+	}
+	err = Watch(r, CircuitBreakerConfigKey, func(cfg CircuitBreakerConfig) error {
+		cb.Update(cfg)
+		log.NewHelper(r.Logger).Info("熔断配置已更新")
+		return nil
+	})
+	if err != nil && err != config.ErrNotFound {
+		return nil, err
+	}
+	return cb, nil
+}
+
+// breaker 获取或创建 目标服务+operation 对应的熔断状态
+func (cb *CircuitBreaker) breaker(target, operation string) circuitbreaker.CircuitBreaker {
+	key := target + "|" + operation
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if b, ok := cb.breakers[key]; ok {
+		return b
+	}
+
+	rule := cb.cfg.Default
+	if r, ok := cb.cfg.Targets[target]; ok {
+		rule = r
+	}
+	var opts []sre.Option
+	if rule.Success > 0 {
+		opts = append(opts, sre.WithSuccess(rule.Success))
+	}
+	if rule.Request > 0 {
+		opts = append(opts, sre.WithRequest(rule.Request))
+	}
+	if rule.Window > 0 {
+		opts = append(opts, sre.WithWindow(rule.Window.Std()))
+	}
+	if rule.Bucket > 0 {
+		opts = append(opts, sre.WithBucket(rule.Bucket))
+	}
+
+	b := sre.NewBreaker(opts...)
+	cb.breakers[key] = b
+	return b
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	ggrpc "google.golang.org/grpc"
+)
+
+// DiscoveryEndpoint 生成服务发现地址 discovery:///service-name
+// 使用Nacos注册中心时，服务按 "<服务名>.<协议>" 注册，例如 "dispatch.grpc"
+func DiscoveryEndpoint(serviceName string) string {
+	return "discovery:///" + serviceName
+}
+
+// DefaultClientMiddlewares 默认客户端中间件，透传请求ID和上下文元数据
+// 自定义客户端中间件时需一并加入，否则会覆盖默认中间件
+func DefaultClientMiddlewares() []middleware.Middleware {
+	return []middleware.Middleware{
+		RequestIDClient(),
+		ContextMetadataClient(),
+	}
+}
+
+// NewGRPCClient 通过服务发现创建gRPC客户端连接(非TLS)
+// opts 中的选项会在默认选项之后应用，可覆盖默认值
+func NewGRPCClient(
+	ctx context.Context,
+	dis registry.Discovery,
+	serviceName string,
+	opts ...grpc.ClientOption,
+) (*ggrpc.ClientConn, error) {
+	opts = append([]grpc.ClientOption{
+		grpc.WithEndpoint(DiscoveryEndpoint(serviceName)),
+		grpc.WithDiscovery(dis),
+		grpc.WithMiddleware(DefaultClientMiddlewares()...),
+	}, opts...)
+
+	return grpc.DialInsecure(ctx, opts...)
+}
+
+// NewHTTPClient 通过服务发现创建HTTP客户端
+// opts 中的选项会在默认选项之后应用，可覆盖默认值
+func NewHTTPClient(
+	ctx context.Context,
+	dis registry.Discovery,
+	serviceName string,
+	opts ...http.ClientOption,
+) (*http.Client, error) {
+	opts = append([]http.ClientOption{
+		http.WithEndpoint(DiscoveryEndpoint(serviceName)),
+		http.WithDiscovery(dis),
+		http.WithMiddleware(DefaultClientMiddlewares()...),
+	}, opts...)
+
+	return http.NewClient(ctx, opts...)
+}
@@ -1,39 +1,149 @@
 package common
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type appConfig struct {
-	Nacos nacosSettings `yaml:"nacos"`
+	Standalone  bool               `yaml:"standalone"` // 单机模式，不连接Nacos
+	Nacos       nacosSettings      `yaml:"nacos"`
+	Registry    registrySettings   `yaml:"registry"`
+	ConfigMerge *ConfigMergePolicy `yaml:"config_merge"` // 配置源优先级和合并策略
+	Shutdown    shutdownSettings   `yaml:"shutdown"`
+	Log         logSettings        `yaml:"log"`
+	Sentry      *SentryConfig      `yaml:"sentry"`
+	Trace       *TraceConfig       `yaml:"-"` // 应用配置中没有trace时使用
+	Metrics     *MetricsConfig     `yaml:"-"` // 应用配置中没有metrics时使用
+}
+
+type logSettings struct {
+	Level    string               `yaml:"level"`
+	Backend  string               `yaml:"backend"`
+	Format   string               `yaml:"format"`
+	Sampling *logSamplingSettings `yaml:"sampling"`
+}
+
+type logSamplingSettings struct {
+	First      int `yaml:"first"`
+	Thereafter int `yaml:"thereafter"`
+}
+
+type registrySettings struct {
+	Weight            float64           `yaml:"weight"`
+	Metadata          map[string]string `yaml:"metadata"`
+	Group             string            `yaml:"group"`
+	Cluster           string            `yaml:"cluster"`
+	Ephemeral         *bool             `yaml:"ephemeral"`
+	DiscoveryClusters []string          `yaml:"discovery_clusters"`
+	Warmup            *warmupSettings   `yaml:"warmup"`
+}
+
+type warmupSettings struct {
+	InitialWeight float64       `yaml:"initial_weight"` // 初始权重
+	Ramp          time.Duration `yaml:"ramp"`           // 升到目标权重的时间
+	Timeout       time.Duration `yaml:"timeout"`        // 预热任务总超时
+}
+
+type shutdownSettings struct {
+	Drain   time.Duration `yaml:"drain"`
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 type nacosSettings struct {
 	UserName string        `yaml:"username"`
 	Password string        `yaml:"password"`
+	Endpoint string        `yaml:"endpoint"`
 	Servers  []nacosServer `yaml:"servers"`
+	TLS      *nacosTLS     `yaml:"tls"`
+	Timeout  time.Duration `yaml:"timeout"`
+	LogDir   string        `yaml:"log_dir"`
+	CacheDir string        `yaml:"cache_dir"`
+	LogLevel string        `yaml:"log_level"`
 }
 
 type nacosServer struct {
-	Host string `yaml:"host"`
-	Port uint64 `yaml:"port"`
+	Host        string `yaml:"host" json:"host"`
+	Port        uint64 `yaml:"port" json:"port"`
+	Scheme      string `yaml:"scheme" json:"scheme"`
+	ContextPath string `yaml:"context_path" json:"context_path"`
+}
+
+type nacosTLS struct {
+	CAFile     string `yaml:"ca_file"`
+	SkipVerify bool   `yaml:"skip_verify"`
 }
 
+// NewConfig 读取引导配置
+// path为目录时读取其中的 config.yaml，为文件时直接读取该文件，加载规则见 loadBootstrap
 func NewConfig(path string) (*appConfig, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, DefaultBootstrapFile)
+	}
 
-	f, err := os.ReadFile(path + "/config.yaml")
+	values, err := loadBootstrap(path, ActiveProfile())
 	if err != nil {
 		return nil, err
 	}
 
-	var c appConfig
-	err = yaml.Unmarshal(f, &c)
+	// 回写为yaml后按yaml标签解码，trace、metrics等节点按json标签解码
+	b, err := yaml.Marshal(values)
 	if err != nil {
 		return nil, err
-
+	}
+	var c appConfig
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("解析引导配置失败(%s): %w", path, err)
+	}
+	if err := decodeSection(values, "trace", &c.Trace); err != nil {
+		return nil, err
+	}
+	if err := decodeSection(values, "metrics", &c.Metrics); err != nil {
+		return nil, err
 	}
 
 	return &c, nil
 }
+
+// NacosCfgSource 根据引导配置创建Nacos配置源
+func (c *appConfig) NacosCfgSource() *NacosCfgSource {
+	nfs := NewNacosCfgSource(nacosHosts(c.Nacos.Servers), c.Nacos.UserName, c.Nacos.Password).
+		WithEndpoint(c.Nacos.Endpoint)
+	if c.Nacos.Timeout > 0 {
+		nfs.WithTimeout(c.Nacos.Timeout)
+	}
+	if c.Nacos.LogDir != "" {
+		nfs.WithLogDir(c.Nacos.LogDir)
+	}
+	if c.Nacos.CacheDir != "" {
+		nfs.WithCacheDir(c.Nacos.CacheDir)
+	}
+	if c.Nacos.LogLevel != "" {
+		nfs.WithLogLevel(c.Nacos.LogLevel)
+	}
+	if c.Nacos.TLS != nil {
+		nfs.WithTLS(&NacosTLS{
+			CAFile:     c.Nacos.TLS.CAFile,
+			SkipVerify: c.Nacos.TLS.SkipVerify,
+		})
+	}
+	return nfs
+}
+
+// nacosHosts 转换引导配置中的服务器列表
+func nacosHosts(servers []nacosServer) []*NacosHost {
+	svs := make([]*NacosHost, len(servers))
+	for i, s := range servers {
+		svs[i] = &NacosHost{
+			Host:        s.Host,
+			Port:        s.Port,
+			Scheme:      s.Scheme,
+			ContextPath: s.ContextPath,
+		}
+	}
+	return svs
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/lnhlg/gbm-common/i18n"
+)
+
+const (
+	// 加密配置值前缀
+	encPrefix = "ENC("
+
+	// 加密配置值后缀
+	encSuffix = ")"
+)
+
+// DecryptResolver 解密配置中形如 ENC(base64密文) 的值
+// 配置加载和每次热更新时都会执行，解密失败时返回错误，热更新会保留旧配置
+func DecryptResolver(dec *RSADecryptor) config.Resolver {
+	return decryptResolver(dec, nil)
+}
+
+// decryptResolver 解密配置，每解密一个值调用一次mark(点分路径)
+func decryptResolver(dec *RSADecryptor, mark func(path string)) config.Resolver {
+	return func(input map[string]interface{}) error {
+		return decryptValues(dec, "", input, mark)
+	}
+}
+
+// EncryptConfigValue 加密配置值，返回可直接写入配置的 ENC(...) 文本
+func EncryptConfigValue(enc *RSAEncryptor, plaintext string) (string, error) {
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return encPrefix + ciphertext + encSuffix, nil
+}
+
+// decryptValues 递归解密map和数组中的加密值
+func decryptValues(dec *RSADecryptor, prefix string, v interface{}, mark func(string)) error {
+	decrypt := func(path string, s string) (string, error) {
+		plain, err := decryptValue(dec, s)
+		if err != nil {
+			return "", i18n.Errorf("解密配置失败(%s): %w", path, err)
+		}
+		if plain != s && mark != nil {
+			mark(path)
+		}
+		return plain, nil
+	}
+
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range vt {
+			path := joinPath(prefix, k)
+			if s, ok := sub.(string); ok {
+				plain, err := decrypt(path, s)
+				if err != nil {
+					return err
+				}
+				vt[k] = plain
+				continue
+			}
+			if err := decryptValues(dec, path, sub, mark); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, sub := range vt {
+			path := joinPath(prefix, strconv.Itoa(i))
+			if s, ok := sub.(string); ok {
+				plain, err := decrypt(path, s)
+				if err != nil {
+					return err
+				}
+				vt[i] = plain
+				continue
+			}
+			if err := decryptValues(dec, path, sub, mark); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decryptValue 解密单个值，非 ENC(...) 形式的值原样返回
+func decryptValue(dec *RSADecryptor, s string) (string, error) {
+	t := strings.TrimSpace(s)
+	if !strings.HasPrefix(t, encPrefix) || !strings.HasSuffix(t, encSuffix) {
+		return s, nil
+	}
+	return dec.Decrypt(t[len(encPrefix) : len(t)-len(encSuffix)])
+}
+
+// joinPath 拼接点分路径
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// chainResolvers 依次执行多个解析器
+func chainResolvers(rs ...config.Resolver) config.Resolver {
+	return func(input map[string]interface{}) error {
+		for _, r := range rs {
+			if err := r(input); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
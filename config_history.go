@@ -0,0 +1,338 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// 默认保留的配置版本数
+const DefaultConfigHistorySize = 20
+
+// ConfigSnapshot 一次加载后的配置快照
+type ConfigSnapshot struct {
+	Revision int                    `json:"revision"` // 版本号，从1开始递增
+	Hash     string                 `json:"hash"`     // 配置内容的sha256
+	Time     time.Time              `json:"time"`     // 加载时间
+	Values   map[string]interface{} `json:"values"`   // 合并后的配置，解密后的值以摘要代替，仅用于对比和展示
+	// Sources 各配置源的原始内容（未展开占位符、未解密），按配置源顺序排列，回滚时按此重新发布
+	Sources []ConfigSourceContent `json:"-"`
+}
+
+// ConfigSourceContent 单个配置源加载的原始内容
+type ConfigSourceContent struct {
+	Source int                // 配置源序号，与传给 config.WithSource 的顺序一致
+	Values []*config.KeyValue // 原始键值，如Nacos的dataId及内容
+}
+
+// ConfigChange 配置项变化
+type ConfigChange struct {
+	Key string      `json:"key"` // 点分路径，如 server.http.addr
+	Old interface{} `json:"old"` // 为nil表示新增
+	New interface{} `json:"new"` // 为nil表示删除
+}
+
+// RollbackHook 回滚钩子，接收要回滚到的配置快照
+type RollbackHook func(target *ConfigSnapshot) error
+
+// ConfigHistory 配置版本历史，配置内容变化时记录新版本
+type ConfigHistory struct {
+	mu        sync.RWMutex
+	size      int
+	revision  int
+	snapshots []*ConfigSnapshot
+	hooks     []RollbackHook
+	secrets   map[string]struct{}        // 解密过的配置路径，快照中不保存明文
+	raw       map[int][]*config.KeyValue // 各配置源最近一次加载的原始内容
+	sources   int                        // 已包装的配置源数量
+}
+
+// NewConfigHistory 创建配置版本历史，size<=0时保留20个版本
+func NewConfigHistory(size int) *ConfigHistory {
+	if size <= 0 {
+		size = DefaultConfigHistorySize
+	}
+	return &ConfigHistory{size: size, secrets: make(map[string]struct{}), raw: make(map[int][]*config.KeyValue)}
+}
+
+// Source 包装配置源，记录其加载和变化时的原始内容，供回滚使用
+// 需按传给 config.WithSource 的顺序依次包装
+func (h *ConfigHistory) Source(src config.Source) config.Source {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	idx := h.sources
+	h.sources++
+	return &historySource{Source: src, history: h, idx: idx}
+}
+
+// record 记录配置源加载的原始内容，同名键覆盖，其余保留
+func (h *ConfigHistory) record(idx int, kvs []*config.KeyValue) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cur := h.raw[idx]
+	for _, kv := range kvs {
+		cp := &config.KeyValue{Key: kv.Key, Format: kv.Format, Value: append([]byte(nil), kv.Value...)}
+		replaced := false
+		for i, old := range cur {
+			if old.Key == kv.Key {
+				cur[i] = cp
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			cur = append(cur, cp)
+		}
+	}
+	h.raw[idx] = cur
+}
+
+// rawSources 当前各配置源原始内容的副本，调用方需持有h.mu
+func (h *ConfigHistory) rawSources() []ConfigSourceContent {
+	idxs := make([]int, 0, len(h.raw))
+	for idx := range h.raw {
+		idxs = append(idxs, idx)
+	}
+	sort.Ints(idxs)
+	out := make([]ConfigSourceContent, 0, len(idxs))
+	for _, idx := range idxs {
+		out = append(out, ConfigSourceContent{Source: idx, Values: append([]*config.KeyValue(nil), h.raw[idx]...)})
+	}
+	return out
+}
+
+// Resolver 记录版本的配置解析器，配合 config.WithResolver 使用
+func (h *ConfigHistory) Resolver(input map[string]interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	maskSecrets(values, "", h.secrets)
+	if data, err = json.Marshal(values); err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if n := len(h.snapshots); n > 0 && h.snapshots[n-1].Hash == hash {
+		return nil
+	}
+
+	h.revision++
+	h.snapshots = append(h.snapshots, &ConfigSnapshot{
+		Revision: h.revision,
+		Hash:     hash,
+		Time:     time.Now(),
+		Values:   values,
+		Sources:  h.rawSources(),
+	})
+	if len(h.snapshots) > h.size {
+		h.snapshots = h.snapshots[len(h.snapshots)-h.size:]
+	}
+	return nil
+}
+
+// markSecret 标记解密过的配置路径
+func (h *ConfigHistory) markSecret(path string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.secrets[path] = struct{}{}
+}
+
+// Snapshots 返回保留的全部版本，按版本号升序
+func (h *ConfigHistory) Snapshots() []*ConfigSnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]*ConfigSnapshot(nil), h.snapshots...)
+}
+
+// Current 返回当前版本，尚未加载时返回nil
+func (h *ConfigHistory) Current() *ConfigSnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.snapshots) == 0 {
+		return nil
+	}
+	return h.snapshots[len(h.snapshots)-1]
+}
+
+// Get 按版本号获取快照
+func (h *ConfigHistory) Get(revision int) (*ConfigSnapshot, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, s := range h.snapshots {
+		if s.Revision == revision {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// OnRollback 注册回滚钩子，按注册顺序执行，如将 target.Sources 中的原始内容重新发布到Nacos
+// target.Values 中的密钥为摘要、占位符已展开，不能用于重新发布
+func (h *ConfigHistory) OnRollback(hook RollbackHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, hook)
+}
+
+// Rollback 回滚到指定版本，依次执行回滚钩子
+func (h *ConfigHistory) Rollback(revision int) error {
+	target, ok := h.Get(revision)
+	if !ok {
+		return fmt.Errorf("配置版本不存在: %d", revision)
+	}
+
+	h.mu.RLock()
+	hooks := append([]RollbackHook(nil), h.hooks...)
+	h.mu.RUnlock()
+	if len(hooks) == 0 {
+		return fmt.Errorf("未注册回滚钩子")
+	}
+
+	for _, hook := range hooks {
+		if err := hook(target); err != nil {
+			return fmt.Errorf("回滚到版本%d失败: %w", revision, err)
+		}
+	}
+	return nil
+}
+
+// Diff 比较两个版本，返回按键排序的变化列表，prev为nil时全部视为新增
+func Diff(prev, cur *ConfigSnapshot) []ConfigChange {
+	var oldFlat, newFlat map[string]interface{}
+	if prev != nil {
+		oldFlat = flattenConfig(prev.Values)
+	}
+	if cur != nil {
+		newFlat = flattenConfig(cur.Values)
+	}
+
+	var changes []ConfigChange
+	for k, nv := range newFlat {
+		if ov, ok := oldFlat[k]; !ok || !reflect.DeepEqual(ov, nv) {
+			changes = append(changes, ConfigChange{Key: k, Old: ov, New: nv})
+		}
+	}
+	for k, ov := range oldFlat {
+		if _, ok := newFlat[k]; !ok {
+			changes = append(changes, ConfigChange{Key: k, Old: ov})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// maskSecrets 将解密过的值替换为摘要，值变化时摘要随之变化，Diff仍可发现密钥轮换
+func maskSecrets(v interface{}, prefix string, secrets map[string]struct{}) {
+	if len(secrets) == 0 {
+		return
+	}
+	mask := func(path string, sub interface{}) (interface{}, bool) {
+		s, ok := sub.(string)
+		if !ok {
+			return nil, false
+		}
+		if _, secret := secrets[path]; !secret {
+			return nil, false
+		}
+		sum := sha256.Sum256([]byte(s))
+		return "******(" + hex.EncodeToString(sum[:4]) + ")", true
+	}
+
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range vt {
+			path := joinPath(prefix, k)
+			if m, ok := mask(path, sub); ok {
+				vt[k] = m
+				continue
+			}
+			maskSecrets(sub, path, secrets)
+		}
+	case []interface{}:
+		for i, sub := range vt {
+			path := joinPath(prefix, strconv.Itoa(i))
+			if m, ok := mask(path, sub); ok {
+				vt[i] = m
+				continue
+			}
+			maskSecrets(sub, path, secrets)
+		}
+	}
+}
+
+// flattenConfig 将嵌套配置展开为点分路径，数组作为整体比较
+func flattenConfig(values map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	var walk func(prefix string, v map[string]interface{})
+	walk = func(prefix string, v map[string]interface{}) {
+		for k, sub := range v {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			if m, ok := sub.(map[string]interface{}); ok && len(m) > 0 {
+				walk(key, m)
+				continue
+			}
+			out[key] = sub
+		}
+	}
+	walk("", values)
+	return out
+}
+
+// historySource 记录原始内容的配置源
+type historySource struct {
+	config.Source
+	history *ConfigHistory
+	idx     int
+}
+
+// Load 加载并记录原始内容
+func (s *historySource) Load() ([]*config.KeyValue, error) {
+	kvs, err := s.Source.Load()
+	if err == nil {
+		s.history.record(s.idx, kvs)
+	}
+	return kvs, err
+}
+
+// Watch 监听并记录变化后的原始内容
+func (s *historySource) Watch() (config.Watcher, error) {
+	w, err := s.Source.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return &historyWatcher{Watcher: w, source: s}, nil
+}
+
+type historyWatcher struct {
+	config.Watcher
+	source *historySource
+}
+
+// Next 返回变化的键值并记录
+func (w *historyWatcher) Next() ([]*config.KeyValue, error) {
+	kvs, err := w.Watcher.Next()
+	if err == nil {
+		w.source.history.record(w.source.idx, kvs)
+	}
+	return kvs, err
+}
@@ -0,0 +1,319 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// SourceClass 配置源类别，用于声明优先级
+type SourceClass string
+
+const (
+	SourceRemote SourceClass = "remote" // Nacos、Consul等远程配置中心及其他追加的配置源
+	SourceFile   SourceClass = "file"   // 本地配置文件
+	SourceEnv    SourceClass = "env"    // 环境变量
+)
+
+// MergeStrategy 多个配置源定义同一配置键时的合并策略
+type MergeStrategy string
+
+const (
+	MergeOverride       MergeStrategy = "override" // 优先级高的覆盖低的，map逐层合并，列表整体替换
+	MergeAppendLists    MergeStrategy = "append"   // 同 override，但列表按优先级顺序拼接并去重
+	MergeFailOnConflict MergeStrategy = "fail"     // 同一配置键在不同位置取值不同时报错
+)
+
+// ConfigMergePolicy 配置源优先级和合并策略，对应引导配置中的 config_merge 节点
+// 未设置时保持原有行为：按配置源追加顺序加载，本地配置文件最后加载；热更新时最后变化的配置源生效
+type ConfigMergePolicy struct {
+	Precedence []SourceClass `yaml:"precedence" json:"precedence"` // 优先级从低到高，默认 remote、file、env
+	Strategy   MergeStrategy `yaml:"strategy" json:"strategy"`     // 默认 override
+}
+
+// DefaultPrecedence 默认优先级(从低到高)
+var DefaultPrecedence = []SourceClass{SourceRemote, SourceFile, SourceEnv}
+
+// ClassifiedSource 带类别的配置源
+type ClassifiedSource struct {
+	Class  SourceClass
+	Source config.Source
+}
+
+// validate 检查策略和优先级配置
+func (p ConfigMergePolicy) validate() error {
+	switch p.Strategy {
+	case "", MergeOverride, MergeAppendLists, MergeFailOnConflict:
+	default:
+		return fmt.Errorf("不支持的配置合并策略: %s", p.Strategy)
+	}
+	for _, c := range p.Precedence {
+		switch c {
+		case SourceRemote, SourceFile, SourceEnv:
+		default:
+			return fmt.Errorf("不支持的配置源类别: %s", c)
+		}
+	}
+	return nil
+}
+
+// rank 配置源类别的优先级，未列出的类别优先级最低
+func (p ConfigMergePolicy) rank(c SourceClass) int {
+	precedence := p.Precedence
+	if len(precedence) == 0 {
+		precedence = DefaultPrecedence
+	}
+	for i, pc := range precedence {
+		if pc == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// classifySource 推断配置源类别，环境变量之外的配置源均视为远程配置源
+func classifySource(src config.Source) SourceClass {
+	if _, ok := src.(*envSource); ok {
+		return SourceEnv
+	}
+	return SourceRemote
+}
+
+var _ config.Source = (*mergedSource)(nil)
+
+// mergedSource 按优先级和合并策略合并多个配置源，输出一份完整配置
+// 每个配置源保留最新的键值，任一配置源变化时按同样的顺序重新合并，结果与变化顺序无关
+type mergedSource struct {
+	policy  ConfigMergePolicy
+	sources []config.Source
+
+	mu  sync.Mutex
+	kvs [][]*config.KeyValue
+}
+
+// NewMergedSource 创建合并配置源，sources按policy中的类别优先级排序，同类别保持原顺序
+func NewMergedSource(policy ConfigMergePolicy, sources ...ClassifiedSource) (config.Source, error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+
+	ordered := make([]ClassifiedSource, len(sources))
+	copy(ordered, sources)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return policy.rank(ordered[i].Class) < policy.rank(ordered[j].Class)
+	})
+
+	m := &mergedSource{policy: policy, kvs: make([][]*config.KeyValue, len(ordered))}
+	for _, s := range ordered {
+		m.sources = append(m.sources, s.Source)
+	}
+	return m, nil
+}
+
+// Load 加载全部配置源并合并
+func (m *mergedSource) Load() ([]*config.KeyValue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, s := range m.sources {
+		kvs, err := s.Load()
+		if err != nil {
+			return nil, err
+		}
+		m.kvs[i] = kvs
+	}
+	return m.merge()
+}
+
+// Watch 监听全部配置源，任一变化时返回重新合并的配置
+func (m *mergedSource) Watch() (config.Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &mergedWatcher{ctx: ctx, cancel: cancel, ch: make(chan mergedResult, 1)}
+
+	for i, s := range m.sources {
+		sw, err := s.Watch()
+		if err != nil {
+			_ = w.Stop()
+			return nil, err
+		}
+		w.watchers = append(w.watchers, sw)
+		go m.watch(ctx, i, sw, w.ch)
+	}
+	return w, nil
+}
+
+// watch 转发单个配置源的变化
+func (m *mergedSource) watch(ctx context.Context, i int, sw config.Watcher, ch chan<- mergedResult) {
+	for {
+		kvs, err := sw.Next()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			select {
+			case ch <- mergedResult{err: err}:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		m.mu.Lock()
+		m.kvs[i] = replaceKeyValues(m.kvs[i], kvs)
+		merged, err := m.merge()
+		m.mu.Unlock()
+
+		select {
+		case ch <- mergedResult{kvs: merged, err: err}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// merge 按顺序合并各配置源的键值，调用方需持有锁
+func (m *mergedSource) merge() ([]*config.KeyValue, error) {
+	merged := make(map[string]interface{})
+	for _, kvs := range m.kvs {
+		for _, kv := range kvs {
+			next := make(map[string]interface{})
+			if err := DecodeConfig(kv, next); err != nil {
+				return nil, fmt.Errorf("解码配置失败(%s): %w", kv.Key, err)
+			}
+			if err := mergeWithStrategy(merged, next, m.policy.Strategy, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	return []*config.KeyValue{{Key: "merged", Value: b, Format: "json"}}, nil
+}
+
+// replaceKeyValues 用变化的键值替换同名键值
+func replaceKeyValues(old, changed []*config.KeyValue) []*config.KeyValue {
+	out := make([]*config.KeyValue, len(old))
+	copy(out, old)
+	for _, kv := range changed {
+		replaced := false
+		for i := range out {
+			if out[i].Key == kv.Key {
+				out[i] = kv
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// mergeWithStrategy 按策略将src合并到dst，path为当前配置键路径
+func mergeWithStrategy(dst, src map[string]interface{}, strategy MergeStrategy, path string) error {
+	for k, sv := range src {
+		key := k
+		if path != "" {
+			key = path + "." + k
+		}
+
+		dv, exists := dst[k]
+		if !exists {
+			dst[k] = sv
+			continue
+		}
+
+		dm, dIsMap := dv.(map[string]interface{})
+		sm, sIsMap := sv.(map[string]interface{})
+		if dIsMap && sIsMap {
+			if err := mergeWithStrategy(dm, sm, strategy, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch strategy {
+		case MergeFailOnConflict:
+			if !reflect.DeepEqual(dv, sv) {
+				return fmt.Errorf("配置键%s在多个配置源中取值冲突", key)
+			}
+		case MergeAppendLists:
+			dl, dIsList := dv.([]interface{})
+			sl, sIsList := sv.([]interface{})
+			if dIsList && sIsList {
+				dst[k] = appendUnique(dl, sl)
+				continue
+			}
+			dst[k] = sv
+		default:
+			dst[k] = sv
+		}
+	}
+	return nil
+}
+
+// appendUnique 拼接列表，跳过已存在的元素
+func appendUnique(dst, src []interface{}) []interface{} {
+	out := append([]interface{}{}, dst...)
+	for _, v := range src {
+		found := false
+		for _, e := range out {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+type mergedResult struct {
+	kvs []*config.KeyValue
+	err error
+}
+
+// mergedWatcher 汇总各配置源监听结果的监听器
+type mergedWatcher struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	ch       chan mergedResult
+	watchers []config.Watcher
+	stopOnce sync.Once
+}
+
+// Next 返回下一次合并结果
+func (w *mergedWatcher) Next() ([]*config.KeyValue, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case r := <-w.ch:
+		return r.kvs, r.err
+	}
+}
+
+// Stop 停止全部配置源的监听
+func (w *mergedWatcher) Stop() error {
+	var errs []error
+	w.stopOnce.Do(func() {
+		w.cancel()
+		for _, sw := range w.watchers {
+			if err := sw.Stop(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,168 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// tagValidator 结构体标签校验器(配置和请求共用)，错误信息使用json标签中的字段名
+var tagValidator = func() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	return v
+}()
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Scan 将合并后的配置解码到结构体指针v
+// 解码前为零值字段应用 default 标签，解码后执行 validate 标签中的规则，返回汇总后的全部错误
+func (r *appResult) Scan(v interface{}) error {
+	return bindConfig(v, r.Cfg.Scan)
+}
+
+// ScanKey 将指定配置键解码到结构体指针v，规则同Scan
+func (r *appResult) ScanKey(key string, v interface{}) error {
+	return bindConfig(v, func(v interface{}) error {
+		if err := r.Cfg.Value(key).Scan(v); err != nil {
+			return fmt.Errorf("解码配置失败(%s): %w", key, err)
+		}
+		return nil
+	})
+}
+
+// bindConfig 应用默认值、解码并校验
+func bindConfig(v interface{}, scan func(interface{}) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("配置目标必须是非空的结构体指针")
+	}
+
+	if err := applyDefaults(rv.Elem()); err != nil {
+		return err
+	}
+	if err := scan(v); err != nil {
+		return err
+	}
+	return ValidateConfig(v)
+}
+
+// ValidateConfig 按 validate 标签校验结构体，返回汇总后的全部错误
+func ValidateConfig(v interface{}) error {
+	err := tagValidator.Struct(v)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	errs := make([]error, 0, len(verrs))
+	for _, fe := range verrs {
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		// 去掉命名空间开头的结构体名
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		errs = append(errs, fmt.Errorf("配置项%s校验失败: %s", field, rule))
+	}
+	return errors.Join(errs...)
+}
+
+// applyDefaults 为零值字段设置 default 标签中的默认值
+func applyDefaults(rv reflect.Value) error {
+	var errs []error
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		fv := rv.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		if def, ok := sf.Tag.Lookup("default"); ok && fv.IsZero() {
+			if err := setDefault(fv, def); err != nil {
+				errs = append(errs, fmt.Errorf("字段%s的默认值无效(%s): %w", sf.Name, def, err))
+			}
+			continue
+		}
+
+		switch {
+		case fv.Kind() == reflect.Struct:
+			if err := applyDefaults(fv); err != nil {
+				errs = append(errs, err)
+			}
+		case fv.Kind() == reflect.Pointer && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct:
+			if err := applyDefaults(fv.Elem()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setDefault 将字符串形式的默认值写入字段，切片以逗号分隔
+func setDefault(fv reflect.Value, def string) error {
+	if fv.Type() == durationType || fv.Type() == reflect.TypeOf(Duration(0)) {
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(def)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(def, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(def, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(def, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		parts := strings.Split(def, ",")
+		s := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := setDefault(s.Index(i), strings.TrimSpace(p)); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+	default:
+		return fmt.Errorf("不支持的类型: %s", fv.Type())
+	}
+	return nil
+}
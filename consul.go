@@ -0,0 +1,90 @@
+package common
+
+import (
+	consulconfig "github.com/go-kratos/kratos/contrib/config/consul/v2"
+	consulregistry "github.com/go-kratos/kratos/contrib/registry/consul/v2"
+	kconfig "github.com/go-kratos/kratos/v2/config"
+	"github.com/hashicorp/consul/api"
+)
+
+// ConsulCfgSource Consul KV配置中心
+type ConsulCfgSource struct {
+	address    string
+	scheme     string
+	token      string
+	datacenter string
+}
+
+// NewConsulCfgSource 创建Consul KV配置中心，address形如 127.0.0.1:8500
+func NewConsulCfgSource(address string) *ConsulCfgSource {
+	return &ConsulCfgSource{address: address}
+}
+
+// WithScheme 设置访问协议(http或https)
+func (c *ConsulCfgSource) WithScheme(scheme string) *ConsulCfgSource {
+	c.scheme = scheme
+	return c
+}
+
+// WithToken 设置ACL令牌
+func (c *ConsulCfgSource) WithToken(token string) *ConsulCfgSource {
+	c.token = token
+	return c
+}
+
+// WithDatacenter 设置数据中心
+func (c *ConsulCfgSource) WithDatacenter(dc string) *ConsulCfgSource {
+	c.datacenter = dc
+	return c
+}
+
+// ConsulSource 创建监听指定前缀的配置源
+// 前缀下每个键作为一个配置项，按键名扩展名(.yaml/.json/.toml)解码，如 gbm/dispatch/app.yaml
+func (c *ConsulCfgSource) ConsulSource(prefix string) (kconfig.Source, error) {
+	sources, err := c.ConsulSources(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return sources[0], nil
+}
+
+// ConsulSources 为多个前缀创建配置源，共用同一个客户端，后面的前缀覆盖前面的同名键
+func (c *ConsulCfgSource) ConsulSources(prefixes ...string) ([]kconfig.Source, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]kconfig.Source, 0, len(prefixes))
+	for _, p := range prefixes {
+		s, err := consulconfig.New(client, consulconfig.WithPath(p))
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, s)
+	}
+	return sources, nil
+}
+
+// ConsulRegistry 创建Consul注册中心，可作为 MultiRegistrar 的后端同时注册
+func (c *ConsulCfgSource) ConsulRegistry(opts ...consulregistry.Option) (*consulregistry.Registry, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	return consulregistry.New(client, opts...), nil
+}
+
+// client 创建Consul客户端
+func (c *ConsulCfgSource) client() (*api.Client, error) {
+	cfg := api.DefaultConfig()
+	if c.address != "" {
+		cfg.Address = c.address
+	}
+	if c.scheme != "" {
+		cfg.Scheme = c.scheme
+	}
+	cfg.Token = c.token
+	cfg.Datacenter = c.datacenter
+	return api.NewClient(cfg)
+}
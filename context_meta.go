@@ -0,0 +1,104 @@
+package common
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// 跨服务透传的上下文元数据头，使用kratos全局元数据前缀，未经本包中间件的服务也会继续透传
+const (
+	TenantIDHeader    = "x-md-global-tenant-id"
+	OperatorIDHeader  = "x-md-global-operator-id"
+	WarehouseIDHeader = "x-md-global-warehouse-id"
+)
+
+type (
+	tenantIDKey    struct{}
+	operatorIDKey  struct{}
+	warehouseIDKey struct{}
+)
+
+// contextMetadata 透传的上下文键与请求头
+var contextMetadata = []struct {
+	key    interface{}
+	header string
+}{
+	{tenantIDKey{}, TenantIDHeader},
+	{operatorIDKey{}, OperatorIDHeader},
+	{warehouseIDKey{}, WarehouseIDHeader},
+}
+
+// ContextMetadata 服务端上下文元数据中间件，从请求头读取租户、操作人和仓库ID写入上下文
+func ContextMetadata() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				for _, md := range contextMetadata {
+					if v := tr.RequestHeader().Get(md.header); v != "" {
+						ctx = context.WithValue(ctx, md.key, v)
+					}
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// ContextMetadataClient 客户端上下文元数据中间件，将上下文中的租户、操作人和仓库ID透传给下游
+func ContextMetadataClient() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				for _, md := range contextMetadata {
+					if v, _ := ctx.Value(md.key).(string); v != "" {
+						tr.RequestHeader().Set(md.header, v)
+					}
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// WithTenantID 将租户ID写入上下文
+func WithTenantID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, id)
+}
+
+// TenantIDFromContext 获取上下文中的租户ID，不存在时返回空字符串
+func TenantIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(tenantIDKey{}).(string)
+	return id
+}
+
+// WithOperatorID 将操作人ID写入上下文
+func WithOperatorID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, operatorIDKey{}, id)
+}
+
+// OperatorIDFromContext 获取上下文中的操作人ID，不存在时返回空字符串
+func OperatorIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(operatorIDKey{}).(string)
+	return id
+}
+
+// WithWarehouseID 将仓库ID写入上下文
+func WithWarehouseID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, warehouseIDKey{}, id)
+}
+
+// WarehouseIDFromContext 获取上下文中的仓库ID，不存在时返回空字符串
+func WarehouseIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(warehouseIDKey{}).(string)
+	return id
+}
+
+// TenantIDValuer 日志字段，输出上下文中的租户ID
+func TenantIDValuer() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		return TenantIDFromContext(ctx)
+	}
+}
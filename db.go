@@ -0,0 +1,174 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const (
+	// 数据库配置键
+	DBConfigKey = "database"
+
+	// MySQL驱动
+	DBDriverMySQL = "mysql"
+
+	// PostgreSQL驱动
+	DBDriverPostgres = "postgres"
+
+	// 默认慢查询阈值
+	DefaultDBSlowThreshold = 200 * time.Millisecond
+)
+
+// DBConfig 数据库配置，对应配置中的 database 节点
+type DBConfig struct {
+	Name            string   `json:"name"`   // 连接名，用于指标和链路标签，默认default
+	Driver          string   `json:"driver"` // mysql或postgres，默认mysql
+	DSN             string   `json:"dsn"`
+	MaxOpenConns    int      `json:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time"`
+	SlowThreshold   Duration `json:"slow_threshold"` // 慢查询阈值，默认200ms
+	LogLevel        string   `json:"log_level"`      // silent、error、warn、info，默认warn
+}
+
+// NewDB 创建配置好连接池、日志、链路追踪和连接指标的 *gorm.DB
+// logger为nil时使用kratos默认日志，metrics为nil时不记录指标；链路使用全局TracerProvider
+func NewDB(cfg DBConfig, logger log.Logger, metrics *Metrics) (*gorm.DB, error) {
+	if cfg.DSN == "" {
+		return nil, errors.New("数据库DSN不能为空")
+	}
+	if cfg.Name == "" {
+		cfg.Name = "default"
+	}
+	if logger == nil {
+		logger = log.GetLogger()
+	}
+
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case "", DBDriverMySQL:
+		dialector = mysql.Open(cfg.DSN)
+	case DBDriverPostgres:
+		dialector = postgres.Open(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	}
+
+	slow := cfg.SlowThreshold.Std()
+	if slow <= 0 {
+		slow = DefaultDBSlowThreshold
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: newGormLogger(logger, slow, parseGormLevel(cfg.LogLevel)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime.Std())
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime.Std())
+	}
+
+	if err := db.Use(&gormPlugin{name: cfg.Name, system: db.Dialector.Name(), metrics: metrics}); err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// gormLogger 将gorm日志输出到kratos日志
+type gormLogger struct {
+	logger log.Logger
+	slow   time.Duration
+	level  gormlogger.LogLevel
+}
+
+// newGormLogger 创建gorm日志适配器
+func newGormLogger(logger log.Logger, slow time.Duration, level gormlogger.LogLevel) *gormLogger {
+	return &gormLogger{logger: logger, slow: slow, level: level}
+}
+
+// LogMode 设置日志级别
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	n := *l
+	n.level = level
+	return &n
+}
+
+// Info 输出INFO日志
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		log.WithContext(ctx, l.logger).Log(log.LevelInfo, log.DefaultMessageKey, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn 输出WARN日志
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		log.WithContext(ctx, l.logger).Log(log.LevelWarn, log.DefaultMessageKey, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error 输出ERROR日志
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		log.WithContext(ctx, l.logger).Log(log.LevelError, log.DefaultMessageKey, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace 输出SQL日志：失败的SQL以ERROR输出，慢查询以WARN输出，info级别时输出全部SQL
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	logger := log.WithContext(ctx, l.logger)
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gormlogger.ErrRecordNotFound):
+		sql, rows := fc()
+		_ = logger.Log(log.LevelError, "kind", "sql", "sql", sql, "rows", rows, "latency", elapsed.Seconds(), "error", err.Error())
+	case l.slow > 0 && elapsed > l.slow && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		_ = logger.Log(log.LevelWarn, "kind", "sql", "sql", sql, "rows", rows, "latency", elapsed.Seconds(), "slow", true)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		_ = logger.Log(log.LevelInfo, "kind", "sql", "sql", sql, "rows", rows, "latency", elapsed.Seconds())
+	}
+}
+
+// parseGormLevel 解析gorm日志级别，默认warn
+func parseGormLevel(level string) gormlogger.LogLevel {
+	switch level {
+	case "silent":
+		return gormlogger.Silent
+	case "error":
+		return gormlogger.Error
+	case "info":
+		return gormlogger.Info
+	default:
+		return gormlogger.Warn
+	}
+}
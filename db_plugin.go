@@ -0,0 +1,157 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	gormSpanKey  = "gbm:span"
+	gormStartKey = "gbm:start"
+)
+
+// gormPlugin 为gorm增加链路追踪、查询耗时和连接池指标
+type gormPlugin struct {
+	name    string
+	system  string
+	metrics *Metrics
+	seconds *Histogram
+}
+
+// Name 插件名
+func (p *gormPlugin) Name() string {
+	return "gbm:observability"
+}
+
+// Initialize 注册回调和连接池指标
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	if p.metrics != nil {
+		var err error
+		p.seconds, err = p.metrics.Histogram("db_query_seconds", "数据库操作耗时",
+			[]float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}, "db", "operation", "table")
+		if err != nil {
+			return err
+		}
+		if err := p.registerPoolMetrics(db); err != nil {
+			return err
+		}
+	}
+
+	cb := db.Callback()
+	hooks := []struct {
+		op     string
+		before func(string, func(*gorm.DB)) error
+		after  func(string, func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		if err := h.before("gbm:before_"+h.op, p.before(h.op)); err != nil {
+			return err
+		}
+		if err := h.after("gbm:after_"+h.op, p.after(h.op)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before 开始span并记录开始时间
+func (p *gormPlugin) before(op string) func(*gorm.DB) {
+	tracer := otel.Tracer("gorm")
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, span := tracer.Start(ctx, "gorm."+op, trace.WithSpanKind(trace.SpanKindClient))
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+		db.InstanceSet(gormStartKey, time.Now())
+	}
+}
+
+// after 结束span并记录耗时
+func (p *gormPlugin) after(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if v, ok := db.InstanceGet(gormStartKey); ok && p.seconds != nil {
+			p.seconds.Observe(db.Statement.Context, time.Since(v.(time.Time)).Seconds(), p.name, op, db.Statement.Table)
+		}
+
+		v, ok := db.InstanceGet(gormSpanKey)
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("db.system", p.system),
+			attribute.String("db.name", p.name),
+			attribute.String("db.operation", op),
+			attribute.String("db.sql.table", db.Statement.Table),
+			attribute.String("db.statement", db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+		if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+}
+
+// registerPoolMetrics 注册连接池指标
+func (p *gormPlugin) registerPoolMetrics(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	meter := p.metrics.meter
+	open, err := meter.Int64ObservableGauge("db_connections_open", metric.WithDescription("已建立的连接数"))
+	if err != nil {
+		return err
+	}
+	inUse, err := meter.Int64ObservableGauge("db_connections_in_use", metric.WithDescription("使用中的连接数"))
+	if err != nil {
+		return err
+	}
+	idle, err := meter.Int64ObservableGauge("db_connections_idle", metric.WithDescription("空闲连接数"))
+	if err != nil {
+		return err
+	}
+	waitCount, err := meter.Int64ObservableCounter("db_connections_wait_total", metric.WithDescription("等待连接的累计次数"))
+	if err != nil {
+		return err
+	}
+	waitSeconds, err := meter.Float64ObservableCounter("db_connections_wait_seconds_total",
+		metric.WithDescription("等待连接的累计时间"), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+
+	attrs := metric.WithAttributes(attribute.String("db", p.name))
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := sqlDB.Stats()
+		o.ObserveInt64(open, int64(s.OpenConnections), attrs)
+		o.ObserveInt64(inUse, int64(s.InUse), attrs)
+		o.ObserveInt64(idle, int64(s.Idle), attrs)
+		o.ObserveInt64(waitCount, s.WaitCount, attrs)
+		o.ObserveFloat64(waitSeconds, s.WaitDuration.Seconds(), attrs)
+		return nil
+	}, open, inUse, idle, waitCount, waitSeconds)
+	return err
+}
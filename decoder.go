@@ -0,0 +1,63 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-kratos/kratos/v2/config"
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeConfig 按格式解码配置源的键值
+// 优先使用KeyValue.Format，其次使用键名的扩展名，都无法识别时按内容推断：
+// 以{开头按JSON解码，否则依次尝试YAML和TOML
+func DecodeConfig(kv *config.KeyValue, v map[string]interface{}) error {
+	switch format := configFormat(kv); format {
+	case EnvFormat:
+		v[kv.Key] = string(kv.Value)
+		return nil
+	case "yaml", "yml":
+		return yaml.Unmarshal(kv.Value, v)
+	case "json":
+		return json.Unmarshal(kv.Value, &v)
+	case "toml":
+		return toml.Unmarshal(kv.Value, &v)
+	default:
+		return sniffDecode(kv, v)
+	}
+}
+
+// configFormat 识别键值的格式
+func configFormat(kv *config.KeyValue) string {
+	if f := strings.ToLower(kv.Format); f != "" && f != "text" {
+		return f
+	}
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(kv.Key), "."))
+}
+
+// sniffDecode 按内容推断格式并解码
+func sniffDecode(kv *config.KeyValue, v map[string]interface{}) error {
+	content := bytes.TrimSpace(kv.Value)
+	if len(content) == 0 {
+		return nil
+	}
+	if content[0] == '{' {
+		return json.Unmarshal(content, &v)
+	}
+
+	yamlErr := yaml.Unmarshal(content, v)
+	if yamlErr == nil {
+		return nil
+	}
+	for k := range v {
+		delete(v, k)
+	}
+	if err := toml.Unmarshal(content, &v); err != nil {
+		return fmt.Errorf("无法识别配置格式(%s): yaml: %v, toml: %v", kv.Key, yamlErr, err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// 开启单次请求转储的请求头，取值true/1
+	DumpHeader = "X-Debug-Dump"
+
+	// 默认单个消息体的最大记录字节数
+	DefaultDumpMaxBody = 64 << 10
+
+	// 脱敏后的占位值
+	redactedValue = "******"
+)
+
+// DefaultDumpRedact 默认脱敏的字段名和请求头(不区分大小写)
+var DefaultDumpRedact = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"authorization", "cookie", "set-cookie", "x-api-key",
+}
+
+// DumpOption 请求转储选项
+type DumpOption func(*dumpOptions)
+
+type dumpOptions struct {
+	routes  []string
+	header  string
+	maxBody int
+	redact  map[string]struct{}
+}
+
+// WithDumpRoutes 设置始终转储的路由，匹配operation或HTTP路径，以*结尾表示前缀匹配
+func WithDumpRoutes(routes ...string) DumpOption {
+	return func(o *dumpOptions) { o.routes = append(o.routes, routes...) }
+}
+
+// WithDumpHeader 设置开启单次转储的请求头，为空时不支持按请求头开启
+func WithDumpHeader(header string) DumpOption {
+	return func(o *dumpOptions) { o.header = header }
+}
+
+// WithDumpMaxBody 设置单个消息体的最大记录字节数，超出部分截断
+func WithDumpMaxBody(n int) DumpOption {
+	return func(o *dumpOptions) { o.maxBody = n }
+}
+
+// WithDumpRedact 追加需要脱敏的字段名或请求头，在 DefaultDumpRedact 基础上生效
+func WithDumpRedact(fields ...string) DumpOption {
+	return func(o *dumpOptions) {
+		for _, f := range fields {
+			o.redact[strings.ToLower(f)] = struct{}{}
+		}
+	}
+}
+
+// Dump 请求/响应转储中间件，用于排查对接问题，默认不转储任何请求
+// 路由匹配 WithDumpRoutes 或请求头 X-Debug-Dump 为true时，以结构化日志记录请求头、完整请求和响应；
+// 消息体中的敏感字段(任意层级)和敏感请求头替换为 ******
+func Dump(logger log.Logger, opts ...DumpOption) middleware.Middleware {
+	o := dumpOptions{
+		header:  DumpHeader,
+		maxBody: DefaultDumpMaxBody,
+		redact:  make(map[string]struct{}, len(DefaultDumpRedact)),
+	}
+	for _, f := range DefaultDumpRedact {
+		o.redact[f] = struct{}{}
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || !o.match(ctx, tr) {
+				return handler(ctx, req)
+			}
+
+			start := time.Now()
+			reply, err := handler(ctx, req)
+
+			method, path, remote := accessInfo(ctx)
+			kv := []interface{}{
+				"kind", "dump",
+				"operation", tr.Operation(),
+				"method", method,
+				"path", path,
+				"peer", remote,
+				"latency", time.Since(start).Seconds(),
+				"request.header", o.headers(tr.RequestHeader()),
+				"request", o.body(req),
+			}
+			if err != nil {
+				se := errors.FromError(err)
+				kv = append(kv, "code", int(se.Code), "error", err.Error())
+			} else {
+				kv = append(kv, "code", http.StatusOK, "response", o.body(reply))
+			}
+
+			_ = log.WithContext(ctx, logger).Log(log.LevelInfo, kv...)
+			return reply, err
+		}
+	}
+}
+
+// match 判断是否需要转储
+func (o *dumpOptions) match(ctx context.Context, tr transport.Transporter) bool {
+	if o.header != "" {
+		if v, err := strconv.ParseBool(tr.RequestHeader().Get(o.header)); err == nil && v {
+			return true
+		}
+	}
+	if allowed(o.routes, tr.Operation()) {
+		return true
+	}
+	_, path, _ := accessInfo(ctx)
+	return path != "" && allowed(o.routes, path)
+}
+
+// headers 脱敏后的请求头
+func (o *dumpOptions) headers(h transport.Header) map[string]string {
+	out := make(map[string]string, len(h.Keys()))
+	for _, k := range h.Keys() {
+		if _, ok := o.redact[strings.ToLower(k)]; ok {
+			out[k] = redactedValue
+			continue
+		}
+		out[k] = strings.Join(h.Values(k), ",")
+	}
+	return out
+}
+
+// body 将消息序列化为JSON并脱敏，超过maxBody时截断
+func (o *dumpOptions) body(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	var (
+		b   []byte
+		err error
+	)
+	if m, ok := v.(proto.Message); ok {
+		b, err = protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	} else {
+		b, err = json.Marshal(v)
+	}
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err == nil {
+		if redacted, err := json.Marshal(o.redactValue(data)); err == nil {
+			b = redacted
+		}
+	}
+
+	if o.maxBody > 0 {
+		return truncate(string(b), o.maxBody)
+	}
+	return string(b)
+}
+
+// redactValue 递归替换敏感字段的值
+func (o *dumpOptions) redactValue(v interface{}) interface{} {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range vt {
+			if _, ok := o.redact[strings.ToLower(k)]; ok {
+				vt[k] = redactedValue
+				continue
+			}
+			vt[k] = o.redactValue(sub)
+		}
+	case []interface{}:
+		for i, sub := range vt {
+			vt[i] = o.redactValue(sub)
+		}
+	}
+	return v
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration 支持从配置中以字符串("500ms"、"30s")或纳秒数解析的时长
+// kratos配置通过JSON解码，time.Duration只能接受纳秒数，配置中的时长字段统一使用该类型
+type Duration time.Duration
+
+// Std 转换为 time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// MarshalJSON 输出为字符串形式
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON 解析字符串或数字形式的时长
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case float64:
+		*d = Duration(time.Duration(v))
+	case string:
+		td, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = Duration(td)
+	default:
+		return fmt.Errorf("无效的时长: %s", data)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/env"
+)
+
+// 环境变量配置源的格式标识
+const EnvFormat = "env"
+
+// placeholder 匹配 ${NAME} 和 ${NAME:default}
+var placeholder = regexp.MustCompile(`\${(.*?)}`)
+
+// NewEnvSource 环境变量配置源
+// prefixes非空时只加载带前缀的变量，并去掉前缀和随后的下划线作为配置键，如 GBM_DB_DSN -> DB_DSN
+func NewEnvSource(prefixes ...string) config.Source {
+	return &envSource{Source: env.NewSource(prefixes...)}
+}
+
+// envSource 为环境变量标记格式，便于解码器按原始字符串处理
+type envSource struct {
+	config.Source
+}
+
+// Load 加载环境变量
+func (s *envSource) Load() ([]*config.KeyValue, error) {
+	kvs, err := s.Source.Load()
+	if err != nil {
+		return nil, err
+	}
+	return markEnv(kvs), nil
+}
+
+// Watch 监听环境变量变化
+func (s *envSource) Watch() (config.Watcher, error) {
+	w, err := s.Source.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return &envWatcher{Watcher: w}, nil
+}
+
+type envWatcher struct {
+	config.Watcher
+}
+
+// Next 返回变化的环境变量
+func (w *envWatcher) Next() ([]*config.KeyValue, error) {
+	kvs, err := w.Watcher.Next()
+	if err != nil {
+		return nil, err
+	}
+	return markEnv(kvs), nil
+}
+
+// markEnv 标记键值来自环境变量
+func markEnv(kvs []*config.KeyValue) []*config.KeyValue {
+	for _, kv := range kvs {
+		kv.Format = EnvFormat
+	}
+	return kvs
+}
+
+// EnvResolver 展开配置值中的 ${NAME:default} 占位符
+// 依次从环境变量、已合并的配置键(如 ${db.host})中查找NAME，都不存在时使用default
+func EnvResolver(input map[string]interface{}) error {
+	lookup := func(name string) string {
+		args := strings.SplitN(strings.TrimSpace(name), ":", 2)
+		if v, ok := os.LookupEnv(args[0]); ok {
+			return v
+		}
+		if v, ok := lookupKey(input, args[0]); ok {
+			return v
+		}
+		if len(args) > 1 {
+			return args[1]
+		}
+		return ""
+	}
+
+	var resolve func(v interface{}) interface{}
+	resolve = func(v interface{}) interface{} {
+		switch vt := v.(type) {
+		case string:
+			return placeholder.ReplaceAllStringFunc(vt, func(s string) string {
+				return lookup(s[2 : len(s)-1])
+			})
+		case map[string]interface{}:
+			for k, sub := range vt {
+				vt[k] = resolve(sub)
+			}
+		case []interface{}:
+			for i, sub := range vt {
+				vt[i] = resolve(sub)
+			}
+		}
+		return v
+	}
+	resolve(input)
+	return nil
+}
+
+// lookupKey 按点分路径查找配置中的字符串值
+func lookupKey(input map[string]interface{}, key string) (string, bool) {
+	var cur interface{} = input
+	for _, k := range strings.Split(key, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if cur, ok = m[k]; !ok {
+			return "", false
+		}
+	}
+	switch v := cur.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return "", false
+	case string:
+		return v, !placeholder.MatchString(v)
+	default:
+		return fmt.Sprint(v), true
+	}
+}
@@ -0,0 +1,205 @@
+package common
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// 特性开关配置键
+const FlagsConfigKey = "flags"
+
+// Flag 特性开关
+// Users和Percentage都为空时按Enabled整体开关；否则Enabled为true时，
+// 命中Users的用户开启，其余用户按Percentage灰度(同一用户结果稳定)
+type Flag struct {
+	Enabled    bool     `json:"enabled"`
+	Users      []string `json:"users"`      // 定向开启的用户
+	Percentage *float64 `json:"percentage"` // 灰度比例0-100，nil表示全量
+}
+
+type flagUserKey struct{}
+
+// WithFlagUser 将用户标识写入上下文，用于定向和按比例灰度
+func WithFlagUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, flagUserKey{}, user)
+}
+
+// FlagUserFromContext 获取上下文中的用户标识
+func FlagUserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(flagUserKey{}).(string)
+	return user
+}
+
+// Flags 特性开关集合，读取配置中的 flags 节点并在变化时自动更新
+type Flags struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+
+	watchMu  sync.Mutex // 保护cfg和watching
+	cfg      config.Config
+	watching bool // 已对flags节点注册监听
+}
+
+// NewFlags 从配置源创建特性开关，配置格式为:
+//
+//	flags:
+//	  new_dispatch:
+//	    enabled: true
+//	    users: [u1001]
+//	    percentage: 20
+//
+// 启动时不存在flags节点也可以创建，节点出现后开始生效并自动更新
+func NewFlags(sources ...config.Source) (*Flags, error) {
+	f := &Flags{}
+	wrapped := make([]config.Source, len(sources))
+	for i, s := range sources {
+		wrapped[i] = &flagsSource{Source: s, f: f}
+	}
+	c := config.New(
+		config.WithSource(wrapped...),
+		config.WithDecoder(DecodeConfig),
+	)
+	if err := c.Load(); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	f.watchMu.Lock()
+	f.cfg = c
+	f.watchMu.Unlock()
+	if err := f.watch(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewNacosFlags 从Nacos的独立dataId创建特性开关
+func NewNacosFlags(nfs *NacosCfgSource, namespaceid, dataid, group string) (*Flags, error) {
+	source, err := nfs.NacosSource(namespaceid, dataid, group)
+	if err != nil {
+		return nil, err
+	}
+	return NewFlags(source)
+}
+
+// IsEnabled 判断特性开关对当前上下文的用户是否开启，开关不存在时返回false
+func (f *Flags) IsEnabled(ctx context.Context, name string) bool {
+	f.mu.RLock()
+	flag, ok := f.flags[name]
+	f.mu.RUnlock()
+	if !ok || !flag.Enabled {
+		return false
+	}
+	if len(flag.Users) == 0 && flag.Percentage == nil {
+		return true
+	}
+
+	user := FlagUserFromContext(ctx)
+	if user != "" {
+		for _, u := range flag.Users {
+			if u == user {
+				return true
+			}
+		}
+	}
+	if flag.Percentage == nil {
+		return false
+	}
+	pct := *flag.Percentage
+	if pct >= 100 {
+		return true
+	}
+	if pct <= 0 || user == "" {
+		return false
+	}
+	return rolloutBucket(name, user) < pct*100
+}
+
+// Flags 返回当前全部开关
+func (f *Flags) Flags() map[string]Flag {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make(map[string]Flag, len(f.flags))
+	for k, v := range f.flags {
+		out[k] = v
+	}
+	return out
+}
+
+// Close 停止监听配置
+func (f *Flags) Close() error {
+	return f.cfg.Close()
+}
+
+// watch flags节点存在时加载并注册监听，已注册或节点不存在时不做处理
+// kratos只能监听已存在的键，启动时缺失的节点在出现后由配置源监听补注册
+func (f *Flags) watch() error {
+	f.watchMu.Lock()
+	defer f.watchMu.Unlock()
+	if f.watching || f.cfg == nil {
+		return nil
+	}
+	v := f.cfg.Value(FlagsConfigKey)
+	if v.Load() == nil {
+		return nil
+	}
+	if err := f.load(v); err != nil {
+		return err
+	}
+	err := f.cfg.Watch(FlagsConfigKey, func(_ string, v config.Value) {
+		_ = f.load(v)
+	})
+	if err != nil {
+		return err
+	}
+	f.watching = true
+	return nil
+}
+
+// flagsSource 包装配置源，使配置变化后检查flags节点是否出现
+type flagsSource struct {
+	config.Source
+	f *Flags
+}
+
+func (s *flagsSource) Watch() (config.Watcher, error) {
+	w, err := s.Source.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return &flagsWatcher{Watcher: w, f: s.f}, nil
+}
+
+type flagsWatcher struct {
+	config.Watcher
+	f *Flags
+}
+
+// Next kratos在两次Next之间合并上一次的变更，进入时即可读到最新配置
+func (w *flagsWatcher) Next() ([]*config.KeyValue, error) {
+	_ = w.f.watch()
+	return w.Watcher.Next()
+}
+
+// load 解码并替换开关集合
+func (f *Flags) load(v config.Value) error {
+	var flags map[string]Flag
+	if err := v.Scan(&flags); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.flags = flags
+	f.mu.Unlock()
+	return nil
+}
+
+// rolloutBucket 将 开关名+用户 稳定映射到 [0, 10000)
+func rolloutBucket(name, user string) float64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + user))
+	return float64(h.Sum32() % 10000)
+}
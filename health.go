@@ -0,0 +1,195 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	// 健康状态
+	HealthStatusUp   = "UP"
+	HealthStatusDown = "DOWN"
+
+	// 默认健康检查路径
+	DefaultHealthPath    = "/healthz"
+	DefaultLivenessPath  = "/livez"
+	DefaultReadinessPath = "/readyz"
+
+	// 默认单项检查超时时间
+	DefaultHealthCheckTimeout = 3 * time.Second
+)
+
+// HealthCheck 健康检查函数，返回nil表示健康
+type HealthCheck func(ctx context.Context) error
+
+// HealthStatus 聚合后的健康状态
+type HealthStatus struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult 单项检查结果
+type HealthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Health 健康检查子系统
+// - liveness: 存活检查，失败表示进程需要重启
+// - readiness: 就绪检查，失败表示暂时不能接收流量
+type Health struct {
+	mu        sync.RWMutex
+	checks    map[string]HealthCheck
+	readiness map[string]HealthCheck
+	timeout   time.Duration
+}
+
+// NewHealth 创建健康检查子系统
+func NewHealth() *Health {
+	return &Health{
+		checks:    make(map[string]HealthCheck),
+		readiness: make(map[string]HealthCheck),
+		timeout:   DefaultHealthCheckTimeout,
+	}
+}
+
+// WithTimeout 设置单项检查超时时间
+func (h *Health) WithTimeout(d time.Duration) *Health {
+	h.timeout = d
+	return h
+}
+
+// RegisterCheck 注册存活检查项，同名检查会被覆盖
+func (h *Health) RegisterCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// RegisterReadiness 注册就绪检查项，同名检查会被覆盖
+// 就绪检查全部通过后才会注册到注册中心
+func (h *Health) RegisterReadiness(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness[name] = check
+}
+
+// Check 并发执行所有检查项(存活+就绪)并聚合结果
+func (h *Health) Check(ctx context.Context) HealthStatus {
+	h.mu.RLock()
+	checks := make(map[string]HealthCheck, len(h.checks)+len(h.readiness))
+	for name, c := range h.checks {
+		checks[name] = c
+	}
+	for name, c := range h.readiness {
+		checks[name] = c
+	}
+	h.mu.RUnlock()
+
+	return runHealthChecks(ctx, checks, h.timeout)
+}
+
+// Live 执行存活检查
+func (h *Health) Live(ctx context.Context) HealthStatus {
+	h.mu.RLock()
+	checks := make(map[string]HealthCheck, len(h.checks))
+	for name, c := range h.checks {
+		checks[name] = c
+	}
+	h.mu.RUnlock()
+
+	return runHealthChecks(ctx, checks, h.timeout)
+}
+
+// Ready 执行就绪检查
+func (h *Health) Ready(ctx context.Context) HealthStatus {
+	h.mu.RLock()
+	checks := make(map[string]HealthCheck, len(h.readiness))
+	for name, c := range h.readiness {
+		checks[name] = c
+	}
+	h.mu.RUnlock()
+
+	return runHealthChecks(ctx, checks, h.timeout)
+}
+
+// Handler 返回健康检查HTTP处理器，健康时返回200，否则返回503
+func (h *Health) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthStatus(w, h.Check(r.Context()))
+	})
+}
+
+// LivenessHandler 返回存活探针HTTP处理器
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthStatus(w, h.Live(r.Context()))
+	})
+}
+
+// ReadinessHandler 返回就绪探针HTTP处理器
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthStatus(w, h.Ready(r.Context()))
+	})
+}
+
+// Register 将健康检查挂载到kratos HTTP服务器的 /healthz、/livez、/readyz
+func (h *Health) Register(srv *khttp.Server) {
+	srv.Handle(DefaultHealthPath, h.Handler())
+	srv.Handle(DefaultLivenessPath, h.LivenessHandler())
+	srv.Handle(DefaultReadinessPath, h.ReadinessHandler())
+}
+
+// runHealthChecks 并发执行检查项
+func runHealthChecks(ctx context.Context, checks map[string]HealthCheck, timeout time.Duration) HealthStatus {
+	status := HealthStatus{
+		Status: HealthStatusUp,
+		Checks: make(map[string]HealthCheckResult, len(checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+
+			cctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result := HealthCheckResult{Status: HealthStatusUp}
+			if err := check(cctx); err != nil {
+				result = HealthCheckResult{Status: HealthStatusDown, Error: err.Error()}
+			}
+
+			mu.Lock()
+			status.Checks[name] = result
+			if result.Status != HealthStatusUp {
+				status.Status = HealthStatusDown
+			}
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	return status
+}
+
+// writeHealthStatus 输出健康状态JSON
+func writeHealthStatus(w http.ResponseWriter, status HealthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	if status.Status != HealthStatusUp {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,158 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const (
+	// 默认就绪检查间隔
+	DefaultReadinessInterval = 5 * time.Second
+
+	// 默认注册前等待就绪的最长时间
+	DefaultReadinessTimeout = 2 * time.Minute
+)
+
+var _ registry.Registrar = (*HealthRegistrar)(nil)
+
+// HealthRegistrar 结合就绪检查的注册器
+// - Register 阻塞到就绪检查全部通过后才真正注册，超过等待时间仍未就绪时返回错误
+// - 注册后持续检查，失败时从注册中心注销，恢复后重新注册
+type HealthRegistrar struct {
+	reg      registry.Registrar
+	health   *Health
+	logger   *log.Helper
+	interval time.Duration
+	timeout  time.Duration
+
+	mu         sync.Mutex
+	cancel     context.CancelFunc
+	done       chan struct{}
+	registered bool
+}
+
+// NewHealthRegistrar 创建结合就绪检查的注册器
+func NewHealthRegistrar(reg registry.Registrar, health *Health, logger log.Logger) *HealthRegistrar {
+	return &HealthRegistrar{
+		reg:      reg,
+		health:   health,
+		logger:   log.NewHelper(logger),
+		interval: DefaultReadinessInterval,
+		timeout:  DefaultReadinessTimeout,
+	}
+}
+
+// WithInterval 设置就绪检查间隔
+func (r *HealthRegistrar) WithInterval(d time.Duration) *HealthRegistrar {
+	r.interval = d
+	return r
+}
+
+// WithTimeout 设置注册前等待就绪的最长时间，d<=0时一直等待到ctx结束
+func (r *HealthRegistrar) WithTimeout(d time.Duration) *HealthRegistrar {
+	r.timeout = d
+	return r
+}
+
+// Register 等待就绪后注册，并启动后台就绪监控
+func (r *HealthRegistrar) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	var deadline <-chan time.Time
+	if r.timeout > 0 {
+		timer := time.NewTimer(r.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for {
+		status := r.health.Ready(ctx)
+		if status.Status == HealthStatusUp {
+			break
+		}
+		r.logger.Warnf("就绪检查未通过，暂缓注册: %+v", status.Checks)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("等待就绪超时(%v): %+v", r.timeout, status.Checks)
+		case <-time.After(r.interval):
+		}
+	}
+
+	if err := r.reg.Register(ctx, service); err != nil {
+		return err
+	}
+
+	mctx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	r.registered = true
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	done := r.done
+	r.mu.Unlock()
+
+	go r.monitor(mctx, service, done)
+	return nil
+}
+
+// Deregister 停止就绪监控并注销
+func (r *HealthRegistrar) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.registered {
+		return nil
+	}
+	r.registered = false
+	return r.reg.Deregister(ctx, service)
+}
+
+// monitor 周期性执行就绪检查，根据结果注销或重新注册
+func (r *HealthRegistrar) monitor(ctx context.Context, service *registry.ServiceInstance, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ready := r.health.Ready(ctx).Status == HealthStatusUp
+
+		r.mu.Lock()
+		switch {
+		case !ready && r.registered:
+			if err := r.reg.Deregister(ctx, service); err != nil {
+				r.logger.Errorf("就绪检查失败后注销失败: %v", err)
+			} else {
+				r.registered = false
+				r.logger.Warn("就绪检查失败，已从注册中心注销")
+			}
+		case ready && !r.registered:
+			if err := r.reg.Register(ctx, service); err != nil {
+				r.logger.Errorf("就绪恢复后重新注册失败: %v", err)
+			} else {
+				r.registered = true
+				r.logger.Info("就绪检查恢复，已重新注册")
+			}
+		}
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/lnhlg/gbm-common/i18n"
+)
+
+const (
+	// 默认语言配置键，取值 zh/en
+	LanguageKey = "i18n.lang"
+)
+
+func init() {
+	i18n.Register(i18n.EN, map[string]string{
+		// 密钥与加解密
+		"无法创建密钥目录: %w":       "failed to create key directory: %w",
+		"密钥生成失败: %w":         "failed to generate key: %w",
+		"PKCS#8编码失败: %w":     "failed to encode PKCS#8: %w",
+		"创建文件失败: %w":         "failed to create file: %w",
+		"PEM编码失败: %w":        "failed to encode PEM: %w",
+		"公钥序列化失败: %w":        "failed to marshal public key: %w",
+		"读取文件失败: %w":         "failed to read file: %w",
+		"读取公钥文件失败: %w":       "failed to read public key file: %w",
+		"读取私钥文件失败: %w":       "failed to read private key file: %w",
+		"文本过长(最大%d字符)，请缩短内容": "text too long (max %d characters), please shorten it",
+		"加密失败: %w":           "encryption failed: %w",
+		"Base64解码失败: %w":     "failed to decode base64: %w",
+		"解密失败: %w":           "decryption failed: %w",
+		"无效的PEM格式":           "invalid PEM format",
+		"不是RSA私钥":            "not an RSA private key",
+		"无法解析私钥格式":           "unable to parse private key format",
+		"不是公钥: %s":           "not a public key: %s",
+		"解析公钥失败: %w":         "failed to parse public key: %w",
+		"不是RSA公钥":            "not an RSA public key",
+		"解密配置失败(%s): %w":     "failed to decrypt config (%s): %w",
+
+		// 请求校验
+		"参数校验失败: %s": "validation failed: %s",
+
+		// 分页
+		"无效的页码: %s":    "invalid page: %s",
+		"无效的页大小: %s":   "invalid page size: %s",
+		"不支持的排序字段: %s": "unsupported sort field: %s",
+
+		// Nacos
+		"发布配置失败: %w":         "failed to publish config: %w",
+		"发布配置失败: %s/%s":      "failed to publish config: %s/%s",
+		"删除配置失败: %w":         "failed to delete config: %w",
+		"删除配置失败: %s/%s":      "failed to delete config: %s/%s",
+		"默认HTTP传输层不支持TLS设置":  "default HTTP transport does not support TLS settings",
+		"读取CA证书失败: %w":       "failed to read CA certificate: %w",
+		"无效的CA证书":            "invalid CA certificate",
+		"查询命名空间失败: %w":       "failed to list namespaces: %w",
+		"创建命名空间失败: %w":       "failed to create namespace: %w",
+		"创建命名空间失败: %s":       "failed to create namespace: %s",
+		"查询服务列表失败: %w":       "failed to list services: %w",
+		"管理接口需要配置Nacos服务器列表": "admin API requires a static Nacos server list",
+	})
+}
+
+// WatchLanguage 从配置读取 i18n.lang 作为默认语言，并在配置变化时更新
+// 请求中的语言由 Accept-Language 决定，见 i18n.Server
+func WatchLanguage(c config.Config, logger log.Logger) error {
+	if s, err := c.Value(LanguageKey).String(); err == nil {
+		i18n.SetDefault(i18n.Lang(s))
+	}
+
+	err := c.Watch(LanguageKey, func(_ string, v config.Value) {
+		s, err := v.String()
+		if err != nil {
+			return
+		}
+		i18n.SetDefault(i18n.Lang(s))
+		_ = logger.Log(log.LevelInfo, log.DefaultMessageKey, "默认语言已更新: "+s)
+	})
+	if err == config.ErrNotFound {
+		return nil
+	}
+	return err
+}
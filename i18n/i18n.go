@@ -0,0 +1,154 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Lang 语言
+type Lang string
+
+// 支持的语言
+const (
+	ZH Lang = "zh"
+	EN Lang = "en"
+)
+
+// HeaderAcceptLanguage 请求语言头
+const HeaderAcceptLanguage = "Accept-Language"
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[Lang]map[string]string{}
+
+	defaultLang atomic.Value
+)
+
+func init() {
+	defaultLang.Store(ZH)
+}
+
+// Register 注册某种语言的翻译，键为中文原文(含格式化占位符)，值为译文
+// 译文中的占位符需与原文一致，重复注册时后者覆盖前者
+func Register(lang Lang, messages map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	m, ok := catalogs[lang]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		catalogs[lang] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
+
+// SetDefault 设置默认语言，用于非请求场景(启动、配置加载等)的错误信息
+func SetDefault(lang Lang) {
+	if lang == "" {
+		lang = ZH
+	}
+	defaultLang.Store(lang)
+}
+
+// Default 默认语言
+func Default() Lang {
+	return defaultLang.Load().(Lang)
+}
+
+// T 将中文原文翻译为指定语言，没有对应译文时返回原文
+func T(lang Lang, msg string) string {
+	if lang == "" || lang == ZH {
+		return msg
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if s, ok := catalogs[lang][msg]; ok {
+		return s
+	}
+	return msg
+}
+
+// Sprintf 翻译格式串后格式化
+func Sprintf(lang Lang, format string, args ...interface{}) string {
+	return fmt.Sprintf(T(lang, format), args...)
+}
+
+// Errorf 使用默认语言翻译格式串后创建错误，支持 %w
+func Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(T(Default(), format), args...)
+}
+
+// Supported 是否为已支持的语言(中文或注册过译文的语言)
+func Supported(lang Lang) bool {
+	if lang == ZH {
+		return true
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Parse 解析 Accept-Language，按权重返回第一个支持的语言，没有时返回默认语言
+// 例如 "en-US,en;q=0.9,zh;q=0.8" 返回 EN
+func Parse(header string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+
+	var list []candidate
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		tag, q := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = strings.TrimSpace(part[:i])
+			if v, ok := strings.CutPrefix(strings.TrimSpace(part[i+1:]), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if i := strings.IndexAny(tag, "-_"); i >= 0 {
+			tag = tag[:i]
+		}
+		if q > 0 {
+			list = append(list, candidate{lang: Lang(strings.ToLower(tag)), q: q})
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].q > list[j].q })
+	for _, c := range list {
+		if Supported(c.lang) {
+			return c.lang
+		}
+	}
+	return Default()
+}
+
+type langKey struct{}
+
+// NewContext 将语言写入上下文
+func NewContext(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// FromContext 从上下文读取语言，没有时返回默认语言
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(langKey{}).(Lang); ok {
+		return lang
+	}
+	return Default()
+}
@@ -0,0 +1,45 @@
+package i18n
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Server 服务端中间件：根据 Accept-Language 确定请求语言并写入上下文，
+// 返回的kratos错误信息按请求语言翻译(HTTP和gRPC均生效)
+func Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			lang := Default()
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if h := tr.RequestHeader().Get(HeaderAcceptLanguage); h != "" {
+					lang = Parse(h)
+				}
+			}
+
+			reply, err := handler(NewContext(ctx, lang), req)
+			if err != nil {
+				err = Localize(err, lang)
+			}
+			return reply, err
+		}
+	}
+}
+
+// Localize 将kratos错误信息翻译为指定语言，非kratos错误原样返回
+func Localize(err error, lang Lang) error {
+	se := new(errors.Error)
+	if !errors.As(err, &se) {
+		return err
+	}
+	msg := T(lang, se.Message)
+	if msg == se.Message {
+		return err
+	}
+	e := errors.Clone(se)
+	e.Message = msg
+	return e
+}
@@ -0,0 +1,157 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// crockford ULID使用的Crockford Base32字母表
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidLen ULID字符串长度
+const ulidLen = 26
+
+// maxULIDTime ULID可表示的最大毫秒时间戳(48位)
+const maxULIDTime = 1<<48 - 1
+
+// NewUUID 生成随机UUID(v4)
+func NewUUID() string {
+	return uuid.NewString()
+}
+
+// NewUUIDv7 生成按时间排序的UUID(v7)，适合作为数据库主键，生成失败时退化为v4
+func NewUUIDv7() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// IsUUID 是否为合法的UUID字符串(标准36位格式)
+func IsUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+// UUIDTime 取出v7 UUID中的时间
+func UUIDTime(s string) (time.Time, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if id.Version() != 7 {
+		return time.Time{}, fmt.Errorf("不是v7 UUID: %s", s)
+	}
+	sec, nsec := id.Time().UnixTime()
+	return time.Unix(sec, nsec), nil
+}
+
+// ulidGen 单调ULID生成器：同一毫秒内随机部分递增，保证进程内严格有序
+var ulidGen struct {
+	mu   sync.Mutex
+	last uint64
+	rnd  [10]byte
+}
+
+// NewULID 生成ULID：48位毫秒时间戳 + 80位随机数，26位Crockford Base32，字典序即时间序
+func NewULID() string {
+	return newULID(time.Now())
+}
+
+// newULID 生成指定时间的单调ULID
+func newULID(t time.Time) string {
+	ms := uint64(t.UnixMilli())
+
+	ulidGen.mu.Lock()
+	if ms <= ulidGen.last {
+		// 同一毫秒或时钟回拨时沿用上次的时间戳，随机部分加一
+		ms = ulidGen.last
+		incr(ulidGen.rnd[:])
+	} else {
+		ulidGen.last = ms
+		_, _ = rand.Read(ulidGen.rnd[:])
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	copy(b[6:], ulidGen.rnd[:])
+	ulidGen.mu.Unlock()
+
+	return encodeULID(b)
+}
+
+// ULIDLowerBound 指定时间最小的ULID，用于按时间范围查询
+func ULIDLowerBound(t time.Time) string {
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	return encodeULID(b)
+}
+
+// IsULID 是否为合法的ULID字符串(不区分大小写)
+func IsULID(s string) bool {
+	_, err := ULIDTime(s)
+	return err == nil
+}
+
+// ULIDTime 取出ULID中的时间
+func ULIDTime(s string) (time.Time, error) {
+	if len(s) != ulidLen {
+		return time.Time{}, fmt.Errorf("ULID长度错误: %s", s)
+	}
+	// 首字符最大为7，否则超出128位
+	if s[0] > '7' {
+		return time.Time{}, fmt.Errorf("ULID超出范围: %s", s)
+	}
+
+	var ms uint64
+	for i, c := range strings.ToUpper(s) {
+		v := strings.IndexRune(crockford, c)
+		if v < 0 {
+			return time.Time{}, fmt.Errorf("ULID包含非法字符: %s", s)
+		}
+		// 前10个字符为时间戳
+		if i < 10 {
+			ms = ms<<5 | uint64(v)
+		}
+	}
+	if ms > maxULIDTime {
+		return time.Time{}, fmt.Errorf("ULID时间戳超出范围: %s", s)
+	}
+	return time.UnixMilli(int64(ms)), nil
+}
+
+// encodeULID 按Crockford Base32编码128位
+func encodeULID(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[0:8])
+	lo := binary.BigEndian.Uint64(b[8:16])
+
+	var out [ulidLen]byte
+	for i := ulidLen - 1; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// incr 大端字节数组加一
+func incr(b []byte) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return
+		}
+	}
+}
@@ -1,30 +1,86 @@
 package common
 
 import (
-	"os"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/config"
-	"github.com/go-kratos/kratos/v2/config/file"
 	"github.com/go-kratos/kratos/v2/log"
-	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/registry"
 
-	"gopkg.in/yaml.v3"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/lnhlg/gbm-common/i18n"
+	"github.com/lnhlg/gbm-common/timeutil"
 )
 
 type app struct {
-	id             string
-	name           string
-	version        string
-	nacosNamespace string
-	nacosCfg       *NacosCfgSource
+	id                string
+	name              string
+	version           string
+	nacosNamespace    string
+	nacosCfg          *NacosCfgSource
+	k8s               *KubernetesRegistry
+	metadata          map[string]string
+	weight            float64
+	standalone        bool
+	nacosGroup        string
+	nacosCluster      string
+	nacosDataIDs      []string // Init按运行环境分组创建的Nacos配置dataId
+	ephemeral         *bool
+	discoveryClusters []string
+	registrars        []RegistrarBackend
+	warmupTasks       []warmupTask
+	warmupTimeout     time.Duration
+	rampWeight        float64
+	rampDuration      time.Duration
+	mergePolicy       *ConfigMergePolicy
+	drain             time.Duration
+	stopTimeout       time.Duration
+	confPath          string
+	profile           string
+	sources           []config.Source
+	loggerOpts        []LoggerOption
+	noMetrics         bool
+	sentry            *SentryConfig
+	traceCfg          *TraceConfig
+	metricsCfg        *MetricsConfig
+	decryptor         *RSADecryptor
+	workerLock        DistributedLock // 预留雪花ID workerID 使用的分布式锁
+	language          i18n.Lang       // 默认语言，Init时设置
+	optErr            error           // 选项执行失败的错误，Setup时返回
 }
 
 type appResult struct {
-	Reg     registry.Registrar
-	Logger  log.Logger
-	Metrics *Metrics
-	Cfg     config.Config
+	Reg       registry.Registrar
+	Discovery registry.Discovery
+	Logger    log.Logger
+	LogLevel  *LevelLogger // 运行时调整日志级别，配置键 log.level 变化时自动更新
+	Metrics   *Metrics
+	Cfg       config.Config
+	Tracer    *sdktrace.TracerProvider // 未配置 trace.endpoint 时为nil
+	Metadata  map[string]string        // 注册实例的元数据，传给 kratos.Metadata
+	Shutdown  *ShutdownManager
+	Health    *Health
+	Admin     *AdminServer       // 配置 admin.enabled 时创建并启动
+	History   *ConfigHistory     // 配置版本历史，用于查看热更新差异和回滚
+	Profile   string             // 当前运行环境，同时写入配置键 app.profile
+	Nacos     *NacosMonitor      // Nacos连接监控，使用Kubernetes服务发现时为nil
+	Lifecycle *Lifecycle         // 按优先级有序启动和关闭的组件钩子，Init创建的资源已注册
+	Calendar  *timeutil.Calendar // 工作日历，配置 calendar 变化时自动更新
+
+	id      string
+	name    string
+	version string
+
+	closers   []func() error
+	closeOnce sync.Once
+	closeErr  error
+
+	registrarTimeout time.Duration // 启动后注册的超时时间，覆盖预热、就绪等待和注册本身
 }
 
 func NewApp(
@@ -40,50 +96,610 @@ func NewApp(
 	}
 }
 
+// WithKubernetes 使用Kubernetes原生服务发现代替Nacos注册中心
+func (a *app) WithKubernetes(namespace string) *app {
+	a.k8s = NewKubernetesRegistry(namespace)
+	return a
+}
+
+// WithKubernetesRegistry 使用自定义的Kubernetes注册中心
+func (a *app) WithKubernetesRegistry(k *KubernetesRegistry) *app {
+	a.k8s = k
+	return a
+}
+
+// WithMetadata 设置注册实例的元数据(zone、协议端口等)
+func (a *app) WithMetadata(md map[string]string) *app {
+	if a.metadata == nil {
+		a.metadata = make(map[string]string, len(md))
+	}
+	for k, v := range md {
+		a.metadata[k] = v
+	}
+	return a
+}
+
+// WithWeight 设置注册实例的权重
+func (a *app) WithWeight(weight float64) *app {
+	a.weight = weight
+	return a
+}
+
+// WithRegistrar 追加同时注册的注册中心(如边缘网格使用的Consul)，服务发现仍使用主注册中心
+// optional为true时该注册中心注册失败不影响启动，后台重试；单机模式下不注册
+func (a *app) WithRegistrar(name string, reg registry.Registrar, optional bool) *app {
+	a.registrars = append(a.registrars, RegistrarBackend{Name: name, Registrar: reg, Optional: optional})
+	return a
+}
+
+// WithMergePolicy 设置配置源优先级和合并策略，见 ConfigMergePolicy
+func (a *app) WithMergePolicy(p ConfigMergePolicy) *app {
+	a.mergePolicy = &p
+	return a
+}
+
+// WithWarmup 追加预热任务，全部执行完成后才注册到注册中心
+func (a *app) WithWarmup(name string, task WarmupTask) *app {
+	a.warmupTasks = append(a.warmupTasks, warmupTask{name: name, fn: task})
+	return a
+}
+
+// WithWeightRamp 以初始权重注册，在d时间内逐步升到配置的权重，避免冷实例立即承接全部流量
+func (a *app) WithWeightRamp(initial float64, d time.Duration) *app {
+	a.rampWeight = initial
+	a.rampDuration = d
+	return a
+}
+
+// WithStandalone 开启单机模式：不连接Nacos，注册为空操作，发现使用配置 standalone.services 中的静态地址，
+// 配置只来自本地文件、环境变量等非Nacos配置源；也可通过环境变量 APP_STANDALONE=true 开启
+func (a *app) WithStandalone() *app {
+	a.standalone = true
+	return a
+}
+
+// WithNacosGroup 设置Nacos配置、注册和发现使用的分组，默认按运行环境取 ProfileGroup
+func (a *app) WithNacosGroup(group string) *app {
+	a.nacosGroup = group
+	return a
+}
+
+// WithNacosDataIDs 设置Init加载的Nacos配置dataId，分组见 WithNacosGroup
+func (a *app) WithNacosDataIDs(dataids ...string) *app {
+	a.nacosDataIDs = append(a.nacosDataIDs, dataids...)
+	return a
+}
+
+// WithNacosCluster 设置注册实例所在的Nacos集群，默认 DEFAULT
+func (a *app) WithNacosCluster(cluster string) *app {
+	a.nacosCluster = cluster
+	return a
+}
+
+// WithWorkerIDLock 设置预留雪花ID workerID 使用的分布式锁
+// 未配置 snowflake.worker_id 时通过该锁为workerID加租约，保证多副本之间不重复
+func (a *app) WithWorkerIDLock(l DistributedLock) *app {
+	a.workerLock = l
+	return a
+}
+
+// WithEphemeral 设置是否注册为Nacos临时实例，默认是
+func (a *app) WithEphemeral(ephemeral bool) *app {
+	a.ephemeral = &ephemeral
+	return a
+}
+
+// WithDiscoveryClusters 设置服务发现只返回指定集群的实例，默认返回全部集群
+func (a *app) WithDiscoveryClusters(clusters ...string) *app {
+	a.discoveryClusters = clusters
+	return a
+}
+
+// WithConfig 应用引导配置中的注册、下线、日志、错误上报、链路和指标设置
+func (a *app) WithConfig(c *appConfig) *app {
+	if len(c.Registry.Metadata) > 0 {
+		a.WithMetadata(c.Registry.Metadata)
+	}
+	if c.Standalone {
+		a.WithStandalone()
+	}
+	if c.Registry.Weight > 0 {
+		a.WithWeight(c.Registry.Weight)
+	}
+	if c.Registry.Group != "" {
+		a.WithNacosGroup(c.Registry.Group)
+	}
+	if c.Registry.Cluster != "" {
+		a.WithNacosCluster(c.Registry.Cluster)
+	}
+	if c.Registry.Ephemeral != nil {
+		a.WithEphemeral(*c.Registry.Ephemeral)
+	}
+	if len(c.Registry.DiscoveryClusters) > 0 {
+		a.WithDiscoveryClusters(c.Registry.DiscoveryClusters...)
+	}
+	if w := c.Registry.Warmup; w != nil {
+		if w.Ramp > 0 {
+			a.WithWeightRamp(w.InitialWeight, w.Ramp)
+		}
+		a.warmupTimeout = w.Timeout
+	}
+	if c.ConfigMerge != nil {
+		a.WithMergePolicy(*c.ConfigMerge)
+	}
+	if c.Shutdown.Drain > 0 {
+		a.WithDrain(c.Shutdown.Drain)
+	}
+	a.stopTimeout = c.Shutdown.Timeout
+	if c.Log.Level != "" {
+		a.loggerOpts = append(a.loggerOpts, WithLogLevel(c.Log.Level))
+	}
+	if c.Log.Backend != "" {
+		a.loggerOpts = append(a.loggerOpts, WithLogBackend(c.Log.Backend))
+	}
+	if c.Log.Format != "" {
+		a.loggerOpts = append(a.loggerOpts, WithLogFormat(c.Log.Format))
+	}
+	if c.Log.Sampling != nil {
+		a.loggerOpts = append(a.loggerOpts, WithLogSampling(c.Log.Sampling.First, c.Log.Sampling.Thereafter))
+	}
+	if c.Sentry != nil && c.Sentry.DSN != "" {
+		a.WithSentry(*c.Sentry)
+	}
+	a.traceCfg = c.Trace
+	a.metricsCfg = c.Metrics
+	return a
+}
+
+// WithSentry 开启Sentry错误上报：Error级别日志和中间件恢复的panic会上报到Sentry
+func (a *app) WithSentry(cfg SentryConfig) *app {
+	a.sentry = &cfg
+	return a
+}
+
+// WithDecryptor 设置配置解密器，配置中的 ENC(...) 值在加载和热更新时自动解密
+func (a *app) WithDecryptor(dec *RSADecryptor) *app {
+	a.decryptor = dec
+	return a
+}
+
+// WithProfile 设置运行环境，默认读取环境变量 APP_ENV
+func (a *app) WithProfile(profile string) *app {
+	a.profile = profile
+	return a
+}
+
+// WithDrain 设置优雅下线时注销后的摘流等待时间
+func (a *app) WithDrain(d time.Duration) *app {
+	a.drain = d
+	return a
+}
+
 func (a *app) Init(
 	confPath string,
 	s ...config.Source,
 ) (*appResult, error) {
-	logger := log.With(log.NewStdLogger(os.Stdout),
-		"ts", log.DefaultTimestamp,
-		"caller", log.DefaultCaller,
-		"service.id", a.id,
-		"service.name", a.name,
-		"service.version", a.version,
-
-		"trace.id", tracing.TraceID(),
-		"span.id", tracing.SpanID(),
-	)
+	if a.language != "" {
+		i18n.SetDefault(a.language)
+	}
+	if a.sentry != nil {
+		if err := InitSentry(*a.sentry, a.id, a.name, a.version); err != nil {
+			return nil, err
+		}
+	}
 
-	reg, err := a.nacosCfg.NacosNaming(a.nacosNamespace)
-	if err != nil {
+	logger, levelLogger, flushLogs := a.newLogger()
+	lifecycle := NewLifecycle(logger)
+
+	// 失败时按相反顺序释放已创建的资源
+	closers := []func() error{flushLogs}
+	fail := func(err error) (*appResult, error) {
+		_ = lifecycle.Stop(context.Background())
+		for i := len(closers) - 1; i >= 0; i-- {
+			_ = closers[i]()
+		}
 		return nil, err
 	}
 
-	s = append(s, file.NewSource(confPath))
+	reg, dis, err := a.registry()
+	if err != nil {
+		return fail(err)
+	}
+
+	// 注册前依次等待预热和就绪检查，kratos注册超时需覆盖两者
+	registrarTimeout := DefaultReadinessTimeout + DefaultRegistrarTimeout
+	if len(a.warmupTasks) > 0 || a.rampDuration > 0 {
+		warmup := NewWarmupRegistrar(reg, logger).WithRamp(a.rampWeight, a.rampDuration)
+		if a.warmupTimeout > 0 {
+			warmup.WithTimeout(a.warmupTimeout)
+		}
+		for _, t := range a.warmupTasks {
+			warmup.WithTask(t.name, t.fn)
+		}
+		registrarTimeout += warmup.timeout
+		reg = warmup
+	}
+	if len(a.registrars) > 0 && !a.isStandalone() {
+		backends := append([]RegistrarBackend{{Name: "primary", Registrar: reg}}, a.registrars...)
+		reg = NewMultiRegistrar(logger, backends...)
+	}
+
+	health := NewHealth()
+	healthReg := NewHealthRegistrar(reg, health, logger)
+
+	history := NewConfigHistory(0)
+
+	// 运行环境对应分组的Nacos配置源先加载，之后是调用方传入的配置源
+	if len(a.nacosDataIDs) > 0 && !a.isStandalone() && a.nacosCfg != nil {
+		ids := make([]NacosDataID, len(a.nacosDataIDs))
+		for i, id := range a.nacosDataIDs {
+			ids[i] = NacosDataID{DataID: id, Group: a.nacosGroupName()}
+		}
+		nacosSources, err := a.nacosCfg.NacosSources(a.nacosNamespace, ids...)
+		if err != nil {
+			return fail(err)
+		}
+		s = append(nacosSources, s...)
+	}
+
+	if a.isStandalone() {
+		var dropped int
+		if s, dropped = localSources(s); dropped > 0 {
+			log.NewHelper(logger).Warnf("单机模式忽略%d个Nacos配置源", dropped)
+		}
+	}
+
+	// 未设置合并策略时本地配置文件最后加载，环境配置覆盖基础配置；运行环境标识不可被覆盖
+	profile := a.activeProfile()
+	files := profileFileSources(confPath, profile)
+	if a.mergePolicy != nil {
+		classified := make([]ClassifiedSource, 0, len(s)+len(files))
+		for _, src := range s {
+			classified = append(classified, ClassifiedSource{Class: classifySource(src), Source: src})
+		}
+		for _, src := range files {
+			classified = append(classified, ClassifiedSource{Class: SourceFile, Source: src})
+		}
+		merged, err := NewMergedSource(*a.mergePolicy, classified...)
+		if err != nil {
+			return fail(err)
+		}
+		s = []config.Source{merged}
+	} else {
+		s = append(s, files...)
+	}
+	s = append(s, &profileSource{profile: profile})
+	for i := range s {
+		s[i] = history.Source(s[i])
+	}
 	c := config.New(
 		config.WithSource(
 			s...,
 		),
-		config.WithDecoder(func(kv *config.KeyValue, v map[string]interface{}) error {
-			return yaml.Unmarshal(kv.Value, v)
-		}),
+		config.WithDecoder(DecodeConfig),
+		config.WithResolver(a.resolver(history)),
 	)
-	defer c.Close()
+	if a.nacosCfg != nil {
+		// 逆序执行，配置监听停止后再关闭Nacos配置客户端
+		closers = append(closers, a.nacosCfg.Close)
+	}
+	closers = append(closers, c.Close)
+	if a.sentry != nil {
+		lifecycle.OnStop("sentry", PriorityFlush, func(context.Context) error { return FlushSentry() })
+	}
 
 	if err := c.Load(); err != nil {
-		return nil, err
+		return fail(err)
 	}
 
-	gbmMetrics, err := NewMetrics(a.name)
-	if err != nil {
-		return nil, err
+	if err := levelLogger.WatchLevel(c); err != nil {
+		return fail(err)
+	}
+
+	if err := WatchLanguage(c, logger); err != nil {
+		return fail(err)
+	}
+
+	if err := WatchTimezone(c, logger); err != nil {
+		return fail(err)
+	}
+
+	calendar := timeutil.NewCalendar()
+	if err := WatchCalendar(c, calendar, logger); err != nil {
+		return fail(err)
+	}
+
+	if static, ok := reg.(*StaticRegistry); ok {
+		if err := watchStandaloneServices(c, static); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := a.initIDGenerator(c, dis, lifecycle, logger); err != nil {
+		return fail(err)
+	}
+
+	var tp *sdktrace.TracerProvider
+	var traceCfg TraceConfig
+	if err := c.Value(TraceConfigKey).Scan(&traceCfg); err != nil && a.traceCfg != nil {
+		traceCfg = *a.traceCfg
+	}
+	if traceCfg.Endpoint != "" {
+		sampler, err := traceCfg.Sampler()
+		if err != nil {
+			return fail(err)
+		}
+		tp, err = NewTracerProviderWithSampler(context.Background(), traceCfg, sampler, a.id, a.name, a.version)
+		if err != nil {
+			return fail(err)
+		}
+		if err := WatchTraceSampling(c, sampler, logger); err != nil {
+			return fail(err)
+		}
+		lifecycle.OnStop("tracer", PriorityFlush, tp.Shutdown)
+	}
+
+	var gbmMetrics *Metrics
+	if !a.noMetrics {
+		var metricsCfg MetricsConfig
+		if err := c.Value(MetricsConfigKey).Scan(&metricsCfg); err != nil && a.metricsCfg != nil {
+			metricsCfg = *a.metricsCfg
+		}
+
+		gbmMetrics, err = NewMetricsWithConfig(a.name, metricsCfg)
+		if err != nil {
+			return fail(err)
+		}
+		lifecycle.OnStop("metrics", PriorityFlush, gbmMetrics.Shutdown)
+
+		var pushCfg PushConfig
+		if err := c.Value(MetricsPushConfigKey).Scan(&pushCfg); err == nil && pushCfg.URL != "" {
+			if pushCfg.Job == "" {
+				pushCfg.Job = a.name
+			}
+			gbmMetrics.StartPush(pushCfg)
+		}
+	}
+
+	var monitor *NacosMonitor
+	if nreg, ok := reg.(*NacosRegistry); ok {
+		if monitor, err = a.initNacosMonitor(c, nreg, logger, gbmMetrics); err != nil {
+			return fail(err)
+		}
+		health.RegisterReadiness("nacos", monitor.HealthCheck())
+		lifecycle.OnStop("nacos-monitor", PriorityClose, monitor.Stop)
+	}
+
+	var admin *AdminServer
+	var adminCfg AdminConfig
+	if err := c.Value(AdminConfigKey).Scan(&adminCfg); err == nil && adminCfg.Enabled {
+		admin = NewAdminServer(adminCfg, health)
+		if gbmMetrics != nil && gbmMetrics.Gatherer() != nil {
+			admin.Handle(DefaultMetricsPath, gbmMetrics.Handler())
+		}
+		admin.Handle(DefaultLogLevelPath, levelLogger.Handler(adminCfg.Token))
+		go func() {
+			if err := admin.Start(context.Background()); err != nil {
+				log.NewHelper(logger).Errorf("管理端口启动失败: %v", err)
+			}
+		}()
+		lifecycle.OnStop("admin", PriorityIntake, admin.Stop)
+	}
+
+	// 指标由Lifecycle在服务停止后刷新，ShutdownManager只负责注销、摘流和用户钩子
+	shutdown := NewShutdownManager(healthReg, logger)
+	if a.drain > 0 {
+		shutdown.WithDrain(a.drain)
+	}
+	if a.stopTimeout > 0 {
+		shutdown.WithTimeout(a.stopTimeout)
 	}
 
 	return &appResult{
-		Reg:     reg,
-		Logger:  logger,
-		Metrics: gbmMetrics,
-		Cfg:     c,
+		Reg:       healthReg,
+		Discovery: dis,
+		Logger:    logger,
+		LogLevel:  levelLogger,
+		Metrics:   gbmMetrics,
+		Cfg:       c,
+		Tracer:    tp,
+		Metadata:  a.instanceMetadata(),
+		Shutdown:  shutdown,
+		Health:    health,
+		Admin:     admin,
+		History:   history,
+		Profile:   profile,
+		Nacos:     monitor,
+		Lifecycle: lifecycle,
+		Calendar:  calendar,
+		id:        a.id,
+		name:      a.name,
+		version:   a.version,
+		closers:   closers,
+
+		registrarTimeout: registrarTimeout,
 	}, nil
 }
+
+// Close 按Lifecycle顺序关闭组件，再停止配置监听并刷新日志，多次调用只执行一次
+// 服务退出时调用，返回的Cfg在Close之前始终保持监听
+func (r *appResult) Close() error {
+	r.closeOnce.Do(func() {
+		var errs []error
+		if err := r.Lifecycle.Stop(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+		for i := len(r.closers) - 1; i >= 0; i-- {
+			if err := r.closers[i](); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		r.closeErr = errors.Join(errs...)
+	})
+	return r.closeErr
+}
+
+// Cleanup 返回清理函数，便于配合wire等依赖注入工具使用
+func (r *appResult) Cleanup() func() {
+	return func() {
+		if err := r.Close(); err != nil {
+			log.NewHelper(r.Logger).Errorf("释放资源失败: %v", err)
+		}
+	}
+}
+
+// resolver 配置解析器：展开占位符，解密加密值，再记录版本(快照中不含明文)
+func (a *app) resolver(history *ConfigHistory) config.Resolver {
+	rs := []config.Resolver{EnvResolver}
+	if a.decryptor != nil {
+		rs = append(rs, decryptResolver(a.decryptor, history.markSecret))
+	}
+	return chainResolvers(append(rs, history.Resolver)...)
+}
+
+// initIDGenerator 初始化全局ID生成器，并将workerID写入注册元数据
+// 优先使用配置 snowflake.worker_id；设置了workerID锁时预留空闲的workerID，租约在关闭时释放；
+// 否则从注册中心已用的workerID中分配（多副本同时启动时可能重复），查询失败时按主机名哈希兜底
+func (a *app) initIDGenerator(c config.Config, dis registry.Discovery, lifecycle *Lifecycle, logger log.Logger) error {
+	var cfg SnowflakeConfig
+	if err := c.Value(SnowflakeConfigKey).Scan(&cfg); err != nil && err != config.ErrNotFound {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var workerID int64
+	var release func(context.Context) error
+	switch {
+	case cfg.WorkerID != nil:
+		workerID = *cfg.WorkerID
+	case a.workerLock != nil:
+		id, lease, err := ReserveWorkerID(ctx, a.workerLock, dis, a.name, DefaultWorkerIDLeaseTTL)
+		if err != nil {
+			return err
+		}
+		workerID = id
+		release = KeepWorkerIDLease(a.workerLock, lease, DefaultWorkerIDLeaseTTL, func(err error) {
+			log.NewHelper(logger).Errorf("workerID租约续期失败(%d): %v", id, err)
+		})
+	default:
+		if !a.isStandalone() {
+			log.NewHelper(logger).Warn("未配置 snowflake.worker_id 且未设置workerID锁(WithWorkerIDLock)，多副本同时启动时workerID可能重复")
+		}
+		id, err := AllocateWorkerID(ctx, dis, a.name)
+		if err != nil {
+			id = hostWorkerID()
+			log.NewHelper(logger).Warnf("从注册中心分配workerID失败，按主机名使用%d: %v", id, err)
+		}
+		workerID = id
+	}
+
+	sf, err := NewSnowflake(workerID)
+	if err != nil {
+		if release != nil {
+			_ = release(context.Background())
+		}
+		return err
+	}
+	if release != nil {
+		lifecycle.OnStop("snowflake", PriorityClose, release)
+	}
+	SetIDGenerator(sf)
+	a.WithMetadata(map[string]string{WorkerIDMetadataKey: strconv.FormatInt(workerID, 10)})
+	log.NewHelper(logger).Infof("ID生成器workerID: %d", workerID)
+	return nil
+}
+
+// initNacosMonitor 创建并启动Nacos连接监控
+// 配置键 nacos.servers 变化时更新服务器列表并重建命名客户端
+func (a *app) initNacosMonitor(c config.Config, reg *NacosRegistry, logger log.Logger, metrics *Metrics) (*NacosMonitor, error) {
+	monitor, err := NewNacosMonitor(a.nacosCfg, logger, metrics)
+	if err != nil {
+		return nil, err
+	}
+	monitor.OnServersChanged(reg.Reconnect)
+
+	err = c.Watch(NacosServersKey, func(_ string, v config.Value) {
+		var servers []nacosServer
+		if err := v.Scan(&servers); err != nil {
+			log.NewHelper(logger).Errorf("解码Nacos服务器列表失败: %v", err)
+			return
+		}
+		if len(servers) > 0 {
+			_ = monitor.UpdateServers(context.Background(), nacosHosts(servers))
+		}
+	})
+	if err != nil && err != config.ErrNotFound {
+		return nil, err
+	}
+
+	monitor.Start()
+	return monitor, nil
+}
+
+// activeProfile 当前运行环境，未通过选项设置时读取 APP_ENV
+func (a *app) activeProfile() string {
+	if a.profile != "" {
+		return a.profile
+	}
+	return ActiveProfile()
+}
+
+// nacosGroupName Nacos配置、注册和发现使用的分组，未显式设置时取运行环境对应的分组
+func (a *app) nacosGroupName() string {
+	if a.nacosGroup != "" {
+		return a.nacosGroup
+	}
+	return ProfileGroup(a.activeProfile())
+}
+
+// isStandalone 是否为单机模式
+func (a *app) isStandalone() bool {
+	return a.standalone || standaloneFromEnv()
+}
+
+// registry 根据运行模式创建注册中心
+func (a *app) registry() (registry.Registrar, registry.Discovery, error) {
+	if a.isStandalone() {
+		static := NewStaticRegistry(nil)
+		return static, static, nil
+	}
+	if a.k8s != nil {
+		return a.k8s, a.k8s, nil
+	}
+
+	if a.nacosCfg == nil {
+		return nil, nil, errors.New("未配置Nacos，本地开发可开启单机模式(APP_STANDALONE=true)")
+	}
+	reg, err := a.nacosCfg.NacosRegistry(a.nacosNamespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.weight > 0 {
+		reg.WithWeight(a.weight)
+	}
+	reg.WithGroup(a.nacosGroupName())
+	if a.nacosCluster != "" {
+		reg.WithCluster(a.nacosCluster)
+	}
+	if a.ephemeral != nil {
+		reg.WithEphemeral(*a.ephemeral)
+	}
+	if len(a.discoveryClusters) > 0 {
+		reg.WithDiscoveryClusters(a.discoveryClusters...)
+	}
+	return reg, reg, nil
+}
+
+// instanceMetadata 生成注册实例的元数据
+func (a *app) instanceMetadata() map[string]string {
+	md := make(map[string]string, len(a.metadata)+1)
+	for k, v := range a.metadata {
+		md[k] = v
+	}
+	if a.weight > 0 {
+		md["weight"] = strconv.FormatFloat(a.weight, 'f', -1, 64)
+	}
+	return md
+}
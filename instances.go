@@ -0,0 +1,117 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// InstanceEvent 服务实例变化事件
+type InstanceEvent struct {
+	Instances []*registry.ServiceInstance // 变化后的全部实例
+	Added     []*registry.ServiceInstance // 新增实例
+	Removed   []*registry.ServiceInstance // 下线实例
+}
+
+// ListInstances 列出服务的健康实例
+func ListInstances(ctx context.Context, dis registry.Discovery, serviceName string) ([]*registry.ServiceInstance, error) {
+	return dis.GetService(ctx, serviceName)
+}
+
+// SubscribeInstances 订阅服务实例变化
+// 每次实例列表变化都会向返回的通道发送一个事件，ctx取消后通道关闭
+func SubscribeInstances(ctx context.Context, dis registry.Discovery, serviceName string) (<-chan InstanceEvent, error) {
+	w, err := dis.Watch(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan InstanceEvent, 1)
+	go func() {
+		defer close(ch)
+		defer w.Stop()
+
+		var last []*registry.ServiceInstance
+		for {
+			items, err := w.Next()
+			if errors.Is(err, ErrNoServiceInstance) {
+				// 最后一个实例下线，按空列表处理以发出下线事件
+				items, err = nil, nil
+			}
+			if err != nil {
+				if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+				}
+				continue
+			}
+
+			added, removed := diffInstances(last, items)
+			last = items
+			if len(added) == 0 && len(removed) == 0 {
+				continue
+			}
+
+			select {
+			case ch <- InstanceEvent{Instances: items, Added: added, Removed: removed}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// OnInstancesChanged 订阅服务实例变化并以回调方式处理
+func OnInstancesChanged(ctx context.Context, dis registry.Discovery, serviceName string, fn func(InstanceEvent)) error {
+	ch, err := SubscribeInstances(ctx, dis, serviceName)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for e := range ch {
+			fn(e)
+		}
+	}()
+	return nil
+}
+
+// diffInstances 比较新旧实例列表，返回新增和下线的实例
+func diffInstances(old, cur []*registry.ServiceInstance) (added, removed []*registry.ServiceInstance) {
+	oldSet := make(map[string]*registry.ServiceInstance, len(old))
+	for _, in := range old {
+		oldSet[instanceKey(in)] = in
+	}
+	curSet := make(map[string]*registry.ServiceInstance, len(cur))
+	for _, in := range cur {
+		curSet[instanceKey(in)] = in
+	}
+
+	for k, in := range curSet {
+		if _, ok := oldSet[k]; !ok {
+			added = append(added, in)
+		}
+	}
+	for k, in := range oldSet {
+		if _, ok := curSet[k]; !ok {
+			removed = append(removed, in)
+		}
+	}
+	return added, removed
+}
+
+// instanceKey 实例唯一标识
+func instanceKey(in *registry.ServiceInstance) string {
+	if len(in.Endpoints) > 0 {
+		return in.ID + "|" + in.Endpoints[0]
+	}
+	return in.ID
+}
@@ -0,0 +1,217 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const (
+	// 默认集群域名
+	DefaultClusterDomain = "cluster.local"
+
+	// 默认服务轮询间隔
+	DefaultKubernetesPollInterval = 5 * time.Second
+)
+
+var (
+	_ registry.Registrar = (*KubernetesRegistry)(nil)
+	_ registry.Discovery = (*KubernetesRegistry)(nil)
+
+	// ErrNoServiceInstance 服务当前没有可用实例（DNS记录不存在或为空）
+	ErrNoServiceInstance = errors.New("未发现服务实例")
+)
+
+// KubernetesRegistry 基于Kubernetes原生服务发现(headless service + DNS)的注册中心
+// 注册由Kubernetes Endpoints负责，因此Register/Deregister为空操作；
+// 发现通过解析 <service>.<namespace>.svc.<clusterDomain> 的SRV/A记录实现
+type KubernetesRegistry struct {
+	namespace     string
+	clusterDomain string
+	schemes       []string
+	interval      time.Duration
+	resolver      *net.Resolver
+}
+
+// NewKubernetesRegistry 创建Kubernetes注册中心
+func NewKubernetesRegistry(namespace string) *KubernetesRegistry {
+	return &KubernetesRegistry{
+		namespace:     namespace,
+		clusterDomain: DefaultClusterDomain,
+		schemes:       []string{"grpc", "http"},
+		interval:      DefaultKubernetesPollInterval,
+		resolver:      net.DefaultResolver,
+	}
+}
+
+// WithClusterDomain 设置集群域名
+func (k *KubernetesRegistry) WithClusterDomain(domain string) *KubernetesRegistry {
+	k.clusterDomain = domain
+	return k
+}
+
+// WithSchemes 设置需要解析的端口名(同时作为endpoint协议)
+func (k *KubernetesRegistry) WithSchemes(schemes ...string) *KubernetesRegistry {
+	k.schemes = schemes
+	return k
+}
+
+// WithPollInterval 设置Watch轮询间隔
+func (k *KubernetesRegistry) WithPollInterval(d time.Duration) *KubernetesRegistry {
+	k.interval = d
+	return k
+}
+
+// Register 注册由Kubernetes负责，这里不做任何处理
+func (k *KubernetesRegistry) Register(_ context.Context, _ *registry.ServiceInstance) error {
+	return nil
+}
+
+// Deregister 注销由Kubernetes负责，这里不做任何处理
+func (k *KubernetesRegistry) Deregister(_ context.Context, _ *registry.ServiceInstance) error {
+	return nil
+}
+
+// GetService 解析headless service得到所有Pod实例
+// 没有实例时返回包装了 ErrNoServiceInstance 的错误，DNS查询失败时返回解析错误
+func (k *KubernetesRegistry) GetService(ctx context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	if serviceName == "" {
+		return nil, errors.New("服务名不能为空")
+	}
+	host := k.serviceHost(serviceName)
+
+	// 按IP聚合各端口的endpoint
+	byIP := make(map[string][]string)
+	var lookupErr error
+	for _, scheme := range k.schemes {
+		_, srvs, err := k.resolver.LookupSRV(ctx, scheme, "tcp", host)
+		if err != nil {
+			if !isDNSNotFound(err) {
+				lookupErr = err
+			}
+			continue
+		}
+		for _, srv := range srvs {
+			ips, err := k.resolver.LookupHost(ctx, srv.Target)
+			if err != nil {
+				if !isDNSNotFound(err) {
+					lookupErr = err
+				}
+				continue
+			}
+			for _, ip := range ips {
+				byIP[ip] = append(byIP[ip], fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(ip, fmt.Sprint(srv.Port))))
+			}
+		}
+	}
+
+	if len(byIP) == 0 {
+		if lookupErr != nil {
+			return nil, fmt.Errorf("解析服务失败(%s): %w", host, lookupErr)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoServiceInstance, host)
+	}
+
+	ips := make([]string, 0, len(byIP))
+	for ip := range byIP {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+
+	items := make([]*registry.ServiceInstance, 0, len(ips))
+	for _, ip := range ips {
+		endpoints := byIP[ip]
+		sort.Strings(endpoints)
+		items = append(items, &registry.ServiceInstance{
+			ID:        ip,
+			Name:      serviceName,
+			Metadata:  map[string]string{"namespace": k.namespace},
+			Endpoints: endpoints,
+		})
+	}
+	return items, nil
+}
+
+// Watch 创建服务实例监听器（按轮询间隔解析DNS，实例变化时返回）
+func (k *KubernetesRegistry) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	w := &kubernetesWatcher{
+		registry:    k,
+		serviceName: serviceName,
+		first:       true,
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	return w, nil
+}
+
+// isDNSNotFound 判断DNS错误是否为记录不存在
+func isDNSNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// serviceHost 生成服务的集群内域名
+func (k *KubernetesRegistry) serviceHost(serviceName string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", serviceName, k.namespace, k.clusterDomain)
+}
+
+// kubernetesWatcher Kubernetes服务实例监听器
+type kubernetesWatcher struct {
+	registry    *KubernetesRegistry
+	serviceName string
+	ctx         context.Context
+	cancel      context.CancelFunc
+	last        []*registry.ServiceInstance
+	first       bool
+}
+
+// Next 首次调用立即返回（包括没有实例时），之后阻塞直到实例列表发生变化
+// 没有实例时返回空列表；DNS解析失败时首次调用返回错误，之后保留上次结果继续轮询
+func (w *kubernetesWatcher) Next() ([]*registry.ServiceInstance, error) {
+	for {
+		if !w.first {
+			select {
+			case <-w.ctx.Done():
+				return nil, w.ctx.Err()
+			case <-time.After(w.registry.interval):
+			}
+		}
+		first := w.first
+		w.first = false
+
+		items, err := w.registry.GetService(w.ctx, w.serviceName)
+		if err != nil && !errors.Is(err, ErrNoServiceInstance) {
+			if first {
+				return nil, err
+			}
+			continue
+		}
+		if first || !sameInstances(w.last, items) {
+			w.last = items
+			return items, nil
+		}
+	}
+}
+
+// Stop 停止监听
+func (w *kubernetesWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// sameInstances 判断两组实例是否一致
+func sameInstances(a, b []*registry.ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
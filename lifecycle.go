@@ -0,0 +1,161 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 关闭阶段优先级，数值越小越先关闭，启动顺序与之相反
+const (
+	// 停止接收流量：注销实例、停止HTTP/gRPC和管理端口
+	PriorityIntake = 100
+
+	// 排空后台任务：定时任务、消息消费者等
+	PriorityDrain = 200
+
+	// 刷新指标、链路和日志
+	PriorityFlush = 300
+
+	// 关闭客户端：数据库、消息队列、缓存等
+	PriorityClose = 400
+
+	// 默认钩子超时时间
+	DefaultHookTimeout = 10 * time.Second
+)
+
+// LifecycleHook 生命周期钩子
+type LifecycleHook struct {
+	Name     string
+	Priority int                             // 关闭优先级，见 PriorityIntake 等常量
+	Timeout  time.Duration                   // 单个钩子的超时时间，默认 DefaultHookTimeout
+	OnStart  func(ctx context.Context) error // 可为nil
+	OnStop   func(ctx context.Context) error // 可为nil
+}
+
+// Lifecycle 按优先级有序执行启动和关闭钩子
+// 关闭按优先级从小到大执行，同优先级按注册的逆序执行；启动顺序与关闭完全相反。
+// 启动失败时已启动的钩子和只有关闭动作的钩子会按关闭顺序回滚
+type Lifecycle struct {
+	mu     sync.Mutex
+	hooks  []LifecycleHook
+	logger *log.Helper
+
+	stopOnce sync.Once
+	stopErr  error
+}
+
+// NewLifecycle 创建生命周期管理器
+func NewLifecycle(logger log.Logger) *Lifecycle {
+	return &Lifecycle{logger: log.NewHelper(logger)}
+}
+
+// Append 注册钩子，Start之后注册的钩子只参与关闭
+func (l *Lifecycle) Append(hook LifecycleHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+// OnStop 注册只有关闭动作的钩子
+func (l *Lifecycle) OnStop(name string, priority int, fn func(ctx context.Context) error) {
+	l.Append(LifecycleHook{Name: name, Priority: priority, OnStop: fn})
+}
+
+// Start 按启动顺序执行启动钩子，失败时回滚已启动的钩子并返回错误
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := l.startOrder()
+	l.mu.Unlock()
+
+	for i, h := range hooks {
+		if h.OnStart != nil {
+			if err := l.run(ctx, h, h.OnStart); err != nil {
+				// 回滚已启动的钩子；未执行启动的钩子不关闭，只有关闭动作的钩子全部保留
+				kept := append([]LifecycleHook(nil), hooks[:i]...)
+				for _, rest := range hooks[i:] {
+					if rest.OnStart == nil {
+						kept = append(kept, rest)
+					}
+				}
+				l.mu.Lock()
+				l.hooks = kept
+				l.mu.Unlock()
+				return errors.Join(fmt.Errorf("启动%s失败: %w", h.Name, err), l.Stop(ctx))
+			}
+		}
+	}
+	return nil
+}
+
+// Stop 按关闭顺序执行关闭钩子，单个钩子失败不影响后续钩子，多次调用只执行一次
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.stopOnce.Do(func() {
+		l.mu.Lock()
+		hooks := l.stopOrder()
+		l.mu.Unlock()
+
+		var errs []error
+		for _, h := range hooks {
+			if h.OnStop == nil {
+				continue
+			}
+			if err := l.run(ctx, h, h.OnStop); err != nil {
+				l.logger.Errorf("关闭%s失败: %v", h.Name, err)
+				errs = append(errs, fmt.Errorf("关闭%s失败: %w", h.Name, err))
+			}
+		}
+		l.stopErr = errors.Join(errs...)
+	})
+	return l.stopErr
+}
+
+// startOrder 启动顺序：优先级从大到小，同优先级按注册顺序
+func (l *Lifecycle) startOrder() []LifecycleHook {
+	hooks := append([]LifecycleHook(nil), l.hooks...)
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].Priority > hooks[j].Priority })
+	return hooks
+}
+
+// stopOrder 关闭顺序，与启动顺序相反
+func (l *Lifecycle) stopOrder() []LifecycleHook {
+	hooks := l.startOrder()
+	for i, j := 0, len(hooks)-1; i < j; i, j = i+1, j-1 {
+		hooks[i], hooks[j] = hooks[j], hooks[i]
+	}
+	return hooks
+}
+
+// run 带超时执行钩子，超时后不再等待钩子返回
+func (l *Lifecycle) run(ctx context.Context, h LifecycleHook, fn func(ctx context.Context) error) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				done <- fmt.Errorf("panic: %v", e)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		l.logger.Debugf("生命周期钩子%s执行完成, 耗时%s", h.Name, time.Since(start))
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("执行超时(%s): %w", timeout, ctx.Err())
+	}
+}
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrLockNotAcquired 锁已被其他实例持有
+	ErrLockNotAcquired = errors.New("锁已被其他实例持有")
+
+	// ErrLockLost 锁已过期或被其他实例获取
+	ErrLockLost = errors.New("锁已失效")
+)
+
+// 默认获取锁的重试间隔
+const DefaultLockRetryInterval = 200 * time.Millisecond
+
+// LockLease 已获取的锁
+type LockLease struct {
+	Key   string
+	Owner string // 持有者标识，续期和释放时校验
+	Token int64  // 防护令牌(fencing token)，同一个键单调递增，下游可据此拒绝过期持有者的写入
+}
+
+// DistributedLock 分布式锁
+type DistributedLock interface {
+	// TryAcquire 尝试获取锁，已被占用时返回 ErrLockNotAcquired
+	TryAcquire(ctx context.Context, key string, ttl time.Duration) (*LockLease, error)
+	// Renew 续期，锁已失效时返回 ErrLockLost
+	Renew(ctx context.Context, lease *LockLease, ttl time.Duration) error
+	// Release 释放锁，锁已失效时返回 ErrLockLost
+	Release(ctx context.Context, lease *LockLease) error
+}
+
+// AcquireLock 阻塞获取锁，直到成功或ctx取消
+func AcquireLock(ctx context.Context, l DistributedLock, key string, ttl time.Duration) (*LockLease, error) {
+	for {
+		lease, err := l.TryAcquire(ctx, key, ttl)
+		if !errors.Is(err, ErrLockNotAcquired) {
+			return lease, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(DefaultLockRetryInterval):
+		}
+	}
+}
+
+// WithLock 获取锁后执行fn，执行期间每隔ttl/3自动续期，结束后释放
+// 锁被占用时直接返回 ErrLockNotAcquired；续期失败时取消传给fn的ctx；ttl必须大于0
+func WithLock(ctx context.Context, l DistributedLock, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	if ttl <= 0 {
+		return fmt.Errorf("锁的有效期必须大于0: %v", ttl)
+	}
+	// ttl不足3ns时ttl/3为0，NewTicker会panic
+	interval := max(ttl/3, time.Nanosecond)
+
+	lease, err := l.TryAcquire(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.Renew(ctx, lease, ttl); err != nil {
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+
+	fnErr := fn(ctx)
+	close(done)
+
+	releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer releaseCancel()
+	if err := l.Release(releaseCtx, lease); err != nil && fnErr == nil {
+		return err
+	}
+	return fnErr
+}
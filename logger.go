@@ -0,0 +1,122 @@
+package common
+
+import (
+	"io"
+	"os"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+)
+
+// LoggerOption 日志选项
+type LoggerOption func(*loggerOptions)
+
+const (
+	// 日志后端
+	LogBackendStd = "std"
+	LogBackendZap = "zap"
+
+	// 日志格式
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+type loggerOptions struct {
+	writer  io.Writer
+	fields  []interface{}
+	backend string
+	format  string
+	level   string
+
+	sampleFirst      int
+	sampleThereafter int
+}
+
+// WithLogWriter 设置日志输出，默认输出到标准输出
+func WithLogWriter(w io.Writer) LoggerOption {
+	return func(o *loggerOptions) { o.writer = w }
+}
+
+// WithLogFields 追加日志公共字段(key, value成对出现)
+func WithLogFields(kv ...interface{}) LoggerOption {
+	return func(o *loggerOptions) { o.fields = append(o.fields, kv...) }
+}
+
+// WithLogBackend 设置日志后端(std、zap)，默认std
+func WithLogBackend(backend string) LoggerOption {
+	return func(o *loggerOptions) { o.backend = backend }
+}
+
+// WithLogFormat 设置日志格式(text、json)，默认text
+func WithLogFormat(format string) LoggerOption {
+	return func(o *loggerOptions) { o.format = format }
+}
+
+// WithLogLevel 设置初始日志级别，默认debug，配置 log.level 存在时以配置为准
+func WithLogLevel(level string) LoggerOption {
+	return func(o *loggerOptions) { o.level = level }
+}
+
+// WithLogSampling 开启日志采样：每秒同一消息前first条全部输出，之后每thereafter条输出1条
+func WithLogSampling(first, thereafter int) LoggerOption {
+	return func(o *loggerOptions) {
+		o.sampleFirst = first
+		o.sampleThereafter = thereafter
+	}
+}
+
+// newLogger 创建带服务信息和链路字段的日志，同时返回可调整级别的底层日志和刷新缓冲区的函数
+func (a *app) newLogger() (log.Logger, *LevelLogger, func() error) {
+	o := loggerOptions{writer: os.Stdout, backend: LogBackendStd, format: LogFormatText}
+	for _, opt := range a.loggerOpts {
+		opt(&o)
+	}
+
+	kv := []interface{}{
+		"ts", log.DefaultTimestamp,
+		"caller", log.DefaultCaller,
+		"service.id", a.id,
+		"service.name", a.name,
+		"service.version", a.version,
+
+		"trace.id", tracing.TraceID(),
+		"span.id", tracing.SpanID(),
+		"request.id", RequestIDValuer(),
+		"tenant.id", TenantIDValuer(),
+	}
+	kv = append(kv, o.fields...)
+
+	var base log.Logger
+	switch o.backend {
+	case LogBackendZap:
+		base = NewZapLogger(o.writer, o.format == LogFormatJSON)
+	case LogBackendStd:
+		if o.format == LogFormatJSON {
+			base = NewJSONLogger(o.writer)
+			break
+		}
+		fallthrough
+	default:
+		base = log.NewStdLogger(o.writer)
+	}
+
+	flush := func() error { return nil }
+	if s, ok := base.(interface{ Sync() error }); ok {
+		flush = s.Sync
+	}
+
+	if a.sentry != nil {
+		base = NewSentryLogger(base)
+	}
+
+	if o.sampleFirst > 0 {
+		base = NewSamplingLogger(base, o.sampleFirst, o.sampleThereafter)
+	}
+
+	initial := log.LevelDebug
+	if o.level != "" {
+		initial = log.ParseLevel(o.level)
+	}
+	level := NewLevelLogger(base, initial)
+	return log.With(level, kv...), level, flush
+}
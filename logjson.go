@@ -0,0 +1,62 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var _ log.Logger = (*jsonLogger)(nil)
+
+// jsonLogger 每条日志输出一行JSON，所有字段(服务信息、链路ID等)均为顶层键
+type jsonLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLogger 创建JSON格式的kratos日志
+func NewJSONLogger(w io.Writer) log.Logger {
+	return &jsonLogger{w: w}
+}
+
+// Log 实现 log.Logger
+func (l *jsonLogger) Log(level log.Level, keyvals ...interface{}) error {
+	if len(keyvals) == 0 {
+		return nil
+	}
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+
+	entry := make(map[string]interface{}, len(keyvals)/2+1)
+	entry[log.LevelKey] = level.String()
+	for i := 0; i < len(keyvals); i += 2 {
+		entry[fmt.Sprint(keyvals[i])] = jsonValue(keyvals[i+1])
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(data)
+	return err
+}
+
+// jsonValue 将无法直接序列化的值转换为字符串
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
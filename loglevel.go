@@ -0,0 +1,134 @@
+package common
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	// 日志级别配置键
+	LogLevelKey = "log.level"
+
+	// 管理端口上的日志级别接口路径
+	DefaultLogLevelPath = "/debug/loglevel"
+)
+
+var _ log.Logger = (*LevelLogger)(nil)
+
+// LevelLogger 可在运行时调整级别的日志过滤器
+type LevelLogger struct {
+	logger log.Logger
+	level  atomic.Int32
+}
+
+// NewLevelLogger 创建级别过滤日志，初始级别为level
+func NewLevelLogger(logger log.Logger, level log.Level) *LevelLogger {
+	l := &LevelLogger{logger: logger}
+	l.level.Store(int32(level))
+	return l
+}
+
+// Log 实现 log.Logger，低于当前级别的日志被丢弃
+func (l *LevelLogger) Log(level log.Level, keyvals ...interface{}) error {
+	if level < l.Level() {
+		return nil
+	}
+	return l.logger.Log(level, keyvals...)
+}
+
+// Level 当前日志级别
+func (l *LevelLogger) Level() log.Level {
+	return log.Level(l.level.Load())
+}
+
+// SetLevel 设置日志级别
+func (l *LevelLogger) SetLevel(level log.Level) {
+	l.level.Store(int32(level))
+}
+
+// WatchLevel 从配置读取 log.level 并在配置变化时更新日志级别
+func (l *LevelLogger) WatchLevel(c config.Config) error {
+	if s, err := c.Value(LogLevelKey).String(); err == nil {
+		l.SetLevel(log.ParseLevel(s))
+	}
+
+	err := c.Watch(LogLevelKey, func(_ string, v config.Value) {
+		s, err := v.String()
+		if err != nil {
+			return
+		}
+		old := l.Level()
+		l.SetLevel(log.ParseLevel(s))
+		_ = l.logger.Log(log.LevelInfo, log.DefaultMessageKey, "日志级别已更新: "+old.String()+" -> "+l.Level().String())
+	})
+	if err == config.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// ParseLogLevel 解析日志级别(debug、info、warn、error、fatal，不区分大小写)，无效时返回错误
+func ParseLogLevel(s string) (log.Level, error) {
+	level := log.ParseLevel(s)
+	if level.String() != strings.ToUpper(strings.TrimSpace(s)) {
+		return level, fmt.Errorf("无效的日志级别: %s", s)
+	}
+	return level, nil
+}
+
+// Handler 查询和修改日志级别的HTTP接口
+// GET 返回 {"level":"INFO"}；PUT/POST 通过参数 level 或JSON请求体 {"level":"debug"} 修改级别。
+// 修改需要 Authorization: Bearer <token>，token为空时禁止修改。
+// 本地修改立即生效，之后配置 log.level 变化时仍以配置为准
+func (l *LevelLogger) Handler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			if token == "" {
+				http.Error(w, "未配置令牌，禁止修改日志级别", http.StatusForbidden)
+				return
+			}
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			s := r.URL.Query().Get("level")
+			if s == "" {
+				var body struct {
+					Level string `json:"level"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					http.Error(w, "缺少日志级别参数", http.StatusBadRequest)
+					return
+				}
+				s = body.Level
+			}
+			level, err := ParseLogLevel(s)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			old := l.Level()
+			l.SetLevel(level)
+			_ = l.logger.Log(log.LevelInfo, log.DefaultMessageKey, "日志级别已通过管理接口更新: "+old.String()+" -> "+level.String())
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"level": l.Level().String()})
+	})
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var _ log.Logger = (*SamplingLogger)(nil)
+
+// SamplingLogger 日志采样器
+// 每秒内同一级别、同一消息的前first条全部输出，之后每thereafter条输出1条
+type SamplingLogger struct {
+	logger     log.Logger
+	first      uint64
+	thereafter uint64
+
+	mu     sync.Mutex
+	window int64
+	counts map[string]uint64
+}
+
+// NewSamplingLogger 创建日志采样器，thereafter<=0时超出first的日志全部丢弃
+func NewSamplingLogger(logger log.Logger, first, thereafter int) *SamplingLogger {
+	if first < 0 {
+		first = 0
+	}
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	return &SamplingLogger{
+		logger:     logger,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		counts:     make(map[string]uint64),
+	}
+}
+
+// Log 实现 log.Logger
+func (l *SamplingLogger) Log(level log.Level, keyvals ...interface{}) error {
+	if !l.allow(level, samplingKey(keyvals)) {
+		return nil
+	}
+	return l.logger.Log(level, keyvals...)
+}
+
+// allow 判断本条日志是否需要输出
+func (l *SamplingLogger) allow(level log.Level, msg string) bool {
+	now := time.Now().Unix()
+	key := level.String() + "|" + msg
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 进入新的一秒时重置计数，保证内存占用有界
+	if now != l.window {
+		l.window = now
+		l.counts = make(map[string]uint64, len(l.counts))
+	}
+
+	l.counts[key]++
+	n := l.counts[key]
+	if n <= l.first {
+		return true
+	}
+	if l.thereafter == 0 {
+		return false
+	}
+	return (n-l.first)%l.thereafter == 0
+}
+
+// samplingKey 取日志消息作为采样键
+func samplingKey(keyvals []interface{}) string {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if k, ok := keyvals[i].(string); ok && k == log.DefaultMessageKey {
+			return fmt.Sprint(keyvals[i+1])
+		}
+	}
+	return ""
+}
@@ -1,23 +1,56 @@
 package common
 
 import (
+	"context"
+	"errors"
+	"sync"
+
 	"github.com/go-kratos/kratos/v2/middleware/metrics"
-	"go.opentelemetry.io/otel/exporters/prometheus"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 )
 
+const (
+	// 服务端在途请求数指标名
+	ServerInFlightName = "server_requests_in_flight"
+
+	// 服务端业务错误码计数指标名
+	ServerErrorCodesName = "server_requests_error_code_total"
+)
+
 type Metrics struct {
 	Resquests metric.Int64Counter
 	Seconds   metric.Float64Histogram
+	InFlight  metric.Int64UpDownCounter // 正在处理的请求数
+	ErrCodes  metric.Int64Counter       // 按业务错误码统计的失败请求数
+
+	ClientRequests metric.Int64Counter
+	ClientSeconds  metric.Float64Histogram
+
+	provider     *sdkmetric.MeterProvider
+	registry     *promclient.Registry
+	meter        metric.Meter
+	pusher       *push.Pusher
+	stopPush     func()
+	mu           sync.Mutex
+	instruments  map[string]interface{}
+	shutdownOnce sync.Once
+	shutdownErr  error
 }
 
 func NewMetrics(appName string) (*Metrics, error) {
-	exporter, err := prometheus.New()
+	return NewMetricsWithConfig(appName, MetricsConfig{})
+}
+
+// NewMetricsWithConfig 按配置选择指标导出方式创建指标
+func NewMetricsWithConfig(appName string, cfg MetricsConfig) (*Metrics, error) {
+	reader, registry, err := newMetricReader(context.Background(), cfg)
 	if err != nil {
 		return nil, err
 	}
-	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter))
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
 	meter := provider.Meter(appName)
 
 	requst, err := metrics.DefaultRequestsCounter(meter, metrics.DefaultServerRequestsCounterName)
@@ -29,8 +62,91 @@ func NewMetrics(appName string) (*Metrics, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	inFlight, err := meter.Int64UpDownCounter(
+		ServerInFlightName,
+		metric.WithUnit("{call}"),
+		metric.WithDescription("当前正在处理的服务端请求数"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	errCodes, err := meter.Int64Counter(
+		ServerErrorCodesName,
+		metric.WithUnit("{call}"),
+		metric.WithDescription("按业务错误码统计的服务端失败请求数，class区分client(4xx)和server(5xx)"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	clientRequests, err := metrics.DefaultRequestsCounter(meter, metrics.DefaultClientRequestsCounterName)
+	if err != nil {
+		return nil, err
+	}
+
+	clientSeconds, err := metrics.DefaultSecondsHistogram(meter, metrics.DefaultClientSecondsHistogramName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registerRuntimeMetrics(meter); err != nil {
+		return nil, err
+	}
+
 	return &Metrics{
 		Resquests: requst,
 		Seconds:   seconds,
+		InFlight:  inFlight,
+		ErrCodes:  errCodes,
+
+		ClientRequests: clientRequests,
+		ClientSeconds:  clientSeconds,
+
+		provider:    provider,
+		registry:    registry,
+		meter:       meter,
+		instruments: make(map[string]interface{}),
 	}, nil
 }
+
+// Meter 共享的meter，用于创建自定义指标
+func (m *Metrics) Meter() metric.Meter {
+	return m.meter
+}
+
+// Gatherer 指标所在的Prometheus注册表，使用OTLP导出时为nil
+func (m *Metrics) Gatherer() promclient.Gatherer {
+	if m.registry == nil {
+		return nil
+	}
+	return m.registry
+}
+
+// Shutdown 刷新并关闭指标提供者，多次调用只执行一次
+// 开启了Pushgateway推送时，关闭前会做最后一次推送；刷新或推送失败时仍会关闭提供者
+func (m *Metrics) Shutdown(ctx context.Context) error {
+	m.shutdownOnce.Do(func() {
+		var errs []error
+		if err := m.provider.ForceFlush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+
+		m.mu.Lock()
+		stopPush := m.stopPush
+		m.mu.Unlock()
+		if stopPush != nil {
+			stopPush()
+			if err := m.Push(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if err := m.provider.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		m.shutdownErr = errors.Join(errs...)
+	})
+	return m.shutdownErr
+}
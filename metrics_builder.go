@@ -0,0 +1,137 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Counter 业务计数器
+type Counter struct {
+	counter metric.Float64Counter
+	labels  []string
+}
+
+// Add 累加计数，values与创建时的labels按位置对应
+func (c *Counter) Add(ctx context.Context, v float64, values ...string) {
+	c.counter.Add(ctx, v, metric.WithAttributes(labelAttrs(c.labels, values)...))
+}
+
+// Inc 计数加1
+func (c *Counter) Inc(ctx context.Context, values ...string) {
+	c.Add(ctx, 1, values...)
+}
+
+// Gauge 业务瞬时值
+type Gauge struct {
+	gauge  metric.Float64Gauge
+	labels []string
+}
+
+// Set 设置当前值，values与创建时的labels按位置对应
+func (g *Gauge) Set(ctx context.Context, v float64, values ...string) {
+	g.gauge.Record(ctx, v, metric.WithAttributes(labelAttrs(g.labels, values)...))
+}
+
+// Histogram 业务分布统计
+type Histogram struct {
+	histogram metric.Float64Histogram
+	labels    []string
+}
+
+// Observe 记录一次观测值，values与创建时的labels按位置对应
+func (h *Histogram) Observe(ctx context.Context, v float64, values ...string) {
+	h.histogram.Record(ctx, v, metric.WithAttributes(labelAttrs(h.labels, values)...))
+}
+
+// Counter 创建或获取计数器，同名指标只创建一次
+func (m *Metrics) Counter(name, desc string, labels ...string) (*Counter, error) {
+	v, err := m.instrument(name, func() (interface{}, error) {
+		c, err := m.meter.Float64Counter(name, metric.WithDescription(desc))
+		if err != nil {
+			return nil, err
+		}
+		return &Counter{counter: c, labels: labels}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c, ok := v.(*Counter)
+	if !ok {
+		return nil, fmt.Errorf("指标%s已注册为其他类型", name)
+	}
+	return c, nil
+}
+
+// Gauge 创建或获取瞬时值指标，同名指标只创建一次
+func (m *Metrics) Gauge(name, desc string, labels ...string) (*Gauge, error) {
+	v, err := m.instrument(name, func() (interface{}, error) {
+		g, err := m.meter.Float64Gauge(name, metric.WithDescription(desc))
+		if err != nil {
+			return nil, err
+		}
+		return &Gauge{gauge: g, labels: labels}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	g, ok := v.(*Gauge)
+	if !ok {
+		return nil, fmt.Errorf("指标%s已注册为其他类型", name)
+	}
+	return g, nil
+}
+
+// Histogram 创建或获取分布统计指标，buckets为空时使用默认分桶
+func (m *Metrics) Histogram(name, desc string, buckets []float64, labels ...string) (*Histogram, error) {
+	v, err := m.instrument(name, func() (interface{}, error) {
+		opts := []metric.Float64HistogramOption{metric.WithDescription(desc)}
+		if len(buckets) > 0 {
+			opts = append(opts, metric.WithExplicitBucketBoundaries(buckets...))
+		}
+		h, err := m.meter.Float64Histogram(name, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &Histogram{histogram: h, labels: labels}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	h, ok := v.(*Histogram)
+	if !ok {
+		return nil, fmt.Errorf("指标%s已注册为其他类型", name)
+	}
+	return h, nil
+}
+
+// instrument 从缓存获取指标，不存在时调用create创建
+func (m *Metrics) instrument(name string, create func() (interface{}, error)) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.instruments[name]; ok {
+		return v, nil
+	}
+	v, err := create()
+	if err != nil {
+		return nil, err
+	}
+	m.instruments[name] = v
+	return v, nil
+}
+
+// labelAttrs 将标签名和标签值组合为属性
+func labelAttrs(labels, values []string) []attribute.KeyValue {
+	n := len(labels)
+	if len(values) < n {
+		n = len(values)
+	}
+	attrs := make([]attribute.KeyValue, n)
+	for i := 0; i < n; i++ {
+		attrs[i] = attribute.String(labels[i], values[i])
+	}
+	return attrs
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const (
+	// 指标配置键
+	MetricsConfigKey = "metrics"
+
+	// 指标导出方式
+	MetricsExporterPrometheus = "prometheus"
+	MetricsExporterOTLPGRPC   = "otlp-grpc"
+	MetricsExporterOTLPHTTP   = "otlp-http"
+)
+
+// MetricsConfig 指标导出配置，对应配置中的 metrics 节点
+type MetricsConfig struct {
+	Exporter string            `json:"exporter"` // prometheus(默认)、otlp-grpc、otlp-http
+	Endpoint string            `json:"endpoint"` // OTLP上报地址
+	URLPath  string            `json:"url_path"` // otlp-http上报路径，默认 /v1/metrics
+	Insecure bool              `json:"insecure"` // 是否使用明文连接
+	Headers  map[string]string `json:"headers"`  // 上报时附带的请求头
+	Interval Duration          `json:"interval"` // OTLP上报间隔，默认60s
+}
+
+// newMetricReader 根据导出方式创建指标读取器，prometheus方式同时返回注册表
+func newMetricReader(ctx context.Context, cfg MetricsConfig) (sdkmetric.Reader, *promclient.Registry, error) {
+	var periodic []sdkmetric.PeriodicReaderOption
+	if cfg.Interval > 0 {
+		periodic = append(periodic, sdkmetric.WithInterval(cfg.Interval.Std()))
+	}
+
+	switch cfg.Exporter {
+	case "", MetricsExporterPrometheus:
+		// 使用独立的注册表，避免与默认注册表中的Go/进程采集器重名
+		registry := promclient.NewRegistry()
+		exporter, err := prometheus.New(prometheus.WithRegisterer(registry))
+		if err != nil {
+			return nil, nil, err
+		}
+		return exporter, registry, nil
+
+	case MetricsExporterOTLPGRPC:
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(cfg.Endpoint),
+			otlpmetricgrpc.WithHeaders(cfg.Headers),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		exporter, err := otlpmetricgrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sdkmetric.NewPeriodicReader(exporter, periodic...), nil, nil
+
+	case MetricsExporterOTLPHTTP:
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(cfg.Endpoint),
+			otlpmetrichttp.WithHeaders(cfg.Headers),
+		}
+		if cfg.URLPath != "" {
+			opts = append(opts, otlpmetrichttp.WithURLPath(cfg.URLPath))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		exporter, err := otlpmetrichttp.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sdkmetric.NewPeriodicReader(exporter, periodic...), nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("不支持的指标导出方式: %s", cfg.Exporter)
+	}
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// 默认指标路径
+	DefaultMetricsPath = "/metrics"
+)
+
+// Handler 返回Prometheus指标HTTP处理器，使用OTLP导出时返回404
+func (m *Metrics) Handler() http.Handler {
+	if m.registry == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Register 将指标挂载到kratos HTTP服务器的 /metrics
+func (m *Metrics) Register(srv *khttp.Server) {
+	srv.Handle(DefaultMetricsPath, m.Handler())
+}
+
+// NewServer 创建独立端口的指标服务，可直接作为 kratos.Server 使用
+func (m *Metrics) NewServer(addr string) *MetricsServer {
+	mux := http.NewServeMux()
+	mux.Handle(DefaultMetricsPath, m.Handler())
+	return &MetricsServer{
+		srv: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+var _ transport.Server = (*MetricsServer)(nil)
+
+// MetricsServer 独立端口的指标服务
+type MetricsServer struct {
+	srv *http.Server
+}
+
+// Start 启动指标服务，阻塞直到服务停止
+func (s *MetricsServer) Start(_ context.Context) error {
+	ln, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+	if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Stop 停止指标服务
+func (s *MetricsServer) Stop(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
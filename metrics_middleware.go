@@ -0,0 +1,99 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/metrics"
+	"github.com/go-kratos/kratos/v2/transport"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/lnhlg/gbm-common/response"
+)
+
+// Server 服务端指标中间件，记录请求数、耗时、在途请求数和失败请求的业务错误码
+func (m *Metrics) Server() middleware.Middleware {
+	return middleware.Chain(
+		metrics.Server(
+			metrics.WithRequests(m.Resquests),
+			metrics.WithSeconds(m.Seconds),
+		),
+		m.serverCodes,
+	)
+}
+
+// serverCodes 统计在途请求数，请求失败时按业务错误码计数
+func (m *Metrics) serverCodes(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		var kind, operation string
+		if info, ok := transport.FromServerContext(ctx); ok {
+			kind = info.Kind().String()
+			operation = info.Operation()
+		}
+
+		attrs := metric.WithAttributes(
+			attribute.String("kind", kind),
+			attribute.String("operation", operation),
+		)
+		m.InFlight.Add(ctx, 1, attrs)
+		defer m.InFlight.Add(ctx, -1, attrs)
+
+		reply, err := handler(ctx, req)
+
+		if err != nil {
+			code := response.CodeOf(err)
+			class := "server"
+			if status := code.HTTPStatus(); status >= 400 && status < 500 {
+				class = "client"
+			}
+			m.ErrCodes.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("kind", kind),
+				attribute.String("operation", operation),
+				attribute.Int("code", int(code)),
+				attribute.String("reason", errors.Reason(err)),
+				attribute.String("class", class),
+			))
+		}
+		return reply, err
+	}
+}
+
+// Client 客户端指标中间件，按目标服务记录出站请求数和耗时
+func (m *Metrics) Client(target string) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var kind, operation string
+			if info, ok := transport.FromClientContext(ctx); ok {
+				kind = info.Kind().String()
+				operation = info.Operation()
+			}
+
+			start := time.Now()
+			reply, err := handler(ctx, req)
+
+			code, reason := 200, ""
+			if se := errors.FromError(err); se != nil {
+				code = int(se.Code)
+				reason = se.Reason
+			}
+
+			m.ClientRequests.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("target", target),
+				attribute.String("kind", kind),
+				attribute.String("operation", operation),
+				attribute.Int("code", code),
+				attribute.String("reason", reason),
+			))
+			m.ClientSeconds.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+				attribute.String("target", target),
+				attribute.String("kind", kind),
+				attribute.String("operation", operation),
+			))
+
+			return reply, err
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const (
+	// Pushgateway推送配置键
+	MetricsPushConfigKey = "metrics.push"
+)
+
+// PushConfig Pushgateway推送配置，对应配置中的 metrics.push 节点
+type PushConfig struct {
+	URL      string   `json:"url"`      // Pushgateway地址
+	Job      string   `json:"job"`      // job名称
+	Instance string   `json:"instance"` // instance分组标签，可为空
+	Interval Duration `json:"interval"` // 推送间隔，0表示只在关闭时推送
+}
+
+// StartPush 开启Pushgateway推送：按间隔推送，并在Shutdown时做最后一次推送
+// 适用于执行完即退出、来不及被Prometheus抓取的批处理任务，仅支持prometheus导出方式
+func (m *Metrics) StartPush(cfg PushConfig) {
+	if m.registry == nil {
+		return
+	}
+
+	pusher := push.New(cfg.URL, cfg.Job).Gatherer(m.registry)
+	if cfg.Instance != "" {
+		pusher = pusher.Grouping("instance", cfg.Instance)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	m.mu.Lock()
+	m.pusher = pusher
+	m.stopPush = func() {
+		cancel()
+		<-done
+	}
+	m.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		if cfg.Interval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(cfg.Interval.Std())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = pusher.PushContext(ctx)
+			}
+		}
+	}()
+}
+
+// Push 立即推送一次当前指标
+func (m *Metrics) Push(ctx context.Context) error {
+	m.mu.Lock()
+	pusher := m.pusher
+	m.mu.Unlock()
+
+	if pusher == nil {
+		return nil
+	}
+	return pusher.PushContext(ctx)
+}
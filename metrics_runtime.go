@@ -0,0 +1,121 @@
+package common
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// /proc/self/stat 中CPU时间的单位(USER_HZ)
+	clockTicksPerSecond = 100
+)
+
+// registerRuntimeMetrics 在meter上注册Go运行时指标和进程指标
+// 进程指标读取 /proc/self，非Linux系统上只上报运行时指标
+func registerRuntimeMetrics(meter metric.Meter) error {
+	goroutines, err := meter.Int64ObservableGauge("go_goroutines",
+		metric.WithDescription("当前goroutine数量"))
+	if err != nil {
+		return err
+	}
+	heapAlloc, err := meter.Int64ObservableGauge("go_memstats_heap_alloc_bytes",
+		metric.WithDescription("堆上已分配且仍在使用的字节数"), metric.WithUnit("By"))
+	if err != nil {
+		return err
+	}
+	heapSys, err := meter.Int64ObservableGauge("go_memstats_heap_sys_bytes",
+		metric.WithDescription("从操作系统获得的堆内存字节数"), metric.WithUnit("By"))
+	if err != nil {
+		return err
+	}
+	gcCount, err := meter.Int64ObservableCounter("go_gc_count",
+		metric.WithDescription("已完成的GC次数"))
+	if err != nil {
+		return err
+	}
+	gcPause, err := meter.Float64ObservableCounter("go_gc_pause_seconds_total",
+		metric.WithDescription("GC累计暂停时间"), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	cpuSeconds, err := meter.Float64ObservableCounter("process_cpu_seconds_total",
+		metric.WithDescription("进程累计CPU时间(用户态+内核态)"), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	rss, err := meter.Int64ObservableGauge("process_resident_memory_bytes",
+		metric.WithDescription("进程常驻内存"), metric.WithUnit("By"))
+	if err != nil {
+		return err
+	}
+	fds, err := meter.Int64ObservableGauge("process_open_fds",
+		metric.WithDescription("进程打开的文件描述符数量"))
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+
+		o.ObserveInt64(goroutines, int64(runtime.NumGoroutine()))
+		o.ObserveInt64(heapAlloc, int64(ms.HeapAlloc))
+		o.ObserveInt64(heapSys, int64(ms.HeapSys))
+		o.ObserveInt64(gcCount, int64(ms.NumGC))
+		o.ObserveFloat64(gcPause, float64(ms.PauseTotalNs)/1e9)
+
+		if cpu, mem, ok := readProcStat(); ok {
+			o.ObserveFloat64(cpuSeconds, cpu)
+			o.ObserveInt64(rss, mem)
+		}
+		if n, ok := countOpenFDs(); ok {
+			o.ObserveInt64(fds, n)
+		}
+		return nil
+	}, goroutines, heapAlloc, heapSys, gcCount, gcPause, cpuSeconds, rss, fds)
+
+	return err
+}
+
+// readProcStat 读取进程CPU时间(秒)和常驻内存(字节)
+func readProcStat() (float64, int64, bool) {
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, 0, false
+	}
+
+	// 进程名可能包含空格，从最后一个')'之后开始解析
+	s := string(data)
+	idx := strings.LastIndexByte(s, ')')
+	if idx < 0 {
+		return 0, 0, false
+	}
+	fields := strings.Fields(s[idx+1:])
+	// fields[0]为第3个字段(state)，utime/stime/rss分别为第14/15/24个字段
+	if len(fields) < 22 {
+		return 0, 0, false
+	}
+
+	utime, err1 := strconv.ParseFloat(fields[11], 64)
+	stime, err2 := strconv.ParseFloat(fields[12], 64)
+	pages, err3 := strconv.ParseInt(fields[21], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, false
+	}
+
+	return (utime + stime) / clockTicksPerSecond, pages * int64(os.Getpagesize()), true
+}
+
+// countOpenFDs 统计进程打开的文件描述符数量
+func countOpenFDs() (int64, bool) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	return int64(len(entries)), true
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"github.com/getsentry/sentry-go"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/logging"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/lnhlg/gbm-common/i18n"
+)
+
+// DefaultMiddlewares 服务端推荐的标准中间件组合
+// 顺序为: recovery -> request id -> i18n -> tracing -> metrics -> logging -> validate
+// i18n位于日志外层，日志记录原文，返回给调用方的错误信息按 Accept-Language 翻译；
+// metrics为nil时跳过指标中间件，tracer为nil时使用全局TracerProvider；
+// validate同时支持 Validate() error 和 validate 结构体标签，失败时返回 response.ValidationFailed；
+// 已初始化Sentry时panic会同时上报到Sentry
+func DefaultMiddlewares(logger log.Logger, metrics *Metrics, tracer *sdktrace.TracerProvider) []middleware.Middleware {
+	var recoveryOpts []recovery.Option
+	if sentry.CurrentHub().Client() != nil {
+		recoveryOpts = append(recoveryOpts, recovery.WithHandler(SentryRecoveryHandler()))
+	}
+
+	var tracingOpts []tracing.Option
+	if tracer != nil {
+		tracingOpts = append(tracingOpts, tracing.WithTracerProvider(tracer))
+	}
+
+	mws := []middleware.Middleware{
+		recovery.Recovery(recoveryOpts...),
+		RequestID(),
+		ContextMetadata(),
+		i18n.Server(),
+		tracing.Server(tracingOpts...),
+	}
+	if metrics != nil {
+		mws = append(mws, metrics.Server())
+	}
+	return append(mws,
+		logging.Server(logger),
+		ValidateRequest(),
+	)
+}
@@ -0,0 +1,240 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	// MQTT配置键
+	MQTTConfigKey = "mqtt"
+
+	// 默认心跳间隔
+	DefaultMQTTKeepAlive = 30 * time.Second
+
+	// 默认最大重连间隔
+	DefaultMQTTMaxReconnect = 30 * time.Second
+)
+
+// MQTTTLS MQTT证书配置
+type MQTTTLS struct {
+	CAFile     string `json:"ca_file"`
+	CertFile   string `json:"cert_file"` // 客户端证书，双向认证时使用
+	KeyFile    string `json:"key_file"`
+	SkipVerify bool   `json:"skip_verify"`
+}
+
+// MQTTConfig MQTT配置，对应配置中的 mqtt 节点
+type MQTTConfig struct {
+	Brokers      []string `json:"brokers"` // 如 tcp://127.0.0.1:1883、ssl://broker:8883
+	ClientID     string   `json:"client_id"`
+	Username     string   `json:"username"`
+	Password     string   `json:"password"`
+	QoS          byte     `json:"qos"`           // 订阅和发布的默认QoS(0/1/2)
+	CleanSession bool     `json:"clean_session"` // 为true时重连后自动重新订阅
+	KeepAlive    Duration `json:"keep_alive"`    // 默认30s
+	MaxReconnect Duration `json:"max_reconnect"` // 最大重连间隔，默认30s
+	TLS          *MQTTTLS `json:"tls"`
+}
+
+// MQTTHandler 消息处理函数
+type MQTTHandler func(ctx context.Context, topic string, payload []byte) error
+
+// MQTTClient 自动重连的MQTT客户端，按主题过滤器注册处理函数，重连后自动恢复订阅
+type MQTTClient struct {
+	client mqtt.Client
+	qos    byte
+	logger *log.Helper
+
+	mu     sync.RWMutex
+	routes map[string]MQTTHandler
+
+	received  *Counter
+	published *Counter
+	failed    *Counter
+}
+
+// NewMQTTClient 创建MQTT客户端，metrics为nil时不记录指标，调用Connect后开始连接
+func NewMQTTClient(cfg MQTTConfig, logger log.Logger, metrics *Metrics) (*MQTTClient, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("MQTT broker地址不能为空")
+	}
+	if logger == nil {
+		logger = log.GetLogger()
+	}
+
+	c := &MQTTClient{
+		qos:    cfg.QoS,
+		logger: log.NewHelper(logger),
+		routes: make(map[string]MQTTHandler),
+	}
+	if metrics != nil {
+		var err error
+		if c.received, err = metrics.Counter("mqtt_messages_received_total", "收到的MQTT消息数", "filter"); err != nil {
+			return nil, err
+		}
+		if c.published, err = metrics.Counter("mqtt_messages_published_total", "发布的MQTT消息数", "result"); err != nil {
+			return nil, err
+		}
+		if c.failed, err = metrics.Counter("mqtt_handler_errors_total", "处理失败的MQTT消息数", "filter"); err != nil {
+			return nil, err
+		}
+	}
+
+	keepAlive := cfg.KeepAlive.Std()
+	if keepAlive <= 0 {
+		keepAlive = DefaultMQTTKeepAlive
+	}
+	maxReconnect := cfg.MaxReconnect.Std()
+	if maxReconnect <= 0 {
+		maxReconnect = DefaultMQTTMaxReconnect
+	}
+
+	opts := mqtt.NewClientOptions().
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetCleanSession(cfg.CleanSession).
+		SetKeepAlive(keepAlive).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetMaxReconnectInterval(maxReconnect).
+		SetOrderMatters(false).
+		SetOnConnectHandler(c.onConnect).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			c.logger.Warnf("MQTT连接断开: %v", err)
+		})
+	for _, b := range cfg.Brokers {
+		opts.AddBroker(b)
+	}
+	if cfg.TLS != nil {
+		tc, err := cfg.TLS.Config()
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tc)
+	}
+
+	c.client = mqtt.NewClient(opts)
+	return c, nil
+}
+
+// Connect 连接broker，broker不可用时在后台持续重试直到ctx取消
+func (c *MQTTClient) Connect(ctx context.Context) error {
+	return waitToken(ctx, c.client.Connect())
+}
+
+// Handle 注册主题过滤器(支持+和#通配符)的处理函数，已连接时立即订阅
+func (c *MQTTClient) Handle(filter string, handler MQTTHandler) error {
+	c.mu.Lock()
+	c.routes[filter] = handler
+	c.mu.Unlock()
+
+	if !c.client.IsConnectionOpen() {
+		return nil
+	}
+	return waitToken(context.Background(), c.client.Subscribe(filter, c.qos, c.dispatch(filter, handler)))
+}
+
+// Publish 按默认QoS发布消息
+func (c *MQTTClient) Publish(ctx context.Context, topic string, payload []byte, retained bool) error {
+	err := waitToken(ctx, c.client.Publish(topic, c.qos, retained, payload))
+	if c.published != nil {
+		result := "ok"
+		if err != nil {
+			result = "error"
+		}
+		c.published.Inc(ctx, result)
+	}
+	return err
+}
+
+// Close 断开连接，等待最多250ms发送未完成的消息
+func (c *MQTTClient) Close() error {
+	c.client.Disconnect(250)
+	return nil
+}
+
+// onConnect 连接(含重连)成功后订阅全部主题
+func (c *MQTTClient) onConnect(client mqtt.Client) {
+	c.logger.Info("MQTT已连接")
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for filter, handler := range c.routes {
+		token := client.Subscribe(filter, c.qos, c.dispatch(filter, handler))
+		go func(filter string) {
+			if err := waitToken(context.Background(), token); err != nil {
+				c.logger.Errorf("订阅MQTT主题失败(%s): %v", filter, err)
+			}
+		}(filter)
+	}
+}
+
+// dispatch 包装处理函数，记录指标并恢复panic
+func (c *MQTTClient) dispatch(filter string, handler MQTTHandler) mqtt.MessageHandler {
+	return func(_ mqtt.Client, msg mqtt.Message) {
+		ctx := context.Background()
+		if c.received != nil {
+			c.received.Inc(ctx, filter)
+		}
+
+		err := func() (err error) {
+			defer func() {
+				if e := recover(); e != nil {
+					err = fmt.Errorf("panic: %v", e)
+				}
+			}()
+			return handler(ctx, msg.Topic(), msg.Payload())
+		}()
+		if err != nil {
+			c.logger.Errorf("处理MQTT消息失败(%s): %v", msg.Topic(), err)
+			if c.failed != nil {
+				c.failed.Inc(ctx, filter)
+			}
+		}
+	}
+}
+
+// Config 生成TLS配置
+func (t *MQTTTLS) Config() (*tls.Config, error) {
+	tc := &tls.Config{InsecureSkipVerify: t.SkipVerify, MinVersion: tls.VersionTLS12}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("无效的CA证书")
+		}
+		tc.RootCAs = pool
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
+
+// waitToken 等待MQTT操作完成或ctx取消
+func waitToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,172 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const (
+	// 可选注册中心注册失败后的默认重试间隔
+	DefaultRegisterRetryInterval = 10 * time.Second
+)
+
+var _ registry.Registrar = (*MultiRegistrar)(nil)
+
+// RegistrarBackend 参与扇出注册的注册中心
+type RegistrarBackend struct {
+	Name      string             // 名称，用于日志
+	Registrar registry.Registrar // 注册器
+	Optional  bool               // 可选的注册中心注册失败不影响启动，后台重试
+}
+
+// MultiRegistrar 同时向多个注册中心注册实例的注册器
+// - 必需的注册中心任一注册失败时，回滚已成功的注册并返回错误
+// - 可选的注册中心注册失败时记录日志，后台按间隔重试直到成功或注销
+// - Deregister 从所有已注册的注册中心注销，汇总各自的错误
+type MultiRegistrar struct {
+	backends []RegistrarBackend
+	logger   *log.Helper
+	retry    time.Duration
+
+	mu         sync.Mutex
+	registered map[int]bool
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewMultiRegistrar 创建扇出注册器，backends按顺序注册，逆序注销
+func NewMultiRegistrar(logger log.Logger, backends ...RegistrarBackend) *MultiRegistrar {
+	return &MultiRegistrar{
+		backends:   backends,
+		logger:     log.NewHelper(logger),
+		retry:      DefaultRegisterRetryInterval,
+		registered: make(map[int]bool, len(backends)),
+	}
+}
+
+// WithRetryInterval 设置可选注册中心的重试间隔
+func (m *MultiRegistrar) WithRetryInterval(d time.Duration) *MultiRegistrar {
+	m.retry = d
+	return m
+}
+
+// Register 向所有注册中心注册
+func (m *MultiRegistrar) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pending []int
+	for i, b := range m.backends {
+		if m.registered[i] {
+			continue
+		}
+		err := b.Registrar.Register(ctx, service)
+		if err == nil {
+			m.registered[i] = true
+			continue
+		}
+		if b.Optional {
+			m.logger.Warnf("注册到%s失败，稍后重试: %v", b.Name, err)
+			pending = append(pending, i)
+			continue
+		}
+
+		err = fmt.Errorf("注册到%s失败: %w", b.Name, err)
+		if rerr := m.deregisterLocked(ctx, service); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("回滚注册失败: %w", rerr))
+		}
+		return err
+	}
+
+	if len(pending) > 0 {
+		rctx, cancel := context.WithCancel(context.Background())
+		m.cancel = cancel
+		for _, i := range pending {
+			m.wg.Add(1)
+			go m.retryRegister(rctx, i, service)
+		}
+	}
+	return nil
+}
+
+// Deregister 停止后台重试并从所有已注册的注册中心注销
+func (m *MultiRegistrar) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	m.mu.Lock()
+	cancel := m.cancel
+	m.cancel = nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		m.wg.Wait()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deregisterLocked(ctx, service)
+}
+
+// Registered 已成功注册的注册中心名称
+func (m *MultiRegistrar) Registered() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.registered))
+	for i, b := range m.backends {
+		if m.registered[i] {
+			names = append(names, b.Name)
+		}
+	}
+	return names
+}
+
+// deregisterLocked 逆序注销已注册的注册中心，调用方需持有锁
+func (m *MultiRegistrar) deregisterLocked(ctx context.Context, service *registry.ServiceInstance) error {
+	var errs []error
+	for i := len(m.backends) - 1; i >= 0; i-- {
+		if !m.registered[i] {
+			continue
+		}
+		b := m.backends[i]
+		if err := b.Registrar.Deregister(ctx, service); err != nil {
+			errs = append(errs, fmt.Errorf("从%s注销失败: %w", b.Name, err))
+			continue
+		}
+		delete(m.registered, i)
+	}
+	return errors.Join(errs...)
+}
+
+// retryRegister 后台重试可选注册中心的注册
+func (m *MultiRegistrar) retryRegister(ctx context.Context, i int, service *registry.ServiceInstance) {
+	defer m.wg.Done()
+
+	b := m.backends[i]
+	ticker := time.NewTicker(m.retry)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := b.Registrar.Register(ctx, service); err != nil {
+			m.logger.Warnf("重试注册到%s失败: %v", b.Name, err)
+			continue
+		}
+
+		m.mu.Lock()
+		m.registered[i] = true
+		m.mu.Unlock()
+		m.logger.Infof("已注册到%s", b.Name)
+		return
+	}
+}
@@ -1,23 +1,61 @@
 package common
 
 import (
+	"net/http"
+	"sync"
+	"time"
+
 	nacosconfig "github.com/go-kratos/kratos/contrib/config/nacos/v2"
 	"github.com/go-kratos/kratos/contrib/registry/nacos/v2"
 	kconfig "github.com/go-kratos/kratos/v2/config"
-	"github.com/nacos-group/nacos-sdk-go/clients"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/lnhlg/gbm-common/i18n"
+	"github.com/nacos-group/nacos-sdk-go/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/clients/nacos_client"
 	"github.com/nacos-group/nacos-sdk-go/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/common/http_agent"
 	"github.com/nacos-group/nacos-sdk-go/vo"
 )
 
+const (
+	// 默认请求超时时间
+	DefaultNacosTimeout = 5 * time.Second
+
+	// 默认日志目录
+	DefaultNacosLogDir = "./tmp/nacos/log"
+
+	// 默认缓存目录
+	DefaultNacosCacheDir = "./tmp/nacos/cache"
+
+	// 默认日志级别
+	DefaultNacosLogLevel = "debug"
+)
+
 type NacosCfgSource struct {
-	sc       []constant.ServerConfig
-	userName string
-	password string
+	mu                  sync.RWMutex
+	sc                  []constant.ServerConfig
+	userName            string
+	password            string
+	endpoint            string
+	tls                 *NacosTLS
+	transport           http.RoundTripper // 按tls设置创建的专用传输层，nil表示尚未创建
+	timeout             time.Duration
+	logDir              string
+	cacheDir            string
+	logLevel            string
+	notLoadCacheAtStart bool
+
+	clientMu      sync.Mutex                             // 保护configClients和retiredClients，创建客户端期间持有
+	configClients map[string]config_client.IConfigClient // 按命名空间缓存的配置客户端
+	// 连接设置变化后不再复用的客户端，已创建的配置源仍在使用，Close时关闭
+	retiredClients []config_client.IConfigClient
 }
 
 type NacosHost struct {
-	Host string
-	Port uint64
+	Host        string
+	Port        uint64
+	Scheme      string // http或https，为空时使用http
+	ContextPath string // 服务上下文路径，为空时使用/nacos
 }
 
 // 修改 NewNacosCfgSource 函数，直接接收服务器配置、用户名和密码
@@ -26,38 +64,111 @@ func NewNacosCfgSource(
 	userName,
 	password string,
 ) *NacosCfgSource {
-	sc := make([]constant.ServerConfig, len(svs))
-	for i, s := range svs {
-		sc[i] = constant.ServerConfig{
-			IpAddr: s.Host,
-			Port:   s.Port,
-		}
-	}
 	return &NacosCfgSource{
-		sc:       sc,
-		userName: userName,
-		password: password,
+		sc:                  serverConfigs(svs),
+		userName:            userName,
+		password:            password,
+		timeout:             DefaultNacosTimeout,
+		logDir:              DefaultNacosLogDir,
+		cacheDir:            DefaultNacosCacheDir,
+		logLevel:            DefaultNacosLogLevel,
+		notLoadCacheAtStart: true,
 	}
 }
 
-func (nfs *NacosCfgSource) NacosSource(namespaceid, dataid, group string) (kconfig.Source, error) {
-	cc := &constant.ClientConfig{
-		NamespaceId:         namespaceid, //namespace id
-		TimeoutMs:           5000,
-		NotLoadCacheAtStart: true,
-		LogDir:              "./tmp/nacos/log",
-		CacheDir:            "./tmp/nacos/cache",
-		LogLevel:            "debug",
-		Username:            nfs.userName,
-		Password:            nfs.password,
+// WithEndpoint 设置地址服务器，从endpoint动态获取Nacos服务器列表
+func (nfs *NacosCfgSource) WithEndpoint(endpoint string) *NacosCfgSource {
+	nfs.endpoint = endpoint
+	return nfs
+}
+
+// WithTLS 设置https连接的TLS选项
+func (nfs *NacosCfgSource) WithTLS(t *NacosTLS) *NacosCfgSource {
+	nfs.mu.Lock()
+	nfs.tls = t
+	nfs.transport = nil
+	nfs.mu.Unlock()
+	nfs.resetConfigClients()
+	return nfs
+}
+
+// httpTransport 返回Nacos请求使用的传输层，未设置TLS时使用默认传输层
+func (nfs *NacosCfgSource) httpTransport() (http.RoundTripper, error) {
+	nfs.mu.Lock()
+	defer nfs.mu.Unlock()
+	if nfs.tls == nil {
+		return http.DefaultTransport, nil
 	}
+	if nfs.transport == nil {
+		tr, err := nfs.tls.transport()
+		if err != nil {
+			return nil, err
+		}
+		nfs.transport = tr
+	}
+	return nfs.transport, nil
+}
 
-	client, err := clients.NewConfigClient(
-		vo.NacosClientParam{
-			ClientConfig:  cc,
-			ServerConfigs: nfs.sc,
-		},
-	)
+// nacosClient 创建SDK基础客户端，TLS设置通过专用的HTTP代理生效
+func (nfs *NacosCfgSource) nacosClient(namespaceid string) (nacos_client.INacosClient, error) {
+	tr, err := nfs.httpTransport()
+	if err != nil {
+		return nil, err
+	}
+	var agent http_agent.IHttpAgent = &http_agent.HttpAgent{}
+	if tr != http.DefaultTransport {
+		agent = &nacosHTTPAgent{transport: tr}
+	}
+
+	client := &nacos_client.NacosClient{}
+	if err := client.SetClientConfig(*nfs.clientConfig(namespaceid)); err != nil {
+		return nil, err
+	}
+	servers := nfs.servers()
+	if len(servers) == 0 && nfs.endpoint == "" {
+		return nil, i18n.Errorf("未配置Nacos服务器列表或地址服务器")
+	}
+	if err := client.SetServerConfig(servers); err != nil {
+		return nil, err
+	}
+	if err := client.SetHttpAgent(agent); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// WithTimeout 设置请求超时时间
+func (nfs *NacosCfgSource) WithTimeout(timeout time.Duration) *NacosCfgSource {
+	nfs.timeout = timeout
+	return nfs
+}
+
+// WithLogDir 设置SDK日志目录
+func (nfs *NacosCfgSource) WithLogDir(dir string) *NacosCfgSource {
+	nfs.logDir = dir
+	return nfs
+}
+
+// WithCacheDir 设置SDK缓存目录
+func (nfs *NacosCfgSource) WithCacheDir(dir string) *NacosCfgSource {
+	nfs.cacheDir = dir
+	return nfs
+}
+
+// WithLogLevel 设置SDK日志级别(debug、info、warn、error)
+func (nfs *NacosCfgSource) WithLogLevel(level string) *NacosCfgSource {
+	nfs.logLevel = level
+	return nfs
+}
+
+// WithNotLoadCacheAtStart 设置启动时是否跳过加载本地缓存
+func (nfs *NacosCfgSource) WithNotLoadCacheAtStart(notLoad bool) *NacosCfgSource {
+	nfs.notLoadCacheAtStart = notLoad
+	return nfs
+}
+
+func (nfs *NacosCfgSource) NacosSource(namespaceid, dataid, group string) (kconfig.Source, error) {
+	client, err := nfs.configClient(namespaceid)
 	if err != nil {
 		return nil, err
 	}
@@ -70,24 +181,201 @@ func (nfs *NacosCfgSource) NacosSource(namespaceid, dataid, group string) (kconf
 	return source, nil
 }
 
-func (nfs *NacosCfgSource) NacosNaming(NamespaceId string) (*nacos.Registry, error) {
-	client, err := clients.NewNamingClient(
-		vo.NacosClientParam{
-			ServerConfigs: nfs.sc,
-			ClientConfig: &constant.ClientConfig{
-				NamespaceId: NamespaceId,
-				TimeoutMs:   5000,
-				Username:    nfs.userName,
-				Password:    nfs.password,
-			},
-		},
-	)
+// NacosDataID 表示一个Nacos配置项(dataId + group)
+type NacosDataID struct {
+	DataID string
+	Group  string
+}
 
+// NacosSources 为多个dataId创建配置源，共用同一个配置客户端
+// 返回的配置源顺序与参数顺序一致，加载时后面的配置覆盖前面的同名键，
+// 因此公共配置(db、redis等)应放在前面，服务自身配置放在后面
+func (nfs *NacosCfgSource) NacosSources(namespaceid string, ids ...NacosDataID) ([]kconfig.Source, error) {
+	client, err := nfs.configClient(namespaceid)
 	if err != nil {
 		return nil, err
 	}
 
-	r := nacos.New(client)
+	sources := make([]kconfig.Source, 0, len(ids))
+	for _, id := range ids {
+		sources = append(sources, nacosconfig.NewConfigSource(client,
+			nacosconfig.WithDataID(id.DataID),
+			nacosconfig.WithGroup(id.Group),
+		))
+	}
+
+	return sources, nil
+}
 
+// PublishConfig 发布(新增或更新)配置到Nacos
+// configType 为空时按yaml处理
+func (nfs *NacosCfgSource) PublishConfig(namespaceid, dataid, group, content string, configType vo.ConfigType) error {
+	client, err := nfs.configClient(namespaceid)
+	if err != nil {
+		return err
+	}
+
+	if configType == "" {
+		configType = vo.YAML
+	}
+
+	ok, err := client.PublishConfig(vo.ConfigParam{
+		DataId:  dataid,
+		Group:   group,
+		Content: content,
+		Type:    configType,
+	})
+	if err != nil {
+		return i18n.Errorf("发布配置失败: %w", err)
+	}
+	if !ok {
+		return i18n.Errorf("发布配置失败: %s/%s", group, dataid)
+	}
+
+	return nil
+}
+
+// DeleteConfig 从Nacos删除配置
+func (nfs *NacosCfgSource) DeleteConfig(namespaceid, dataid, group string) error {
+	client, err := nfs.configClient(namespaceid)
+	if err != nil {
+		return err
+	}
+
+	ok, err := client.DeleteConfig(vo.ConfigParam{
+		DataId: dataid,
+		Group:  group,
+	})
+	if err != nil {
+		return i18n.Errorf("删除配置失败: %w", err)
+	}
+	if !ok {
+		return i18n.Errorf("删除配置失败: %s/%s", group, dataid)
+	}
+
+	return nil
+}
+
+// servers 当前的服务器列表
+func (nfs *NacosCfgSource) servers() []constant.ServerConfig {
+	nfs.mu.RLock()
+	defer nfs.mu.RUnlock()
+	return nfs.sc
+}
+
+// setServers 替换服务器列表，之后创建的客户端使用新列表
+// 已缓存的配置客户端不再复用，已创建的配置源仍使用原客户端
+func (nfs *NacosCfgSource) setServers(svs []*NacosHost) {
+	sc := serverConfigs(svs)
+	nfs.mu.Lock()
+	nfs.sc = sc
+	nfs.mu.Unlock()
+	nfs.resetConfigClients()
+}
+
+// serverConfigs 转换为SDK的服务器配置
+func serverConfigs(svs []*NacosHost) []constant.ServerConfig {
+	sc := make([]constant.ServerConfig, len(svs))
+	for i, s := range svs {
+		sc[i] = constant.ServerConfig{
+			Scheme:      s.Scheme,
+			ContextPath: s.ContextPath,
+			IpAddr:      s.Host,
+			Port:        s.Port,
+		}
+	}
+	return sc
+}
+
+// clientConfig 生成Nacos客户端配置
+func (nfs *NacosCfgSource) clientConfig(namespaceid string) *constant.ClientConfig {
+	return &constant.ClientConfig{
+		NamespaceId:         namespaceid, //namespace id
+		TimeoutMs:           uint64(nfs.timeout.Milliseconds()),
+		NotLoadCacheAtStart: nfs.notLoadCacheAtStart,
+		LogDir:              nfs.logDir,
+		CacheDir:            nfs.cacheDir,
+		LogLevel:            nfs.logLevel,
+		Username:            nfs.userName,
+		Password:            nfs.password,
+		Endpoint:            nfs.endpoint,
+	}
+}
+
+// configClient 返回命名空间的配置客户端，同一命名空间只创建一次
+// SDK客户端会启动常驻的后台协程，因此不能按调用创建
+func (nfs *NacosCfgSource) configClient(namespaceid string) (config_client.IConfigClient, error) {
+	nfs.clientMu.Lock()
+	defer nfs.clientMu.Unlock()
+	if client, ok := nfs.configClients[namespaceid]; ok {
+		return client, nil
+	}
+
+	nc, err := nfs.nacosClient(namespaceid)
+	if err != nil {
+		return nil, err
+	}
+	client, err := config_client.NewConfigClient(nc)
+	if err != nil {
+		return nil, err
+	}
+	if nfs.configClients == nil {
+		nfs.configClients = make(map[string]config_client.IConfigClient)
+	}
+	nfs.configClients[namespaceid] = client
+	return client, nil
+}
+
+// resetConfigClients 清空配置客户端缓存，连接设置变化后调用
+// 旧客户端仍被已创建的配置源使用，保留到Close时关闭
+func (nfs *NacosCfgSource) resetConfigClients() {
+	nfs.clientMu.Lock()
+	defer nfs.clientMu.Unlock()
+	for _, client := range nfs.configClients {
+		nfs.retiredClients = append(nfs.retiredClients, client)
+	}
+	nfs.configClients = nil
+}
+
+// Close 关闭创建过的全部配置客户端，停止配置监听之后调用
+// SDK客户端支持 CloseClient 时停止其后台协程，不支持时只释放引用
+func (nfs *NacosCfgSource) Close() error {
+	nfs.clientMu.Lock()
+	clients := nfs.retiredClients
+	for _, client := range nfs.configClients {
+		clients = append(clients, client)
+	}
+	nfs.configClients, nfs.retiredClients = nil, nil
+	nfs.clientMu.Unlock()
+
+	for _, client := range clients {
+		if c, ok := client.(interface{ CloseClient() }); ok {
+			c.CloseClient()
+		}
+	}
+	return nil
+}
+
+func (nfs *NacosCfgSource) NacosNaming(NamespaceId string, opts ...nacos.Option) (*nacos.Registry, error) {
+	client, err := nfs.namingClient(NamespaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	r := nacos.New(client, opts...)
+
+	return r, nil
+}
+
+// NacosDiscovery 创建Nacos服务发现，用于客户端按服务名查找实例
+// group为空时使用 DEFAULT_GROUP，返回全部集群的实例
+func (nfs *NacosCfgSource) NacosDiscovery(namespaceid string, group ...string) (registry.Discovery, error) {
+	r, err := nfs.NacosRegistry(namespaceid)
+	if err != nil {
+		return nil, err
+	}
+	if len(group) > 0 && group[0] != "" {
+		r.WithGroup(group[0])
+	}
 	return r, nil
 }
@@ -0,0 +1,221 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lnhlg/gbm-common/i18n"
+	"github.com/nacos-group/nacos-sdk-go/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+)
+
+const (
+	nacosLoginAPI     = "/v1/auth/login"
+	nacosNamespaceAPI = "/v1/console/namespaces"
+
+	// 分页查询服务列表的页大小
+	nacosServicePageSize = 500
+)
+
+// NacosNamespace Nacos命名空间
+type NacosNamespace struct {
+	ID          string `json:"namespace"`
+	Name        string `json:"namespaceShowName"`
+	Desc        string `json:"namespaceDesc"`
+	Quota       int    `json:"quota"`
+	ConfigCount int    `json:"configCount"`
+	Type        int    `json:"type"` // 0:公共命名空间 2:自定义命名空间
+}
+
+// ListNamespaces 查询全部命名空间
+func (nfs *NacosCfgSource) ListNamespaces(ctx context.Context) ([]NacosNamespace, error) {
+	var resp struct {
+		Data []NacosNamespace `json:"data"`
+	}
+	body, err := nfs.adminRequest(ctx, http.MethodGet, nacosNamespaceAPI, nil)
+	if err != nil {
+		return nil, i18n.Errorf("查询命名空间失败: %w", err)
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, i18n.Errorf("查询命名空间失败: %w", err)
+	}
+	return resp.Data, nil
+}
+
+// CreateNamespace 创建命名空间，id为空时由Nacos生成
+func (nfs *NacosCfgSource) CreateNamespace(ctx context.Context, id, name, desc string) error {
+	form := url.Values{
+		"customNamespaceId": {id},
+		"namespaceName":     {name},
+		"namespaceDesc":     {desc},
+	}
+	body, err := nfs.adminRequest(ctx, http.MethodPost, nacosNamespaceAPI, form)
+	if err != nil {
+		return i18n.Errorf("创建命名空间失败: %w", err)
+	}
+	if strings.TrimSpace(string(body)) != "true" {
+		return i18n.Errorf("创建命名空间失败: %s", body)
+	}
+	return nil
+}
+
+// EnsureNamespace 命名空间不存在时创建，用于初始化新环境
+func (nfs *NacosCfgSource) EnsureNamespace(ctx context.Context, id, name, desc string) error {
+	namespaces, err := nfs.ListNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		if ns.ID == id {
+			return nil
+		}
+	}
+	return nfs.CreateNamespace(ctx, id, name, desc)
+}
+
+// ListServices 查询命名空间中指定分组的全部服务名，group为空时使用 DEFAULT_GROUP
+func (nfs *NacosCfgSource) ListServices(namespaceid, group string) ([]string, error) {
+	if group == "" {
+		group = DefaultNacosGroup
+	}
+	cli, err := nfs.namingClient(namespaceid)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for page := uint32(1); ; page++ {
+		list, err := cli.GetAllServicesInfo(vo.GetAllServiceInfoParam{
+			NameSpace: namespaceid,
+			GroupName: group,
+			PageNo:    page,
+			PageSize:  nacosServicePageSize,
+		})
+		if err != nil {
+			return nil, i18n.Errorf("查询服务列表失败: %w", err)
+		}
+		services = append(services, list.Doms...)
+		if len(list.Doms) < nacosServicePageSize || int64(len(services)) >= list.Count {
+			return services, nil
+		}
+	}
+}
+
+// adminRequest 依次请求各服务器的管理接口，返回第一个成功的响应
+func (nfs *NacosCfgSource) adminRequest(ctx context.Context, method, api string, form url.Values) ([]byte, error) {
+	servers := nfs.servers()
+	if len(servers) == 0 {
+		return nil, i18n.Errorf("管理接口需要配置Nacos服务器列表")
+	}
+	tr, err := nfs.httpTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Transport: tr, Timeout: nfs.timeout}
+	var errs []error
+	for _, sc := range servers {
+		base := nacosBaseURL(sc)
+		body, err := nfs.doAdminRequest(ctx, client, method, base, api, form)
+		if err == nil {
+			return body, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", base, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// doAdminRequest 请求单个服务器，配置了用户名时先登录获取令牌
+func (nfs *NacosCfgSource) doAdminRequest(ctx context.Context, client *http.Client, method, base, api string, form url.Values) ([]byte, error) {
+	query := url.Values{}
+	if nfs.userName != "" {
+		token, err := nacosLogin(ctx, client, base, nfs.userName, nfs.password)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("accessToken", token)
+	}
+
+	var body io.Reader
+	target := base + api
+	if method == http.MethodGet {
+		for k, v := range form {
+			query[k] = v
+		}
+	} else if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return doNacosRequest(client, req)
+}
+
+// nacosLogin 登录Nacos获取访问令牌
+func nacosLogin(ctx context.Context, client *http.Client, base, user, password string) (string, error) {
+	form := url.Values{"username": {user}, "password": {password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+nacosLoginAPI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	body, err := doNacosRequest(client, req)
+	if err != nil {
+		return "", fmt.Errorf("登录失败: %w", err)
+	}
+	var resp struct {
+		AccessToken string `json:"accessToken"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("登录失败: %w", err)
+	}
+	return resp.AccessToken, nil
+}
+
+// doNacosRequest 发送请求，非200时返回包含响应内容的错误
+func doNacosRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("返回%d: %s", resp.StatusCode, truncate(string(body), 256))
+	}
+	return body, nil
+}
+
+// nacosBaseURL 服务器的访问地址，含上下文路径
+func nacosBaseURL(sc constant.ServerConfig) string {
+	scheme := sc.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	contextPath := sc.ContextPath
+	if contextPath == "" {
+		contextPath = "/nacos"
+	}
+	return scheme + "://" + net.JoinHostPort(sc.IpAddr, strconv.FormatUint(sc.Port, 10)) + contextPath
+}
@@ -0,0 +1,262 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	// Nacos服务器列表配置键，变化时重建客户端
+	NacosServersKey = "nacos.servers"
+
+	// 默认探测间隔
+	DefaultNacosProbeInterval = 10 * time.Second
+
+	// 默认断连告警阈值，持续断连超过该时间视为不健康
+	DefaultNacosDisconnectThreshold = 30 * time.Second
+
+	// Nacos就绪检查接口
+	nacosReadinessAPI = "/v1/console/health/readiness"
+)
+
+// NacosMonitor Nacos连接监控
+// 定期探测服务器的就绪接口，任一服务器可用即视为已连接；
+// 持续断连超过阈值时输出告警日志并使健康检查失败，恢复后输出恢复日志；
+// 服务器列表变化时更新配置源并执行重建钩子(如 NacosRegistry.Reconnect)
+type NacosMonitor struct {
+	nfs       *NacosCfgSource
+	client    *http.Client
+	logger    *log.Helper
+	interval  time.Duration
+	threshold time.Duration
+
+	connected *Gauge
+	failures  *Counter
+
+	mu      sync.RWMutex
+	started bool
+	up      bool
+	since   time.Time // 当前连接状态的开始时间
+	lastErr error
+	alerted bool
+	hooks   []func(ctx context.Context) error
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewNacosMonitor 创建Nacos连接监控，metrics为nil时不记录指标
+func NewNacosMonitor(nfs *NacosCfgSource, logger log.Logger, metrics *Metrics) (*NacosMonitor, error) {
+	m := &NacosMonitor{
+		nfs:       nfs,
+		client:    &http.Client{Timeout: nfs.timeout},
+		logger:    log.NewHelper(logger),
+		interval:  DefaultNacosProbeInterval,
+		threshold: DefaultNacosDisconnectThreshold,
+		up:        true,
+		since:     time.Now(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	if metrics != nil {
+		var err error
+		if m.connected, err = metrics.Gauge("nacos_connected", "Nacos连接状态，1为已连接", "server"); err != nil {
+			return nil, err
+		}
+		if m.failures, err = metrics.Counter("nacos_probe_failures_total", "Nacos探测失败次数", "server"); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// WithInterval 设置探测间隔
+func (m *NacosMonitor) WithInterval(d time.Duration) *NacosMonitor {
+	m.interval = d
+	return m
+}
+
+// WithThreshold 设置断连告警阈值
+func (m *NacosMonitor) WithThreshold(d time.Duration) *NacosMonitor {
+	m.threshold = d
+	return m
+}
+
+// OnServersChanged 注册服务器列表变化后执行的重建钩子
+func (m *NacosMonitor) OnServersChanged(hook func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// UpdateServers 更新服务器列表，列表变化时执行重建钩子
+func (m *NacosMonitor) UpdateServers(ctx context.Context, svs []*NacosHost) error {
+	if reflect.DeepEqual(serverConfigs(svs), m.nfs.servers()) {
+		return nil
+	}
+	m.nfs.setServers(svs)
+	m.logger.Infof("Nacos服务器列表已更新，共%d个", len(svs))
+
+	m.mu.RLock()
+	hooks := append([]func(context.Context) error(nil), m.hooks...)
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			m.logger.Errorf("重建Nacos客户端失败: %v", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start 启动后台探测，重复调用无效
+func (m *NacosMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return
+	}
+	m.started = true
+	go m.run()
+}
+
+// Stop 停止后台探测
+func (m *NacosMonitor) Stop(ctx context.Context) error {
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	m.mu.RLock()
+	started := m.started
+	m.mu.RUnlock()
+	if !started {
+		return nil
+	}
+	select {
+	case <-m.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Connected 当前是否已连接
+func (m *NacosMonitor) Connected() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.up
+}
+
+// HealthCheck 健康检查，持续断连超过阈值时返回错误
+func (m *NacosMonitor) HealthCheck() HealthCheck {
+	return func(context.Context) error {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		if !m.up && time.Since(m.since) >= m.threshold {
+			return fmt.Errorf("Nacos已断连%s: %v", time.Since(m.since).Truncate(time.Second), m.lastErr)
+		}
+		return nil
+	}
+}
+
+// run 探测循环
+func (m *NacosMonitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.check()
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check 探测所有服务器并更新连接状态
+func (m *NacosMonitor) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+	defer cancel()
+
+	servers := m.nfs.servers()
+	var errs []error
+	for _, sc := range servers {
+		server := net.JoinHostPort(sc.IpAddr, strconv.FormatUint(sc.Port, 10))
+
+		err := m.probe(ctx, nacosBaseURL(sc)+nacosReadinessAPI)
+		if m.connected != nil {
+			v := 1.0
+			if err != nil {
+				v = 0
+			}
+			m.connected.Set(ctx, v, server)
+		}
+		if err != nil {
+			if m.failures != nil {
+				m.failures.Inc(ctx, server)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+		}
+	}
+
+	// 任一服务器可用即视为已连接，使用地址服务器(无静态列表)时不做判断
+	up := len(servers) == 0 || len(errs) < len(servers)
+	m.update(up, errors.Join(errs...))
+}
+
+// update 更新连接状态并在状态变化和超过阈值时输出日志
+func (m *NacosMonitor) update(up bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastErr = err
+	if up != m.up {
+		if up {
+			m.logger.Infof("Nacos连接已恢复，断连%s", time.Since(m.since).Truncate(time.Second))
+		} else {
+			m.logger.Warnf("Nacos连接异常: %v", err)
+		}
+		m.up = up
+		m.since = time.Now()
+		m.alerted = false
+		return
+	}
+
+	if !up && !m.alerted && time.Since(m.since) >= m.threshold {
+		m.alerted = true
+		m.logger.Errorf("Nacos持续断连超过%s: %v", m.threshold, err)
+	}
+}
+
+// probe 请求就绪接口，返回200视为可用
+func (m *NacosMonitor) probe(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("就绪检查返回%d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/model"
+	"github.com/nacos-group/nacos-sdk-go/vo"
+)
+
+const (
+	// 默认Nacos集群
+	DefaultNacosCluster = "DEFAULT"
+
+	// 默认实例权重
+	DefaultNacosWeight = 100
+)
+
+var (
+	_ registry.Registrar = (*NacosRegistry)(nil)
+	_ registry.Discovery = (*NacosRegistry)(nil)
+)
+
+// NacosRegistry 支持分组、集群、权重和持久化实例的Nacos注册中心
+// 注册的服务名为 <name>.<scheme>，与kratos的Nacos注册中心保持一致
+type NacosRegistry struct {
+	mu        sync.RWMutex
+	cli       naming_client.INamingClient
+	nfs       *NacosCfgSource // 用于重建客户端，NewNacosRegistry创建时为nil
+	namespace string
+	services  map[string]*registry.ServiceInstance // 已注册的实例，重建客户端后重新注册
+	watchers  map[*nacosWatcher]struct{}
+	group     string
+	cluster   string
+	weight    float64
+	ephemeral bool
+	clusters  []string // 发现时只返回这些集群的实例，为空时返回全部集群
+	kind      string   // 实例元数据缺少kind时使用的协议
+}
+
+// NacosRegistry 创建Nacos注册中心，默认分组 DEFAULT_GROUP、集群 DEFAULT、权重100、临时实例
+func (nfs *NacosCfgSource) NacosRegistry(namespaceid string) (*NacosRegistry, error) {
+	client, err := nfs.namingClient(namespaceid)
+	if err != nil {
+		return nil, err
+	}
+	r := NewNacosRegistry(client)
+	r.nfs = nfs
+	r.namespace = namespaceid
+	return r, nil
+}
+
+// namingClient 创建Nacos命名客户端
+func (nfs *NacosCfgSource) namingClient(namespaceid string) (naming_client.INamingClient, error) {
+	nc, err := nfs.nacosClient(namespaceid)
+	if err != nil {
+		return nil, err
+	}
+	naming, err := naming_client.NewNamingClient(nc)
+	if err != nil {
+		return nil, err
+	}
+	return &naming, nil
+}
+
+// NewNacosRegistry 使用已有的命名客户端创建Nacos注册中心
+func NewNacosRegistry(cli naming_client.INamingClient) *NacosRegistry {
+	return &NacosRegistry{
+		cli:       cli,
+		services:  make(map[string]*registry.ServiceInstance),
+		watchers:  make(map[*nacosWatcher]struct{}),
+		group:     DefaultNacosGroup,
+		cluster:   DefaultNacosCluster,
+		weight:    DefaultNacosWeight,
+		ephemeral: true,
+		kind:      "grpc",
+	}
+}
+
+// WithGroup 设置注册和发现使用的分组
+func (r *NacosRegistry) WithGroup(group string) *NacosRegistry {
+	r.group = group
+	return r
+}
+
+// WithCluster 设置注册实例所在的集群
+func (r *NacosRegistry) WithCluster(cluster string) *NacosRegistry {
+	r.cluster = cluster
+	return r
+}
+
+// WithWeight 设置注册实例的权重
+func (r *NacosRegistry) WithWeight(weight float64) *NacosRegistry {
+	r.weight = weight
+	return r
+}
+
+// WithEphemeral 设置是否为临时实例，持久化实例由服务端主动健康检查，进程退出后不会自动删除
+func (r *NacosRegistry) WithEphemeral(ephemeral bool) *NacosRegistry {
+	r.ephemeral = ephemeral
+	return r
+}
+
+// WithDiscoveryClusters 设置发现时的集群过滤，为空时返回全部集群的实例
+func (r *NacosRegistry) WithDiscoveryClusters(clusters ...string) *NacosRegistry {
+	r.clusters = clusters
+	return r
+}
+
+// WithDefaultKind 设置实例元数据缺少kind时使用的协议，默认grpc
+func (r *NacosRegistry) WithDefaultKind(kind string) *NacosRegistry {
+	r.kind = kind
+	return r
+}
+
+// Client 底层命名客户端
+func (r *NacosRegistry) Client() naming_client.INamingClient {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cli
+}
+
+// Reconnect 使用配置源当前的服务器列表重建命名客户端，
+// 然后在新客户端上恢复订阅并重新注册已注册的实例
+func (r *NacosRegistry) Reconnect(ctx context.Context) error {
+	if r.nfs == nil {
+		return errors.New("注册中心未关联Nacos配置源，无法重建客户端")
+	}
+	cli, err := r.nfs.namingClient(r.namespace)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	old := r.cli
+	r.cli = cli
+	services := make([]*registry.ServiceInstance, 0, len(r.services))
+	for _, si := range r.services {
+		services = append(services, si)
+	}
+	watchers := make([]*nacosWatcher, 0, len(r.watchers))
+	for w := range r.watchers {
+		watchers = append(watchers, w)
+	}
+	r.mu.Unlock()
+
+	var errs []error
+	for _, w := range watchers {
+		_ = old.Unsubscribe(w.param)
+		if err := cli.Subscribe(w.param); err != nil {
+			errs = append(errs, fmt.Errorf("恢复订阅失败(%s): %w", w.serviceName, err))
+			continue
+		}
+		w.trigger()
+	}
+	for _, si := range services {
+		if err := r.Register(ctx, si); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Register 按endpoint逐个注册实例，元数据weight有效时覆盖默认权重
+func (r *NacosRegistry) Register(_ context.Context, si *registry.ServiceInstance) error {
+	if si.Name == "" {
+		return errors.New("服务名不能为空")
+	}
+	weight := r.weight
+	if w, err := strconv.ParseFloat(si.Metadata["weight"], 64); err == nil && w > 0 {
+		weight = w
+	}
+	for _, endpoint := range si.Endpoints {
+		scheme, host, port, err := splitEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+
+		md := make(map[string]string, len(si.Metadata)+2)
+		for k, v := range si.Metadata {
+			md[k] = v
+		}
+		md["kind"] = scheme
+		md["version"] = si.Version
+
+		if _, err := r.Client().RegisterInstance(vo.RegisterInstanceParam{
+			Ip:          host,
+			Port:        port,
+			ServiceName: si.Name + "." + scheme,
+			Weight:      weight,
+			Enable:      true,
+			Healthy:     true,
+			Ephemeral:   r.ephemeral,
+			Metadata:    md,
+			ClusterName: r.cluster,
+			GroupName:   r.group,
+		}); err != nil {
+			return fmt.Errorf("注册实例失败(%s): %w", endpoint, err)
+		}
+	}
+
+	r.mu.Lock()
+	r.services[si.ID] = si
+	r.mu.Unlock()
+	return nil
+}
+
+// Deregister 按endpoint逐个注销实例
+func (r *NacosRegistry) Deregister(_ context.Context, si *registry.ServiceInstance) error {
+	r.mu.Lock()
+	delete(r.services, si.ID)
+	r.mu.Unlock()
+
+	for _, endpoint := range si.Endpoints {
+		scheme, host, port, err := splitEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+		if _, err := r.Client().DeregisterInstance(vo.DeregisterInstanceParam{
+			Ip:          host,
+			Port:        port,
+			ServiceName: si.Name + "." + scheme,
+			Cluster:     r.cluster,
+			GroupName:   r.group,
+			Ephemeral:   r.ephemeral,
+		}); err != nil {
+			return fmt.Errorf("注销实例失败(%s): %w", endpoint, err)
+		}
+	}
+	return nil
+}
+
+// GetService 查询服务的健康实例
+func (r *NacosRegistry) GetService(_ context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	res, err := r.Client().SelectInstances(vo.SelectInstancesParam{
+		ServiceName: serviceName,
+		GroupName:   r.group,
+		Clusters:    r.clusters,
+		HealthyOnly: true,
+	})
+	if err != nil {
+		// SDK在服务没有(健康)实例时也返回错误
+		if strings.HasSuffix(err.Error(), "instance list is empty!") {
+			return nil, fmt.Errorf("%w: %s", ErrNoServiceInstance, serviceName)
+		}
+		return nil, err
+	}
+	return r.toInstances(serviceName, res), nil
+}
+
+// Watch 订阅服务实例变化
+func (r *NacosRegistry) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	w := &nacosWatcher{
+		registry:    r,
+		serviceName: serviceName,
+		notify:      make(chan struct{}, 1),
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	w.param = &vo.SubscribeParam{
+		ServiceName: serviceName,
+		GroupName:   r.group,
+		Clusters:    r.clusters,
+		SubscribeCallback: func([]model.SubscribeService, error) {
+			w.trigger()
+		},
+	}
+	if err := r.Client().Subscribe(w.param); err != nil {
+		w.cancel()
+		return nil, err
+	}
+	r.mu.Lock()
+	r.watchers[w] = struct{}{}
+	r.mu.Unlock()
+
+	w.trigger()
+	return w, nil
+}
+
+// toInstances 转换为kratos服务实例
+func (r *NacosRegistry) toInstances(serviceName string, res []model.Instance) []*registry.ServiceInstance {
+	items := make([]*registry.ServiceInstance, 0, len(res))
+	for _, in := range res {
+		kind := r.kind
+		if k, ok := in.Metadata["kind"]; ok {
+			kind = k
+		}
+		items = append(items, &registry.ServiceInstance{
+			ID:        in.InstanceId,
+			Name:      serviceName,
+			Version:   in.Metadata["version"],
+			Metadata:  in.Metadata,
+			Endpoints: []string{fmt.Sprintf("%s://%s", kind, net.JoinHostPort(in.Ip, strconv.FormatUint(in.Port, 10)))},
+		})
+	}
+	return items
+}
+
+// nacosWatcher Nacos服务实例监听器
+type nacosWatcher struct {
+	registry    *NacosRegistry
+	serviceName string
+	param       *vo.SubscribeParam
+	notify      chan struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// trigger 通知实例变化，已有未处理的通知时忽略
+func (w *nacosWatcher) trigger() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Next 阻塞直到实例变化，返回变化后的全部健康实例
+func (w *nacosWatcher) Next() ([]*registry.ServiceInstance, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case <-w.notify:
+	}
+	return w.registry.GetService(w.ctx, w.serviceName)
+}
+
+// Stop 取消订阅
+func (w *nacosWatcher) Stop() error {
+	w.cancel()
+	w.registry.mu.Lock()
+	delete(w.registry.watchers, w)
+	w.registry.mu.Unlock()
+	return w.registry.Client().Unsubscribe(w.param)
+}
+
+// splitEndpoint 解析 scheme://host:port 形式的endpoint
+func splitEndpoint(endpoint string) (scheme, host string, port uint64, err error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", 0, err
+	}
+	h, p, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return "", "", 0, err
+	}
+	port, err = strconv.ParseUint(p, 10, 64)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return u.Scheme, h, port, nil
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lnhlg/gbm-common/i18n"
+)
+
+// NacosTLS Nacos https连接的TLS设置
+// 设置仅作用于本库创建的Nacos客户端和管理接口请求，不修改进程的 http.DefaultTransport
+type NacosTLS struct {
+	CAFile     string // 自签CA证书文件(PEM)
+	SkipVerify bool   // 跳过服务端证书校验，仅用于测试环境
+}
+
+// transport 基于默认HTTP传输层克隆出Nacos专用的传输层，并合并TLS设置
+func (t *NacosTLS) transport() (*http.Transport, error) {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, i18n.Errorf("默认HTTP传输层不支持TLS设置")
+	}
+	tr := base.Clone()
+
+	cfg := &tls.Config{}
+	if tr.TLSClientConfig != nil {
+		cfg = tr.TLSClientConfig.Clone()
+	}
+	cfg.InsecureSkipVerify = t.SkipVerify
+
+	if t.CAFile != "" {
+		data, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, i18n.Errorf("读取CA证书失败: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, i18n.Errorf("无效的CA证书")
+		}
+		cfg.RootCAs = pool
+	}
+
+	tr.TLSClientConfig = cfg
+	return tr, nil
+}
+
+// nacosHTTPAgent 使用独立传输层的Nacos SDK HTTP代理
+// 除传输层外与SDK自带的 http_agent.HttpAgent 行为一致，查询参数和表单均按URL编码
+type nacosHTTPAgent struct {
+	transport http.RoundTripper
+}
+
+func (a *nacosHTTPAgent) do(method, path string, header http.Header, timeoutMs uint64, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	client := &http.Client{
+		Transport: a.transport,
+		Timeout:   time.Millisecond * time.Duration(timeoutMs),
+	}
+	return client.Do(req)
+}
+
+// withQuery 将参数拼接到请求路径
+func withQuery(path string, params map[string]string) string {
+	if len(params) == 0 {
+		return path
+	}
+	q := url.Values{}
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	if strings.Contains(path, "?") {
+		return path + "&" + q.Encode()
+	}
+	return path + "?" + q.Encode()
+}
+
+// formBody 将参数编码为表单，skipEmpty为true时忽略空值
+func formBody(params map[string]string, skipEmpty bool) io.Reader {
+	form := url.Values{}
+	for k, v := range params {
+		if v != "" || !skipEmpty {
+			form.Set(k, v)
+		}
+	}
+	return strings.NewReader(form.Encode())
+}
+
+func (a *nacosHTTPAgent) Get(path string, header http.Header, timeoutMs uint64, params map[string]string) (*http.Response, error) {
+	return a.do(http.MethodGet, withQuery(path, params), header, timeoutMs, nil)
+}
+
+func (a *nacosHTTPAgent) Post(path string, header http.Header, timeoutMs uint64, params map[string]string) (*http.Response, error) {
+	return a.do(http.MethodPost, path, header, timeoutMs, formBody(params, false))
+}
+
+func (a *nacosHTTPAgent) Put(path string, header http.Header, timeoutMs uint64, params map[string]string) (*http.Response, error) {
+	return a.do(http.MethodPut, path, header, timeoutMs, formBody(params, true))
+}
+
+func (a *nacosHTTPAgent) Delete(path string, header http.Header, timeoutMs uint64, params map[string]string) (*http.Response, error) {
+	return a.do(http.MethodDelete, withQuery(path, params), header, timeoutMs, nil)
+}
+
+func (a *nacosHTTPAgent) Request(method string, path string, header http.Header, timeoutMs uint64, params map[string]string) (*http.Response, error) {
+	switch method {
+	case http.MethodGet:
+		return a.Get(path, header, timeoutMs, params)
+	case http.MethodPost:
+		return a.Post(path, header, timeoutMs, params)
+	case http.MethodPut:
+		return a.Put(path, header, timeoutMs, params)
+	case http.MethodDelete:
+		return a.Delete(path, header, timeoutMs, params)
+	default:
+		return nil, i18n.Errorf("不支持的请求方法: %s", method)
+	}
+}
+
+// RequestOnlyResult 请求并返回响应内容，失败或状态码非200时返回空字符串
+func (a *nacosHTTPAgent) RequestOnlyResult(method string, path string, header http.Header, timeoutMs uint64, params map[string]string) string {
+	resp, err := a.Request(method, path, header, timeoutMs, params)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/registry"
+
+	"github.com/lnhlg/gbm-common/i18n"
+)
+
+// Option 应用选项
+type Option func(*app)
+
+// NewAppWithOptions 使用选项创建应用，配合 Setup 完成初始化
+func NewAppWithOptions(opts ...Option) *app {
+	a := &app{}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+// WithID 设置服务实例ID
+func WithID(id string) Option {
+	return func(a *app) { a.id = id }
+}
+
+// WithName 设置服务名
+func WithName(name string) Option {
+	return func(a *app) { a.name = name }
+}
+
+// WithVersion 设置服务版本
+func WithVersion(version string) Option {
+	return func(a *app) { a.version = version }
+}
+
+// WithNacos 设置Nacos命名空间和配置源
+func WithNacos(namespace string, nacosCfg *NacosCfgSource) Option {
+	return func(a *app) {
+		a.nacosNamespace = namespace
+		a.nacosCfg = nacosCfg
+	}
+}
+
+// WithStandalone 开启单机模式，见 (*app).WithStandalone
+func WithStandalone() Option {
+	return func(a *app) { a.standalone = true }
+}
+
+// WithNacosGroup 设置Nacos配置、注册和发现使用的分组，见 (*app).WithNacosGroup
+func WithNacosGroup(group string) Option {
+	return func(a *app) { a.nacosGroup = group }
+}
+
+// WithNacosDataIDs 设置Init加载的Nacos配置dataId，见 (*app).WithNacosDataIDs
+func WithNacosDataIDs(dataids ...string) Option {
+	return func(a *app) { a.nacosDataIDs = append(a.nacosDataIDs, dataids...) }
+}
+
+// WithNacosCluster 设置注册实例所在的Nacos集群，见 (*app).WithNacosCluster
+func WithNacosCluster(cluster string) Option {
+	return func(a *app) { a.nacosCluster = cluster }
+}
+
+// WithWorkerIDLock 设置预留雪花ID workerID 使用的分布式锁，见 (*app).WithWorkerIDLock
+func WithWorkerIDLock(l DistributedLock) Option {
+	return func(a *app) { a.workerLock = l }
+}
+
+// WithRegistrar 追加同时注册的注册中心，见 (*app).WithRegistrar
+func WithRegistrar(name string, reg registry.Registrar, optional bool) Option {
+	return func(a *app) { a.WithRegistrar(name, reg, optional) }
+}
+
+// WithWarmup 追加注册前执行的预热任务，见 (*app).WithWarmup
+func WithWarmup(name string, task WarmupTask) Option {
+	return func(a *app) { a.WithWarmup(name, task) }
+}
+
+// WithWeightRamp 设置注册权重爬升，见 (*app).WithWeightRamp
+func WithWeightRamp(initial float64, d time.Duration) Option {
+	return func(a *app) { a.WithWeightRamp(initial, d) }
+}
+
+// WithMergePolicy 设置配置源优先级和合并策略，见 ConfigMergePolicy
+func WithMergePolicy(p ConfigMergePolicy) Option {
+	return func(a *app) { a.WithMergePolicy(p) }
+}
+
+// WithConfigFile 设置本地配置文件(或目录)路径
+func WithConfigFile(path string) Option {
+	return func(a *app) { a.confPath = path }
+}
+
+// WithProfile 设置运行环境(dev、test、prod等)，默认读取环境变量 APP_ENV
+// 运行环境决定加载的 config.{profile}.yaml，未设置 WithNacosGroup 时同时决定Nacos分组(见 ProfileGroup)
+func WithProfile(profile string) Option {
+	return func(a *app) { a.profile = profile }
+}
+
+// WithExtraSource 追加配置源，按追加顺序加载，本地配置文件最后加载
+func WithExtraSource(s ...config.Source) Option {
+	return func(a *app) { a.sources = append(a.sources, s...) }
+}
+
+// WithConsul 增加Consul KV配置源，每个前缀一个配置源
+func WithConsul(c *ConsulCfgSource, prefixes ...string) Option {
+	return func(a *app) {
+		sources, err := c.ConsulSources(prefixes...)
+		if err != nil {
+			a.optErr = err
+			return
+		}
+		a.sources = append(a.sources, sources...)
+	}
+}
+
+// WithEnv 增加环境变量配置源，prefixes非空时只加载带前缀的变量
+func WithEnv(prefixes ...string) Option {
+	return func(a *app) { a.sources = append(a.sources, NewEnvSource(prefixes...)) }
+}
+
+// WithDecryptor 设置配置解密器，配置中的 ENC(...) 值在加载和热更新时自动解密
+func WithDecryptor(dec *RSADecryptor) Option {
+	return func(a *app) { a.decryptor = dec }
+}
+
+// WithLoggerOptions 设置日志选项
+func WithLoggerOptions(opts ...LoggerOption) Option {
+	return func(a *app) { a.loggerOpts = append(a.loggerOpts, opts...) }
+}
+
+// WithoutMetrics 不创建指标，appResult.Metrics 为nil
+func WithoutMetrics() Option {
+	return func(a *app) { a.noMetrics = true }
+}
+
+// Setup 使用选项中的配置文件和配置源完成初始化
+func (a *app) Setup() (*appResult, error) {
+	if a.optErr != nil {
+		return nil, a.optErr
+	}
+	return a.Init(a.confPath, a.sources...)
+}
+
+// WithLanguage 设置默认语言，Init时生效，配置 i18n.lang 存在时以配置为准
+func WithLanguage(lang i18n.Lang) Option {
+	return func(a *app) { a.language = lang }
+}
@@ -0,0 +1,168 @@
+package common
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lnhlg/gbm-common/i18n"
+	"github.com/lnhlg/gbm-common/response"
+)
+
+const (
+	// 默认页大小
+	DefaultPageSize = 20
+
+	// 最大页大小，超过时按最大值处理
+	MaxPageSize = 500
+)
+
+// SortField 排序字段
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// PageRequest 分页查询参数
+type PageRequest struct {
+	Page int         `json:"page"` // 页码，从1开始
+	Size int         `json:"size"`
+	Sort []SortField `json:"sort"`
+}
+
+// PageResult 分页查询结果
+type PageResult[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total"`
+	Page  int   `json:"page"`
+	Size  int   `json:"size"`
+	Pages int   `json:"pages"`
+}
+
+// ParsePageRequest 从查询参数解析分页请求：page、size、sort
+// sort格式为逗号分隔的字段列表，字段前加 - 表示降序，如 sort=-created_at,id；
+// sortable非空时只允许其中的字段，其他字段返回 response.InvalidArgument(信息使用默认语言)
+func ParsePageRequest(q url.Values, sortable ...string) (PageRequest, error) {
+	var p PageRequest
+	if s := q.Get("page"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return p, response.InvalidArgument.Error(i18n.Sprintf(i18n.Default(), "无效的页码: %s", s))
+		}
+		p.Page = n
+	}
+	if s := q.Get("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return p, response.InvalidArgument.Error(i18n.Sprintf(i18n.Default(), "无效的页大小: %s", s))
+		}
+		p.Size = n
+	}
+
+	for _, s := range strings.Split(q.Get("sort"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		f := SortField{Field: s}
+		if strings.HasPrefix(s, "-") {
+			f = SortField{Field: s[1:], Desc: true}
+		}
+		p.Sort = append(p.Sort, f)
+	}
+
+	if err := p.CheckSort(sortable...); err != nil {
+		return p, err
+	}
+	return p.Normalize(), nil
+}
+
+// Normalize 规范化页码和页大小：页码最小为1，页大小默认 DefaultPageSize、最大 MaxPageSize
+func (p PageRequest) Normalize() PageRequest {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	switch {
+	case p.Size <= 0:
+		p.Size = DefaultPageSize
+	case p.Size > MaxPageSize:
+		p.Size = MaxPageSize
+	}
+	return p
+}
+
+// CheckSort 检查排序字段是否在允许列表中，sortable为空时不限制
+func (p PageRequest) CheckSort(sortable ...string) error {
+	if len(sortable) == 0 {
+		return nil
+	}
+	for _, f := range p.Sort {
+		ok := false
+		for _, s := range sortable {
+			if f.Field == s {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return response.InvalidArgument.Error(i18n.Sprintf(i18n.Default(), "不支持的排序字段: %s", f.Field))
+		}
+	}
+	return nil
+}
+
+// Offset 查询偏移量
+func (p PageRequest) Offset() int {
+	p = p.Normalize()
+	return (p.Page - 1) * p.Size
+}
+
+// Limit 查询条数
+func (p PageRequest) Limit() int {
+	return p.Normalize().Size
+}
+
+// Scope GORM分页和排序作用域，排序字段作为列名引用，调用方需先用 CheckSort 限定字段
+func (p PageRequest) Scope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, f := range p.Sort {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: f.Field}, Desc: f.Desc})
+		}
+		return db.Offset(p.Offset()).Limit(p.Limit())
+	}
+}
+
+// NewPageResult 包装分页结果，items为nil时返回空列表
+func NewPageResult[T any](p PageRequest, items []T, total int64) *PageResult[T] {
+	p = p.Normalize()
+	if items == nil {
+		items = []T{}
+	}
+	return &PageResult[T]{
+		Items: items,
+		Total: total,
+		Page:  p.Page,
+		Size:  p.Size,
+		Pages: int((total + int64(p.Size) - 1) / int64(p.Size)),
+	}
+}
+
+// Paginate 在db上统计总数并查询当前页，db应已设置Model和查询条件
+func Paginate[T any](ctx context.Context, db *gorm.DB, p PageRequest) (*PageResult[T], error) {
+	var total int64
+	if err := db.WithContext(ctx).Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var items []T
+	if total > 0 && int64(p.Offset()) < total {
+		if err := db.WithContext(ctx).Scopes(p.Scope()).Find(&items).Error; err != nil {
+			return nil, err
+		}
+	}
+	return NewPageResult(p, items, total), nil
+}
@@ -0,0 +1,162 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+)
+
+const (
+	// 指定运行环境的环境变量
+	ProfileEnv = "APP_ENV"
+
+	// 当前运行环境的配置键
+	ProfileKey = "app.profile"
+
+	// 常用运行环境
+	ProfileDev  = "dev"
+	ProfileTest = "test"
+	ProfileProd = "prod"
+
+	// 未指定运行环境时使用的Nacos分组
+	DefaultNacosGroup = "DEFAULT_GROUP"
+
+	// 本地配置文件的基础名，环境配置文件为 config.{profile}.yaml
+	configBaseName = "config"
+)
+
+// ActiveProfile 从环境变量 APP_ENV 读取当前运行环境，未设置时为空
+func ActiveProfile() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(ProfileEnv)))
+}
+
+// ProfileGroup 运行环境对应的Nacos分组，如 prod -> PROD_GROUP，未指定运行环境时为 DEFAULT_GROUP
+func ProfileGroup(profile string) string {
+	if profile == "" {
+		return DefaultNacosGroup
+	}
+	return strings.ToUpper(profile) + "_GROUP"
+}
+
+// ProfileSources 按运行环境对应的分组为多个dataId创建配置源
+func (nfs *NacosCfgSource) ProfileSources(namespaceid, profile string, dataids ...string) ([]config.Source, error) {
+	ids := make([]NacosDataID, len(dataids))
+	for i, id := range dataids {
+		ids[i] = NacosDataID{DataID: id, Group: ProfileGroup(profile)}
+	}
+	return nfs.NacosSources(namespaceid, ids...)
+}
+
+// profileFileSources 按运行环境创建本地配置文件源，环境配置覆盖基础配置
+// path为文件时追加同目录的 {name}.{profile}{ext}；
+// path为目录时加载除环境配置外的全部文件，再追加 config.{profile}.*；
+// 目录中不存在环境配置文件时与原来一样整体加载目录
+func profileFileSources(path, profile string) []config.Source {
+	info, err := os.Stat(path)
+	if err != nil {
+		return []config.Source{file.NewSource(path)}
+	}
+
+	if !info.IsDir() {
+		sources := []config.Source{file.NewSource(path)}
+		if profile != "" {
+			ext := filepath.Ext(path)
+			p := strings.TrimSuffix(path, ext) + "." + profile + ext
+			if _, err := os.Stat(p); err == nil {
+				sources = append(sources, file.NewSource(p))
+			}
+		}
+		return sources
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil || !hasProfileFile(entries) {
+		return []config.Source{file.NewSource(path)}
+	}
+
+	var base, profiles []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if p, ok := profileOf(name); ok {
+			if p == profile {
+				profiles = append(profiles, name)
+			}
+			continue
+		}
+		base = append(base, name)
+	}
+	sort.Strings(base)
+	sort.Strings(profiles)
+	sources := make([]config.Source, 0, len(base)+len(profiles))
+	for _, name := range append(base, profiles...) {
+		sources = append(sources, file.NewSource(filepath.Join(path, name)))
+	}
+	return sources
+}
+
+// profileOf 解析 config.{profile}.{ext} 形式的文件名
+func profileOf(name string) (string, bool) {
+	parts := strings.Split(name, ".")
+	if len(parts) != 3 || parts[0] != configBaseName || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// hasProfileFile 目录中是否存在环境配置文件
+func hasProfileFile(entries []os.DirEntry) bool {
+	for _, e := range entries {
+		if _, ok := profileOf(e.Name()); ok && !e.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// profileSource 将当前运行环境写入配置键 app.profile
+type profileSource struct {
+	profile string
+}
+
+// Load 加载运行环境配置
+func (s *profileSource) Load() ([]*config.KeyValue, error) {
+	b, err := json.Marshal(map[string]interface{}{
+		"app": map[string]string{"profile": s.profile},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []*config.KeyValue{{Key: "profile", Value: b, Format: "json"}}, nil
+}
+
+// Watch 运行环境不会变化，监听器阻塞到停止
+func (s *profileSource) Watch() (config.Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &profileWatcher{ctx: ctx, cancel: cancel}, nil
+}
+
+type profileWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Next 阻塞直到停止
+func (w *profileWatcher) Next() ([]*config.KeyValue, error) {
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+
+// Stop 停止监听
+func (w *profileWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
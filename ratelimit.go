@@ -0,0 +1,185 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/aegis/ratelimit/bbr"
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	kratoslimit "github.com/go-kratos/kratos/v2/middleware/ratelimit"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// 限流配置键
+	RateLimitConfigKey = "ratelimit"
+
+	// BBR自适应限流
+	RateLimitBBR = "bbr"
+
+	// 令牌桶限流
+	RateLimitTokenBucket = "token"
+)
+
+// RateLimitRule 单条限流规则
+type RateLimitRule struct {
+	Rate         float64 `json:"rate"`          // 令牌桶每秒速率，<=0表示不限流
+	Burst        int     `json:"burst"`         // 令牌桶容量，<=0时取Rate
+	CPUThreshold int64   `json:"cpu_threshold"` // BBR的CPU阈值(千分比)，<=0时使用默认值800
+}
+
+// RateLimitConfig 限流配置，对应配置中的 ratelimit 节点
+type RateLimitConfig struct {
+	Strategy string                   `json:"strategy"` // bbr或token，默认bbr
+	Global   *RateLimitRule           `json:"global"`   // 全局限流，nil表示不启用
+	Routes   map[string]RateLimitRule `json:"routes"`   // 按operation的路由限流
+}
+
+// RateLimiter 支持全局和按路由限流的中间件，规则可热更新
+type RateLimiter struct {
+	mu     sync.RWMutex
+	global ratelimit.Limiter
+	routes map[string]ratelimit.Limiter
+}
+
+// NewRateLimiter 根据配置创建限流器
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	r := &RateLimiter{}
+	r.Update(cfg)
+	return r
+}
+
+// Update 按新配置重建限流器
+func (r *RateLimiter) Update(cfg RateLimitConfig) {
+	var global ratelimit.Limiter
+	if cfg.Global != nil {
+		global = newLimiter(cfg.Strategy, *cfg.Global)
+	}
+	routes := make(map[string]ratelimit.Limiter, len(cfg.Routes))
+	for op, rule := range cfg.Routes {
+		if l := newLimiter(cfg.Strategy, rule); l != nil {
+			routes[op] = l
+		}
+	}
+
+	r.mu.Lock()
+	r.global = global
+	r.routes = routes
+	r.mu.Unlock()
+}
+
+// Middleware 服务端限流中间件，先匹配路由规则再匹配全局规则，超限时返回429
+func (r *RateLimiter) Middleware() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			r.mu.RLock()
+			global := r.global
+			var route ratelimit.Limiter
+			if info, ok := transport.FromServerContext(ctx); ok {
+				route = r.routes[info.Operation()]
+			}
+			r.mu.RUnlock()
+
+			var dones []ratelimit.DoneFunc
+			for _, l := range []ratelimit.Limiter{route, global} {
+				if l == nil {
+					continue
+				}
+				done, err := l.Allow()
+				if err != nil {
+					for _, d := range dones {
+						d(ratelimit.DoneInfo{Err: err})
+					}
+					return nil, kratoslimit.ErrLimitExceed
+				}
+				dones = append(dones, done)
+			}
+
+			reply, err := handler(ctx, req)
+			for _, d := range dones {
+				d(ratelimit.DoneInfo{Err: err})
+			}
+			return reply, err
+		}
+	}
+}
+
+// NewRateLimiterFromConfig 从配置的 ratelimit 节点创建限流器，并在配置变化时热更新
+// 配置中没有 ratelimit 节点时返回不限流的限流器
+func NewRateLimiterFromConfig(r *appResult) (*RateLimiter, error) {
+	var cfg RateLimitConfig
+	if err := r.Cfg.Value(RateLimitConfigKey).Scan(&cfg); err != nil && err != config.ErrNotFound {
+		return nil, err
+	}
+
+	rl := NewRateLimiter(cfg)
+	err := Watch(r, RateLimitConfigKey, func(cfg RateLimitConfig) error {
+		rl.Update(cfg)
+		log.NewHelper(r.Logger).Info("限流配置已更新")
+		return nil
+	})
+	if err != nil && err != config.ErrNotFound {
+		return nil, err
+	}
+	return rl, nil
+}
+
+// newLimiter 按策略创建单个限流器
+func newLimiter(strategy string, rule RateLimitRule) ratelimit.Limiter {
+	if strategy == RateLimitTokenBucket {
+		if rule.Rate <= 0 {
+			return nil
+		}
+		return newTokenBucket(rule.Rate, rule.Burst)
+	}
+
+	var opts []bbr.Option
+	if rule.CPUThreshold > 0 {
+		opts = append(opts, bbr.WithCPUThreshold(rule.CPUThreshold))
+	}
+	return bbr.NewLimiter(opts...)
+}
+
+// tokenBucket 令牌桶限流器
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket 创建令牌桶，初始为满桶
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := float64(burst)
+	if b <= 0 {
+		b = rate
+	}
+	if b < 1 {
+		b = 1
+	}
+	return &tokenBucket{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// Allow 取一个令牌，令牌不足时拒绝
+func (t *tokenBucket) Allow() (ratelimit.DoneFunc, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.tokens += now.Sub(t.last).Seconds() * t.rate
+	if t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+	t.last = now
+
+	if t.tokens < 1 {
+		return nil, ratelimit.ErrLimitExceed
+	}
+	t.tokens--
+	return func(ratelimit.DoneInfo) {}, nil
+}
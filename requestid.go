@@ -0,0 +1,67 @@
+package common
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// 请求ID头
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID 服务端请求ID中间件
+// 优先使用请求头中的 X-Request-ID，没有时生成新ID，并写入上下文和响应头
+func RequestID() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var id string
+			tr, ok := transport.FromServerContext(ctx)
+			if ok {
+				id = tr.RequestHeader().Get(RequestIDHeader)
+			}
+			if id == "" {
+				id = NewUUID()
+			}
+			if ok {
+				tr.ReplyHeader().Set(RequestIDHeader, id)
+			}
+			return handler(NewRequestIDContext(ctx, id), req)
+		}
+	}
+}
+
+// RequestIDClient 客户端请求ID中间件，将上下文中的请求ID透传给下游
+func RequestIDClient() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if id := RequestIDFromContext(ctx); id != "" {
+				if tr, ok := transport.FromClientContext(ctx); ok {
+					tr.RequestHeader().Set(RequestIDHeader, id)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// NewRequestIDContext 将请求ID写入上下文
+func NewRequestIDContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext 获取上下文中的请求ID，不存在时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDValuer 日志字段，输出上下文中的请求ID
+func RequestIDValuer() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		return RequestIDFromContext(ctx)
+	}
+}
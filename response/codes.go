@@ -0,0 +1,124 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// Code 业务错误码，前三位为对应的HTTP状态码，如 40001 -> 400
+type Code int
+
+// 通用错误码
+const (
+	OK                 Code = 0
+	InvalidArgument    Code = 40000
+	ValidationFailed   Code = 40001
+	Unauthorized       Code = 40100
+	TokenExpired       Code = 40101
+	Forbidden          Code = 40300
+	NotFound           Code = 40400
+	Conflict           Code = 40900
+	TooManyRequests    Code = 42900
+	Internal           Code = 50000
+	ServiceUnavailable Code = 50300
+	Timeout            Code = 50400
+)
+
+// 错误元数据中业务错误码的键
+const MetadataCode = "code"
+
+// codeInfo 错误码信息
+type codeInfo struct {
+	reason  string
+	message string
+}
+
+var (
+	mu    sync.RWMutex
+	codes = map[Code]codeInfo{
+		OK:                 {"OK", "成功"},
+		InvalidArgument:    {"INVALID_ARGUMENT", "参数错误"},
+		ValidationFailed:   {"VALIDATION_FAILED", "参数校验失败"},
+		Unauthorized:       {"UNAUTHORIZED", "未认证"},
+		TokenExpired:       {"TOKEN_EXPIRED", "令牌已过期"},
+		Forbidden:          {"FORBIDDEN", "无访问权限"},
+		NotFound:           {"NOT_FOUND", "资源不存在"},
+		Conflict:           {"CONFLICT", "资源冲突"},
+		TooManyRequests:    {"TOO_MANY_REQUESTS", "请求过于频繁"},
+		Internal:           {"INTERNAL", "服务内部错误"},
+		ServiceUnavailable: {"SERVICE_UNAVAILABLE", "服务不可用"},
+		Timeout:            {"TIMEOUT", "请求超时"},
+	}
+)
+
+// Register 注册业务错误码，reason为kratos错误的Reason，message为默认提示
+// 业务码需满足 code/100 为合法HTTP状态码，重复注册会panic
+func Register(code Code, reason, message string) {
+	if http.StatusText(code.HTTPStatus()) == "" {
+		panic(fmt.Sprintf("错误码%d无法映射到HTTP状态码", code))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := codes[code]; ok {
+		panic(fmt.Sprintf("错误码%d已注册", code))
+	}
+	codes[code] = codeInfo{reason: reason, message: message}
+}
+
+// HTTPStatus 对应的HTTP状态码
+func (c Code) HTTPStatus() int {
+	if c == OK {
+		return http.StatusOK
+	}
+	return int(c) / 100
+}
+
+// Reason 错误原因标识
+func (c Code) Reason() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if info, ok := codes[c]; ok {
+		return info.reason
+	}
+	return fmt.Sprintf("CODE_%d", c)
+}
+
+// Message 默认提示信息
+func (c Code) Message() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return codes[c].message
+}
+
+// Error 创建该错误码的kratos错误，msg为空时使用默认提示
+// HTTP状态码由错误码决定，gRPC状态码由kratos按HTTP状态码映射
+func (c Code) Error(msg string) *errors.Error {
+	if msg == "" {
+		msg = c.Message()
+	}
+	return errors.New(c.HTTPStatus(), c.Reason(), msg).
+		WithMetadata(map[string]string{MetadataCode: fmt.Sprint(int(c))})
+}
+
+// Errorf 格式化提示信息创建错误
+func (c Code) Errorf(format string, args ...interface{}) *errors.Error {
+	return c.Error(fmt.Sprintf(format, args...))
+}
+
+// CodeOf 获取错误对应的业务错误码
+// 使用本包创建的错误返回其业务码；其他kratos错误按HTTP状态码映射为 状态码*100；nil返回OK
+func CodeOf(err error) Code {
+	if err == nil {
+		return OK
+	}
+	se := errors.FromError(err)
+	var c int
+	if _, scanErr := fmt.Sscan(se.Metadata[MetadataCode], &c); scanErr == nil {
+		return Code(c)
+	}
+	return Code(se.Code * 100)
+}
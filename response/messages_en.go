@@ -0,0 +1,20 @@
+package response
+
+import "github.com/lnhlg/gbm-common/i18n"
+
+func init() {
+	i18n.Register(i18n.EN, map[string]string{
+		"成功":     "success",
+		"参数错误":   "invalid argument",
+		"参数校验失败": "validation failed",
+		"未认证":    "unauthorized",
+		"令牌已过期":  "token expired",
+		"无访问权限":  "permission denied",
+		"资源不存在":  "resource not found",
+		"资源冲突":   "resource conflict",
+		"请求过于频繁": "too many requests",
+		"服务内部错误": "internal server error",
+		"服务不可用":  "service unavailable",
+		"请求超时":   "request timeout",
+	})
+}
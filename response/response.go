@@ -0,0 +1,128 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lnhlg/gbm-common/i18n"
+)
+
+// Response 统一响应结构
+type Response struct {
+	Code    Code            `json:"code"`
+	Msg     string          `json:"msg"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	TraceID string          `json:"trace_id,omitempty"`
+}
+
+// ServerOptions 返回使用统一响应结构的HTTP服务端选项，可传给 NewHTTPServer
+func ServerOptions() []khttp.ServerOption {
+	return []khttp.ServerOption{
+		khttp.ResponseEncoder(ResponseEncoder),
+		khttp.ErrorEncoder(ErrorEncoder),
+	}
+}
+
+// ClientOptions 返回解析统一响应结构的HTTP客户端选项
+func ClientOptions() []khttp.ClientOption {
+	return []khttp.ClientOption{
+		khttp.WithResponseDecoder(ResponseDecoder),
+		khttp.WithErrorDecoder(ErrorDecoder),
+	}
+}
+
+// ResponseEncoder 将处理结果包装为 {code:0, msg, data, trace_id}
+func ResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	msg := i18n.T(language(r), OK.Message())
+	if v == nil {
+		return write(w, r, http.StatusOK, Response{Code: OK, Msg: msg})
+	}
+	if rd, ok := v.(khttp.Redirector); ok {
+		url, code := rd.Redirect()
+		http.Redirect(w, r, url, code)
+		return nil
+	}
+
+	codec, _ := khttp.CodecForRequest(r, "Accept")
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return write(w, r, http.StatusOK, Response{Code: OK, Msg: msg, Data: data})
+}
+
+// ErrorEncoder 将错误编码为 {code, msg, trace_id}，HTTP状态码取自错误，msg按请求语言翻译
+func ErrorEncoder(w http.ResponseWriter, r *http.Request, err error) {
+	se := errors.FromError(err)
+	msg := i18n.T(language(r), se.Message)
+	_ = write(w, r, int(se.Code), Response{Code: CodeOf(err), Msg: msg})
+}
+
+// ResponseDecoder 解析统一响应结构，将data解码到v
+func ResponseDecoder(_ context.Context, res *http.Response, v interface{}) error {
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+	if resp.Code != OK {
+		return resp.Code.Error(resp.Msg)
+	}
+	if v == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	return khttp.CodecForResponse(res).Unmarshal(resp.Data, v)
+}
+
+// ErrorDecoder 将非2xx响应解析为错误，保留业务错误码
+func ErrorDecoder(_ context.Context, res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return nil
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err == nil {
+		var resp Response
+		if err := json.Unmarshal(body, &resp); err == nil && resp.Code != OK {
+			e := resp.Code.Error(resp.Msg)
+			e.Code = int32(res.StatusCode)
+			return e
+		}
+	}
+	return errors.New(res.StatusCode, errors.UnknownReason, string(body))
+}
+
+// language 请求语言，优先取 Accept-Language 请求头
+func language(r *http.Request) i18n.Lang {
+	if h := r.Header.Get(i18n.HeaderAcceptLanguage); h != "" {
+		return i18n.Parse(h)
+	}
+	return i18n.FromContext(r.Context())
+}
+
+// write 写入响应
+func write(w http.ResponseWriter, r *http.Request, status int, resp Response) error {
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		resp.TraceID = sc.TraceID().String()
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(body)
+	return err
+}
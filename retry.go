@@ -0,0 +1,140 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+// RetryPolicy 重试策略，零值字段使用 DefaultRetryPolicy 中的默认值
+type RetryPolicy struct {
+	MaxAttempts     int                  // 最大尝试次数(含首次)，默认3，<0表示不限次数(直到ctx取消)
+	InitialInterval time.Duration        // 首次重试间隔，默认100ms
+	MaxInterval     time.Duration        // 最大重试间隔，默认10s
+	Multiplier      float64              // 间隔增长倍数，默认2
+	Jitter          float64              // 随机抖动比例[0,1]，默认0.2，即间隔在±20%内浮动
+	Retryable       func(err error) bool // 判断错误是否可重试，默认 IsRetryable
+}
+
+// DefaultRetryPolicy 默认重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     10 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		Retryable:       IsRetryable,
+	}
+}
+
+// permanentError 不可重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 将错误标记为不可重试，Retry遇到后立即返回原错误
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable 默认的错误分类
+// ctx取消/超时和 Permanent 错误不重试；kratos错误中408、429和5xx重试，其余4xx不重试；其他错误重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pe *permanentError
+	if errors.As(err, &pe) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var ke *kerrors.Error
+	if errors.As(err, &ke) {
+		return ke.Code >= 500 || ke.Code == 408 || ke.Code == 429
+	}
+	return true
+}
+
+// Retry 按策略重试fn，直到成功、遇到不可重试的错误、达到最大次数或ctx取消，返回最后一次的错误
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	_, err := RetryValue(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// RetryValue 带返回值的Retry
+func RetryValue[T any](ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) (T, error)) (T, error) {
+	p := policy.withDefaults()
+	interval := p.InitialInterval
+
+	for attempt := 1; ; attempt++ {
+		v, err := fn(ctx)
+		if err == nil {
+			return v, nil
+		}
+
+		var pe *permanentError
+		if errors.As(err, &pe) {
+			return v, pe.err
+		}
+		if !p.Retryable(err) || (p.MaxAttempts > 0 && attempt >= p.MaxAttempts) {
+			return v, err
+		}
+
+		timer := time.NewTimer(jitter(interval, p.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return v, err
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * p.Multiplier)
+		if interval > p.MaxInterval {
+			interval = p.MaxInterval
+		}
+	}
+}
+
+// withDefaults 填充默认值
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.InitialInterval <= 0 {
+		p.InitialInterval = d.InitialInterval
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = d.MaxInterval
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = d.Multiplier
+	}
+	if p.Jitter <= 0 {
+		p.Jitter = d.Jitter
+	}
+	if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	if p.Retryable == nil {
+		p.Retryable = d.Retryable
+	}
+	return p
+}
+
+// jitter 在 d*(1±ratio) 范围内随机取值
+func jitter(d time.Duration, ratio float64) time.Duration {
+	delta := float64(d) * ratio
+	return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+}
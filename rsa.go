@@ -7,10 +7,12 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
-	"errors"
-	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/lnhlg/gbm-common/i18n"
 )
 
 const (
@@ -25,6 +27,12 @@ const (
 
 	// 默认公钥文件名
 	DefaultPublicKeyFile = "public.pem"
+
+	// 默认服务端证书文件名，证书需由私钥对应的CSR签发
+	DefaultCertFile = "cert.pem"
+
+	// 默认客户端CA证书文件名，存在时开启双向认证
+	DefaultClientCAFile = "ca.pem"
 )
 
 // RSAKeyManager 管理RSA密钥的生成、保存和加载
@@ -32,6 +40,8 @@ type RSAKeyManager struct {
 	keyDir         string
 	privateKeyFile string
 	publicKeyFile  string
+	certFile       string
+	clientCAFile   string
 }
 
 // NewRSAKeyManager 创建新的密钥管理器
@@ -40,6 +50,8 @@ func NewRSAKeyManager() *RSAKeyManager {
 		keyDir:         DefaultKeyDir,
 		privateKeyFile: DefaultPrivateKeyFile,
 		publicKeyFile:  DefaultPublicKeyFile,
+		certFile:       DefaultCertFile,
+		clientCAFile:   DefaultClientCAFile,
 	}
 }
 
@@ -56,11 +68,18 @@ func (r *RSAKeyManager) WithKeyFiles(privateFile, publicFile string) *RSAKeyMana
 	return r
 }
 
+// WithCertFiles 设置服务端证书和客户端CA证书文件名
+func (r *RSAKeyManager) WithCertFiles(certFile, clientCAFile string) *RSAKeyManager {
+	r.certFile = certFile
+	r.clientCAFile = clientCAFile
+	return r
+}
+
 // Init 初始化密钥系统
 func (r *RSAKeyManager) Init() error {
 	// 创建密钥目录
 	if err := os.MkdirAll(r.keyDir, 0700); err != nil {
-		return fmt.Errorf("无法创建密钥目录: %w", err)
+		return i18n.Errorf("无法创建密钥目录: %w", err)
 	}
 
 	// 确保密钥存在
@@ -76,7 +95,7 @@ func (r *RSAKeyManager) GenerateKeyPair(keySize int) error {
 
 	privateKey, err := rsa.GenerateKey(rand.Reader, keySize)
 	if err != nil {
-		return fmt.Errorf("密钥生成失败: %w", err)
+		return i18n.Errorf("密钥生成失败: %w", err)
 	}
 
 	// 保存私钥
@@ -95,12 +114,12 @@ func (r *RSAKeyManager) SavePrivateKey(key *rsa.PrivateKey) error {
 	// 使用PKCS#8格式编码私钥
 	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(key)
 	if err != nil {
-		return fmt.Errorf("PKCS#8编码失败: %w", err)
+		return i18n.Errorf("PKCS#8编码失败: %w", err)
 	}
 
 	file, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("创建文件失败: %w", err)
+		return i18n.Errorf("创建文件失败: %w", err)
 	}
 	defer file.Close()
 
@@ -110,7 +129,7 @@ func (r *RSAKeyManager) SavePrivateKey(key *rsa.PrivateKey) error {
 	}
 
 	if err := pem.Encode(file, block); err != nil {
-		return fmt.Errorf("PEM编码失败: %w", err)
+		return i18n.Errorf("PEM编码失败: %w", err)
 	}
 
 	return nil
@@ -122,13 +141,13 @@ func (r *RSAKeyManager) SavePublicKey(key *rsa.PublicKey) error {
 
 	file, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("创建文件失败: %w", err)
+		return i18n.Errorf("创建文件失败: %w", err)
 	}
 	defer file.Close()
 
 	publicKeyBytes, err := x509.MarshalPKIXPublicKey(key)
 	if err != nil {
-		return fmt.Errorf("公钥序列化失败: %w", err)
+		return i18n.Errorf("公钥序列化失败: %w", err)
 	}
 
 	publicKeyPEM := &pem.Block{
@@ -145,7 +164,7 @@ func (r *RSAKeyManager) LoadPrivateKey() (*rsa.PrivateKey, error) {
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("读取文件失败: %w", err)
+		return nil, i18n.Errorf("读取文件失败: %w", err)
 	}
 
 	return ParsePrivateKeyPEM(string(data))
@@ -157,7 +176,7 @@ func (r *RSAKeyManager) LoadPublicKey() (*rsa.PublicKey, error) {
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("读取文件失败: %w", err)
+		return nil, i18n.Errorf("读取文件失败: %w", err)
 	}
 
 	return ParsePublicKeyPEM(string(data))
@@ -173,6 +192,28 @@ func (r *RSAKeyManager) PublicKeyPath() string {
 	return filepath.Join(r.keyDir, r.publicKeyFile)
 }
 
+// CertPath 获取服务端证书路径
+func (r *RSAKeyManager) CertPath() string {
+	return filepath.Join(r.keyDir, r.certFile)
+}
+
+// ClientCAPath 获取客户端CA证书路径，文件不存在时返回空字符串
+func (r *RSAKeyManager) ClientCAPath() string {
+	if r.clientCAFile == "" {
+		return ""
+	}
+	path := filepath.Join(r.keyDir, r.clientCAFile)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// CertReloader 使用密钥目录中的私钥和证书创建证书热加载器，目录中存在客户端CA时开启双向认证
+func (r *RSAKeyManager) CertReloader(logger log.Logger) (*CertReloader, error) {
+	return NewCertReloader(r.CertPath(), r.PrivateKeyPath(), r.ClientCAPath(), logger)
+}
+
 // 确保密钥存在（内部使用）
 func (r *RSAKeyManager) ensureKeys() (*rsa.PrivateKey, error) {
 	privPath := r.PrivateKeyPath()
@@ -184,7 +225,7 @@ func (r *RSAKeyManager) ensureKeys() (*rsa.PrivateKey, error) {
 	// 生成新密钥对
 	privateKey, err := rsa.GenerateKey(rand.Reader, DefaultKeySize)
 	if err != nil {
-		return nil, fmt.Errorf("密钥生成失败: %w", err)
+		return nil, i18n.Errorf("密钥生成失败: %w", err)
 	}
 
 	// 保存私钥
@@ -214,7 +255,7 @@ func NewRSAEncryptorFromKey(publicKey *rsa.PublicKey) *RSAEncryptor {
 func NewRSAEncryptorFromFile(publicKeyPath string) (*RSAEncryptor, error) {
 	data, err := os.ReadFile(publicKeyPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取公钥文件失败: %w", err)
+		return nil, i18n.Errorf("读取公钥文件失败: %w", err)
 	}
 	return NewRSAEncryptorFromPEM(string(data))
 }
@@ -233,7 +274,7 @@ func (e *RSAEncryptor) Encrypt(text string) (string, error) {
 	// 检查文本长度
 	maxLen := e.publicKey.Size() - 2*sha256.New().Size() - 2
 	if len(text) > maxLen {
-		return "", fmt.Errorf("文本过长(最大%d字符)，请缩短内容", maxLen)
+		return "", i18n.Errorf("文本过长(最大%d字符)，请缩短内容", maxLen)
 	}
 
 	// 加密
@@ -245,7 +286,7 @@ func (e *RSAEncryptor) Encrypt(text string) (string, error) {
 		nil,
 	)
 	if err != nil {
-		return "", fmt.Errorf("加密失败: %w", err)
+		return "", i18n.Errorf("加密失败: %w", err)
 	}
 
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
@@ -265,7 +306,7 @@ func NewRSADecryptorFromKey(privateKey *rsa.PrivateKey) *RSADecryptor {
 func NewRSADecryptorFromFile(privateKeyPath string) (*RSADecryptor, error) {
 	data, err := os.ReadFile(privateKeyPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取私钥文件失败: %w", err)
+		return nil, i18n.Errorf("读取私钥文件失败: %w", err)
 	}
 	return NewRSADecryptorFromPEM(string(data))
 }
@@ -284,7 +325,7 @@ func (d *RSADecryptor) Decrypt(encryptedText string) (string, error) {
 	// 解码Base64
 	ciphertext, err := base64.StdEncoding.DecodeString(encryptedText)
 	if err != nil {
-		return "", fmt.Errorf("Base64解码失败: %w", err)
+		return "", i18n.Errorf("Base64解码失败: %w", err)
 	}
 
 	// 解密
@@ -296,7 +337,7 @@ func (d *RSADecryptor) Decrypt(encryptedText string) (string, error) {
 		nil,
 	)
 	if err != nil {
-		return "", fmt.Errorf("解密失败: %w", err)
+		return "", i18n.Errorf("解密失败: %w", err)
 	}
 
 	return string(plaintext), nil
@@ -306,7 +347,7 @@ func (d *RSADecryptor) Decrypt(encryptedText string) (string, error) {
 func ParsePrivateKeyPEM(pemText string) (*rsa.PrivateKey, error) {
 	block, _ := pem.Decode([]byte(pemText))
 	if block == nil {
-		return nil, errors.New("无效的PEM格式")
+		return nil, i18n.Errorf("无效的PEM格式")
 	}
 
 	// 尝试解析为PKCS#8格式
@@ -315,7 +356,7 @@ func ParsePrivateKeyPEM(pemText string) (*rsa.PrivateKey, error) {
 		case *rsa.PrivateKey:
 			return key, nil
 		default:
-			return nil, errors.New("不是RSA私钥")
+			return nil, i18n.Errorf("不是RSA私钥")
 		}
 	}
 
@@ -324,28 +365,28 @@ func ParsePrivateKeyPEM(pemText string) (*rsa.PrivateKey, error) {
 		return key, nil
 	}
 
-	return nil, errors.New("无法解析私钥格式")
+	return nil, i18n.Errorf("无法解析私钥格式")
 }
 
 // ParsePublicKeyPEM 从PEM文本解析公钥
 func ParsePublicKeyPEM(pemText string) (*rsa.PublicKey, error) {
 	block, _ := pem.Decode([]byte(pemText))
 	if block == nil {
-		return nil, errors.New("无效的PEM格式")
+		return nil, i18n.Errorf("无效的PEM格式")
 	}
 
 	if block.Type != "PUBLIC KEY" {
-		return nil, fmt.Errorf("不是公钥: %s", block.Type)
+		return nil, i18n.Errorf("不是公钥: %s", block.Type)
 	}
 
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return nil, fmt.Errorf("解析公钥失败: %w", err)
+		return nil, i18n.Errorf("解析公钥失败: %w", err)
 	}
 
 	rsaPub, ok := pub.(*rsa.PublicKey)
 	if !ok {
-		return nil, errors.New("不是RSA公钥")
+		return nil, i18n.Errorf("不是RSA公钥")
 	}
 
 	return rsaPub, nil
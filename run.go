@@ -0,0 +1,60 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// DefaultRegistrarTimeout 注册中心注册、注销本身的超时时间（与kratos默认值一致）
+const DefaultRegistrarTimeout = 10 * time.Second
+
+// Run 组装kratos应用并阻塞运行，直到收到退出信号
+// 启动服务前执行Lifecycle启动钩子，启动后向注册中心注册；退出时先按ShutdownManager流程注销、摘流、执行钩子，
+// 再停止服务(停止接收流量)，之后按Lifecycle顺序排空任务、刷新指标日志、关闭客户端
+// 注册和注销都由本库完成，不交给kratos，避免退出时重复注销
+func (r *appResult) Run(servers ...transport.Server) error {
+	defer r.Close()
+
+	app := kratos.New(
+		kratos.ID(r.id),
+		kratos.Name(r.name),
+		kratos.Version(r.version),
+		kratos.Metadata(r.Metadata),
+		kratos.Logger(r.Logger),
+		kratos.Server(servers...),
+		kratos.BeforeStart(func(ctx context.Context) error {
+			return r.Lifecycle.Start(ctx)
+		}),
+		kratos.AfterStart(func(ctx context.Context) error {
+			info, ok := kratos.FromContext(ctx)
+			if !ok || r.Reg == nil {
+				return nil
+			}
+			ins := &registry.ServiceInstance{
+				ID:        info.ID(),
+				Name:      info.Name(),
+				Version:   info.Version(),
+				Metadata:  info.Metadata(),
+				Endpoints: info.Endpoint(),
+			}
+			rctx, cancel := context.WithTimeout(ctx, r.registrarTimeout)
+			defer cancel()
+			if err := r.Reg.Register(rctx, ins); err != nil {
+				return err
+			}
+			r.Shutdown.SetInstance(ins)
+			return nil
+		}),
+		kratos.BeforeStop(func(ctx context.Context) error {
+			return r.Shutdown.Shutdown(ctx)
+		}),
+		kratos.AfterStop(func(context.Context) error {
+			return r.Lifecycle.Stop(context.Background())
+		}),
+	)
+	return app.Run()
+}
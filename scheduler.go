@@ -0,0 +1,222 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/robfig/cron/v3"
+)
+
+var _ transport.Server = (*Scheduler)(nil)
+
+// cronParser 支持5段或6段(带秒)cron表达式以及 @every、@daily 等描述符
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Job 定时任务
+type Job func(ctx context.Context) error
+
+// JobOption 定时任务选项
+type JobOption func(*scheduledJob)
+
+// WithJobLock 任务执行前获取分布式锁，多副本部署时同一时刻只有一个副本执行
+// 获取失败时跳过本次执行；执行期间自动续期
+func WithJobLock(l DistributedLock, ttl time.Duration) JobOption {
+	return func(j *scheduledJob) {
+		j.lock = l
+		j.lockTTL = ttl
+	}
+}
+
+// WithJobTimeout 设置单次执行超时
+func WithJobTimeout(d time.Duration) JobOption {
+	return func(j *scheduledJob) { j.timeout = d }
+}
+
+// scheduledJob 已注册的任务
+type scheduledJob struct {
+	name     string
+	schedule cron.Schedule
+	fn       Job
+	lock     DistributedLock
+	lockTTL  time.Duration
+	timeout  time.Duration
+	running  sync.Mutex
+}
+
+// Scheduler 定时任务调度器，实现 transport.Server，可直接传给 appResult.Run 随应用启停
+// 上一次执行未结束时跳过本次执行；任务panic会被恢复并记录
+type Scheduler struct {
+	logger *log.Helper
+	jobs   []*scheduledJob
+
+	runs    *Counter
+	seconds *Histogram
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler 创建调度器，metrics为nil时不记录指标
+func NewScheduler(logger log.Logger, metrics *Metrics) (*Scheduler, error) {
+	if logger == nil {
+		logger = log.GetLogger()
+	}
+	s := &Scheduler{logger: log.NewHelper(logger)}
+	if metrics != nil {
+		var err error
+		if s.runs, err = metrics.Counter("scheduler_job_runs_total", "定时任务执行次数", "job", "result"); err != nil {
+			return nil, err
+		}
+		if s.seconds, err = metrics.Histogram("scheduler_job_seconds", "定时任务执行耗时",
+			[]float64{.1, .5, 1, 5, 10, 30, 60, 300, 900}, "job"); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Cron 注册cron表达式任务，如 "0 2 * * *"、"*/10 * * * * *"、"@daily"
+func (s *Scheduler) Cron(name, spec string, fn Job, opts ...JobOption) error {
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("无效的cron表达式(%s): %w", spec, err)
+	}
+	return s.add(name, schedule, fn, opts)
+}
+
+// Every 注册固定间隔任务
+func (s *Scheduler) Every(name string, interval time.Duration, fn Job, opts ...JobOption) error {
+	if interval <= 0 {
+		return fmt.Errorf("任务间隔必须大于0: %s", name)
+	}
+	return s.add(name, intervalSchedule(interval), fn, opts)
+}
+
+// Start 启动全部任务，调用后立即返回
+func (s *Scheduler) Start(ctx context.Context) error {
+	ctx, s.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+	return nil
+}
+
+// Stop 停止调度并等待执行中的任务结束或ctx超时
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// add 添加任务
+func (s *Scheduler) add(name string, schedule cron.Schedule, fn Job, opts []JobOption) error {
+	j := &scheduledJob{name: name, schedule: schedule, fn: fn}
+	for _, o := range opts {
+		o(j)
+	}
+	if j.lock != nil && j.lockTTL <= 0 {
+		return fmt.Errorf("任务锁的有效期必须大于0: %s", name)
+	}
+	s.jobs = append(s.jobs, j)
+	return nil
+}
+
+// loop 按计划循环执行任务
+func (s *Scheduler) loop(ctx context.Context, j *scheduledJob) {
+	defer s.wg.Done()
+	for {
+		now := time.Now()
+		timer := time.NewTimer(j.schedule.Next(now).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !j.running.TryLock() {
+			s.logger.Warnf("定时任务上次执行未结束，跳过本次执行: %s", j.name)
+			s.record(ctx, j.name, "skipped")
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer j.running.Unlock()
+			s.run(ctx, j)
+		}()
+	}
+}
+
+// run 执行一次任务
+func (s *Scheduler) run(ctx context.Context, j *scheduledJob) {
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result := "success"
+	err := func() (err error) {
+		defer func() {
+			if e := recover(); e != nil {
+				result = "panic"
+				err = fmt.Errorf("panic: %v", e)
+			}
+		}()
+		if j.lock != nil {
+			return WithLock(ctx, j.lock, "job:"+j.name, j.lockTTL, j.fn)
+		}
+		return j.fn(ctx)
+	}()
+
+	switch {
+	case errors.Is(err, ErrLockNotAcquired):
+		result = "skipped"
+	case err != nil:
+		if result != "panic" {
+			result = "error"
+		}
+		s.logger.Errorf("定时任务执行失败(%s): %v", j.name, err)
+	}
+
+	if s.seconds != nil && result != "skipped" {
+		s.seconds.Observe(ctx, time.Since(start).Seconds(), j.name)
+	}
+	s.record(ctx, j.name, result)
+}
+
+// intervalSchedule 固定间隔计划，cron.Every会将间隔取整到秒，这里保留原始精度
+type intervalSchedule time.Duration
+
+// Next 下次执行时间
+func (d intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
+
+// record 记录执行结果
+func (s *Scheduler) record(ctx context.Context, name, result string) {
+	if s.runs != nil {
+		s.runs.Inc(ctx, name, result)
+	}
+}
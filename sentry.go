@@ -0,0 +1,115 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+)
+
+const (
+	// 关闭时等待Sentry上报完成的时间
+	DefaultSentryFlushTimeout = 2 * time.Second
+)
+
+// SentryConfig Sentry错误上报配置
+type SentryConfig struct {
+	DSN         string  `yaml:"dsn"`
+	Environment string  `yaml:"environment"`
+	SampleRate  float64 `yaml:"sample_rate"` // 事件采样率，0表示全部上报
+}
+
+// InitSentry 初始化Sentry客户端，附带服务信息
+func InitSentry(cfg SentryConfig, id, name, version string) error {
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         cfg.DSN,
+		Environment: cfg.Environment,
+		SampleRate:  cfg.SampleRate,
+		Release:     name + "@" + version,
+		ServerName:  id,
+	})
+	if err != nil {
+		return fmt.Errorf("初始化Sentry失败: %w", err)
+	}
+
+	sentry.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("service.id", id)
+		scope.SetTag("service.name", name)
+		scope.SetTag("service.version", version)
+	})
+	return nil
+}
+
+// FlushSentry 等待未发送的事件上报完成
+func FlushSentry() error {
+	sentry.Flush(DefaultSentryFlushTimeout)
+	return nil
+}
+
+var _ log.Logger = (*sentryLogger)(nil)
+
+// sentryLogger 将Error及以上级别的日志转发到Sentry
+type sentryLogger struct {
+	logger log.Logger
+}
+
+// NewSentryLogger 包装日志，Error及以上级别的日志同时上报到Sentry
+func NewSentryLogger(logger log.Logger) log.Logger {
+	return &sentryLogger{logger: logger}
+}
+
+// Log 实现 log.Logger
+func (l *sentryLogger) Log(level log.Level, keyvals ...interface{}) error {
+	if level >= log.LevelError {
+		captureLog(level, keyvals)
+	}
+	return l.logger.Log(level, keyvals...)
+}
+
+// captureLog 将日志转换为Sentry事件
+func captureLog(level log.Level, keyvals []interface{}) {
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	if level == log.LevelFatal {
+		event.Level = sentry.LevelFatal
+	}
+
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		val := fmt.Sprint(keyvals[i+1])
+		switch key {
+		case log.DefaultMessageKey:
+			event.Message = val
+		case "trace.id", "span.id":
+			if val != "" {
+				event.Tags[key] = val
+			}
+		default:
+			event.Extra[key] = val
+		}
+	}
+
+	sentry.CaptureEvent(event)
+}
+
+// SentryRecoveryHandler 恢复panic时上报到Sentry，配合 recovery.WithHandler 使用
+func SentryRecoveryHandler() recovery.HandlerFunc {
+	return func(ctx context.Context, req, err interface{}) error {
+		hub := sentry.CurrentHub().Clone()
+		hub.ConfigureScope(func(scope *sentry.Scope) {
+			if id := tracing.TraceID()(ctx); id != "" {
+				scope.SetTag("trace.id", fmt.Sprint(id))
+			}
+			if id := tracing.SpanID()(ctx); id != "" {
+				scope.SetTag("span.id", fmt.Sprint(id))
+			}
+			scope.SetExtra("request", fmt.Sprintf("%+v", req))
+		})
+		hub.Recover(err)
+		return recovery.ErrUnknownRequest
+	}
+}
@@ -0,0 +1,173 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+	kgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	// 服务端配置键
+	ServerConfigKey = "server"
+
+	// 默认HTTP监听地址
+	DefaultHTTPAddr = "0.0.0.0:8000"
+
+	// 默认gRPC监听地址
+	DefaultGRPCAddr = "0.0.0.0:9000"
+)
+
+// ServerTLS 服务端证书配置
+// 设置 key_dir 时使用 RSAKeyManager 管理的目录(private.pem、cert.pem，存在ca.pem时开启双向认证)，
+// 否则使用 cert_file、key_file 和可选的 client_ca_file
+type ServerTLS struct {
+	CertFile       string   `json:"cert_file"`
+	KeyFile        string   `json:"key_file"`
+	ClientCAFile   string   `json:"client_ca_file"`  // 非空时要求并校验客户端证书
+	KeyDir         string   `json:"key_dir"`         // 密钥目录
+	ReloadInterval Duration `json:"reload_interval"` // 证书文件变化检查间隔，默认30s
+}
+
+// ServerConfig 单个服务端配置
+type ServerConfig struct {
+	Network string     `json:"network"` // 默认tcp
+	Addr    string     `json:"addr"`
+	Timeout Duration   `json:"timeout"` // 请求超时，0时使用kratos默认值1s
+	TLS     *ServerTLS `json:"tls"`     // nil表示不启用TLS
+}
+
+// ServersConfig 服务端配置，对应配置中的 server 节点
+type ServersConfig struct {
+	HTTP ServerConfig `json:"http"`
+	GRPC ServerConfig `json:"grpc"`
+}
+
+// NewHTTPServer 按配置的 server.http 节点创建HTTP服务
+// 默认挂载标准中间件、健康检查和 /metrics，opts可覆盖默认设置
+func NewHTTPServer(r *appResult, opts ...khttp.ServerOption) (*khttp.Server, error) {
+	cfg, err := serversConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	c := cfg.HTTP
+	if c.Addr == "" {
+		c.Addr = DefaultHTTPAddr
+	}
+
+	sopts := []khttp.ServerOption{
+		khttp.Address(c.Addr),
+		khttp.Middleware(DefaultMiddlewares(r.Logger, r.Metrics, r.Tracer)...),
+		khttp.Logger(r.Logger),
+	}
+	if c.Network != "" {
+		sopts = append(sopts, khttp.Network(c.Network))
+	}
+	if c.Timeout > 0 {
+		sopts = append(sopts, khttp.Timeout(c.Timeout.Std()))
+	}
+	if c.TLS != nil {
+		tc, err := serverTLSConfig(r, c.TLS)
+		if err != nil {
+			return nil, err
+		}
+		sopts = append(sopts, khttp.TLSConfig(tc))
+	}
+
+	srv := khttp.NewServer(append(sopts, opts...)...)
+	if r.Health != nil {
+		r.Health.Register(srv)
+	}
+	if r.Metrics != nil {
+		r.Metrics.Register(srv)
+	}
+	return srv, nil
+}
+
+// NewGRPCServer 按配置的 server.grpc 节点创建gRPC服务，默认挂载标准中间件，opts可覆盖默认设置
+func NewGRPCServer(r *appResult, opts ...kgrpc.ServerOption) (*kgrpc.Server, error) {
+	cfg, err := serversConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	c := cfg.GRPC
+	if c.Addr == "" {
+		c.Addr = DefaultGRPCAddr
+	}
+
+	sopts := []kgrpc.ServerOption{
+		kgrpc.Address(c.Addr),
+		kgrpc.Middleware(DefaultMiddlewares(r.Logger, r.Metrics, r.Tracer)...),
+		kgrpc.Logger(r.Logger),
+	}
+	if c.Network != "" {
+		sopts = append(sopts, kgrpc.Network(c.Network))
+	}
+	if c.Timeout > 0 {
+		sopts = append(sopts, kgrpc.Timeout(c.Timeout.Std()))
+	}
+	if c.TLS != nil {
+		tc, err := serverTLSConfig(r, c.TLS)
+		if err != nil {
+			return nil, err
+		}
+		sopts = append(sopts, kgrpc.TLSConfig(tc))
+	}
+
+	return kgrpc.NewServer(append(sopts, opts...)...), nil
+}
+
+// Config 加载证书生成TLS配置，不热加载证书
+func (t *ServerTLS) Config() (*tls.Config, error) {
+	rl, err := t.Reloader(log.DefaultLogger)
+	if err != nil {
+		return nil, err
+	}
+	return rl.TLSConfig(), nil
+}
+
+// Reloader 按配置创建证书热加载器，需调用 Start 开启热加载
+func (t *ServerTLS) Reloader(logger log.Logger) (*CertReloader, error) {
+	var (
+		rl  *CertReloader
+		err error
+	)
+	if t.KeyDir != "" {
+		rl, err = NewRSAKeyManager().WithKeyDir(t.KeyDir).CertReloader(logger)
+	} else {
+		rl, err = NewCertReloader(t.CertFile, t.KeyFile, t.ClientCAFile, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rl.WithInterval(t.ReloadInterval.Std()), nil
+}
+
+// serverTLSConfig 创建热加载证书的TLS配置，随应用关闭停止热加载
+func serverTLSConfig(r *appResult, t *ServerTLS) (*tls.Config, error) {
+	rl, err := t.Reloader(r.Logger)
+	if err != nil {
+		return nil, err
+	}
+	rl.Start()
+	if r.Lifecycle != nil {
+		r.Lifecycle.OnStop("tls-reload", PriorityClose, func(context.Context) error {
+			rl.Stop()
+			return nil
+		})
+	}
+	return rl.TLSConfig(), nil
+}
+
+// serversConfig 读取 server 节点，不存在时返回零值
+func serversConfig(r *appResult) (ServersConfig, error) {
+	var cfg ServersConfig
+	if err := r.Cfg.Value(ServerConfigKey).Scan(&cfg); err != nil && err != config.ErrNotFound {
+		return cfg, fmt.Errorf("解码配置失败(%s): %w", ServerConfigKey, err)
+	}
+	return cfg, nil
+}
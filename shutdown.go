@@ -0,0 +1,155 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const (
+	// 默认摘流等待时间
+	DefaultDrainPeriod = 5 * time.Second
+
+	// 默认关闭超时时间
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// ShutdownHook 关闭钩子
+type ShutdownHook func(ctx context.Context) error
+
+// ShutdownManager 优雅下线管理器
+// 收到SIGTERM/SIGINT后依次执行：从注册中心注销 → 等待摘流 → 执行用户钩子 → 刷新指标
+type ShutdownManager struct {
+	mu       sync.Mutex
+	reg      registry.Registrar
+	instance *registry.ServiceInstance
+	metrics  *Metrics
+	logger   *log.Helper
+	drain    time.Duration
+	timeout  time.Duration
+	hooks    []ShutdownHook
+	once     sync.Once
+	err      error
+}
+
+// NewShutdownManager 创建优雅下线管理器
+func NewShutdownManager(reg registry.Registrar, logger log.Logger) *ShutdownManager {
+	return &ShutdownManager{
+		reg:     reg,
+		logger:  log.NewHelper(logger),
+		drain:   DefaultDrainPeriod,
+		timeout: DefaultShutdownTimeout,
+	}
+}
+
+// WithDrain 设置注销后等待流量排空的时间
+func (m *ShutdownManager) WithDrain(d time.Duration) *ShutdownManager {
+	m.drain = d
+	return m
+}
+
+// WithTimeout 设置整个关闭流程的超时时间
+func (m *ShutdownManager) WithTimeout(d time.Duration) *ShutdownManager {
+	m.timeout = d
+	return m
+}
+
+// WithMetrics 设置关闭时需要刷新的指标
+func (m *ShutdownManager) WithMetrics(metrics *Metrics) *ShutdownManager {
+	m.metrics = metrics
+	return m
+}
+
+// SetInstance 设置已注册的服务实例，关闭时从注册中心注销
+func (m *ShutdownManager) SetInstance(ins *registry.ServiceInstance) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.instance = ins
+}
+
+// OnShutdown 注册关闭钩子，按注册顺序执行
+func (m *ShutdownManager) OnShutdown(hook ShutdownHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Wait 阻塞直到收到退出信号或ctx取消，然后执行关闭流程
+func (m *ShutdownManager) Wait(ctx context.Context) error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sig)
+
+	select {
+	case s := <-sig:
+		m.logger.Infof("收到退出信号: %v", s)
+	case <-ctx.Done():
+	}
+
+	return m.Shutdown(context.Background())
+}
+
+// Shutdown 执行关闭流程，多次调用只执行一次
+func (m *ShutdownManager) Shutdown(ctx context.Context) error {
+	m.once.Do(func() {
+		m.err = m.shutdown(ctx)
+	})
+	return m.err
+}
+
+// shutdown 关闭流程
+func (m *ShutdownManager) shutdown(ctx context.Context) error {
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+
+	m.mu.Lock()
+	ins := m.instance
+	hooks := append([]ShutdownHook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	var errs []error
+
+	// Step1: 从注册中心注销，停止接收新流量
+	if m.reg != nil && ins != nil {
+		if err := m.reg.Deregister(ctx, ins); err != nil {
+			m.logger.Errorf("注销服务实例失败: %v", err)
+			errs = append(errs, err)
+		}
+	}
+
+	// Step2: 等待调用方刷新实例列表，排空在途请求
+	if m.drain > 0 {
+		select {
+		case <-time.After(m.drain):
+		case <-ctx.Done():
+		}
+	}
+
+	// Step3: 执行用户钩子
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			m.logger.Errorf("关闭钩子执行失败: %v", err)
+			errs = append(errs, err)
+		}
+	}
+
+	// Step4: 刷新指标
+	if m.metrics != nil {
+		if err := m.metrics.Shutdown(ctx); err != nil {
+			m.logger.Errorf("刷新指标失败: %v", err)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,214 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const (
+	// 雪花ID配置键
+	SnowflakeConfigKey = "snowflake"
+
+	// 注册元数据中的workerID键
+	WorkerIDMetadataKey = "worker_id"
+
+	// 最大workerID
+	MaxWorkerID = 1<<workerBits - 1
+
+	// 默认workerID租约有效期，持有期间每隔1/3有效期续期
+	DefaultWorkerIDLeaseTTL = 30 * time.Second
+
+	workerBits   = 10
+	sequenceBits = 12
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// snowflakeEpoch 起始时间 2024-01-01 UTC，41位毫秒时间戳可用约69年
+var snowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+// SnowflakeConfig 雪花ID配置，对应配置中的 snowflake 节点
+type SnowflakeConfig struct {
+	WorkerID *int64 `json:"worker_id"` // 为nil时通过workerID锁预留，见 (*app).WithWorkerIDLock
+}
+
+// Snowflake 雪花ID生成器：41位毫秒时间戳 + 10位workerID + 12位序列号
+type Snowflake struct {
+	mu       sync.Mutex
+	workerID int64
+	last     int64
+	sequence int64
+}
+
+// NewSnowflake 创建雪花ID生成器，workerID取值 [0, 1023]
+func NewSnowflake(workerID int64) (*Snowflake, error) {
+	if workerID < 0 || workerID > MaxWorkerID {
+		return nil, fmt.Errorf("workerID超出范围[0, %d]: %d", MaxWorkerID, workerID)
+	}
+	return &Snowflake{workerID: workerID}, nil
+}
+
+// WorkerID 返回workerID
+func (s *Snowflake) WorkerID() int64 {
+	return s.workerID
+}
+
+// Next 生成下一个ID，时钟回拨时等待时钟追上
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	if now < s.last {
+		time.Sleep(time.Duration(s.last-now) * time.Millisecond)
+		now = time.Now().UnixMilli()
+	}
+	if now == s.last {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			for now <= s.last {
+				now = time.Now().UnixMilli()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.last = now
+
+	return (now-snowflakeEpoch)<<(workerBits+sequenceBits) | s.workerID<<sequenceBits | s.sequence
+}
+
+// defaultSnowflake 全局ID生成器，由Init根据配置或注册中心初始化
+var defaultSnowflake atomic.Pointer[Snowflake]
+
+// SetIDGenerator 设置全局ID生成器
+func SetIDGenerator(s *Snowflake) {
+	defaultSnowflake.Store(s)
+}
+
+// NextID 使用全局生成器生成ID
+// 未初始化时按主机名哈希得到workerID，多副本部署时应先通过Init或SetIDGenerator初始化
+func NextID() int64 {
+	s := defaultSnowflake.Load()
+	if s == nil {
+		s, _ = NewSnowflake(hostWorkerID())
+		if !defaultSnowflake.CompareAndSwap(nil, s) {
+			s = defaultSnowflake.Load()
+		}
+	}
+	return s.Next()
+}
+
+// NextIDString 生成字符串形式的ID
+func NextIDString() string {
+	return strconv.FormatInt(NextID(), 10)
+}
+
+// AllocateWorkerID 从注册中心中同名服务已用的workerID(实例元数据worker_id)中选出最小的空闲值
+// 只读取注册中心，同时启动的多个实例可能得到相同的值，多副本部署应使用 ReserveWorkerID
+func AllocateWorkerID(ctx context.Context, dis registry.Discovery, serviceName string) (int64, error) {
+	used, err := usedWorkerIDs(ctx, dis, serviceName)
+	if err != nil {
+		return 0, err
+	}
+	for id := int64(0); id <= MaxWorkerID; id++ {
+		if _, ok := used[id]; !ok {
+			return id, nil
+		}
+	}
+	return 0, errors.New("workerID已耗尽")
+}
+
+// ReserveWorkerID 通过分布式锁为workerID加租约，保证同名服务的实例之间workerID唯一
+// 依次尝试注册中心中未使用的workerID，返回第一个加锁成功的值及租约；
+// 调用方需在实例运行期间续期（见 KeepWorkerIDLease），退出时释放
+func ReserveWorkerID(ctx context.Context, l DistributedLock, dis registry.Discovery, serviceName string, ttl time.Duration) (int64, *LockLease, error) {
+	used, err := usedWorkerIDs(ctx, dis, serviceName)
+	if err != nil {
+		return 0, nil, err
+	}
+	for id := int64(0); id <= MaxWorkerID; id++ {
+		if _, ok := used[id]; ok {
+			continue
+		}
+		lease, err := l.TryAcquire(ctx, workerIDLockKey(serviceName, id), ttl)
+		if errors.Is(err, ErrLockNotAcquired) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("预留workerID失败: %w", err)
+		}
+		return id, lease, nil
+	}
+	return 0, nil, errors.New("workerID已耗尽")
+}
+
+// KeepWorkerIDLease 后台每隔ttl/3续期workerID租约，返回的函数停止续期并释放租约
+// 续期失败时调用onLost，此时workerID可能已被其他实例占用
+func KeepWorkerIDLease(l DistributedLock, lease *LockLease, ttl time.Duration, onLost func(error)) func(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.Renew(ctx, lease, ttl); err != nil && ctx.Err() == nil {
+					if onLost != nil {
+						onLost(err)
+					}
+					if errors.Is(err, ErrLockLost) {
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return func(ctx context.Context) error {
+		cancel()
+		<-done
+		return l.Release(ctx, lease)
+	}
+}
+
+// usedWorkerIDs 注册中心中同名服务已用的workerID，服务没有实例时为空
+func usedWorkerIDs(ctx context.Context, dis registry.Discovery, serviceName string) (map[int64]struct{}, error) {
+	instances, err := dis.GetService(ctx, serviceName)
+	if err != nil && !errors.Is(err, ErrNoServiceInstance) {
+		return nil, fmt.Errorf("查询已用workerID失败: %w", err)
+	}
+
+	used := make(map[int64]struct{}, len(instances))
+	for _, in := range instances {
+		if id, err := strconv.ParseInt(in.Metadata[WorkerIDMetadataKey], 10, 64); err == nil {
+			used[id] = struct{}{}
+		}
+	}
+	return used, nil
+}
+
+// workerIDLockKey workerID租约的锁键
+func workerIDLockKey(serviceName string, id int64) string {
+	return fmt.Sprintf("snowflake:%s:worker:%d", serviceName, id)
+}
+
+// hostWorkerID 按主机名和进程号哈希得到workerID
+func hostWorkerID() int64 {
+	host, _ := os.Hostname()
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(host + ":" + strconv.Itoa(os.Getpid())))
+	return int64(h.Sum32() % (MaxWorkerID + 1))
+}
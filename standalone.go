@@ -0,0 +1,169 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	nacosconfig "github.com/go-kratos/kratos/contrib/config/nacos/v2"
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const (
+	// 开启单机模式的环境变量，取值true/1
+	StandaloneEnv = "APP_STANDALONE"
+
+	// 单机模式下静态服务地址的配置键，格式为 服务名 -> endpoint列表
+	StandaloneServicesKey = "standalone.services"
+)
+
+var (
+	_ registry.Registrar = (*StaticRegistry)(nil)
+	_ registry.Discovery = (*StaticRegistry)(nil)
+)
+
+// StaticRegistry 单机模式使用的注册中心
+// 注册和注销为空操作，发现返回配置的静态地址，如 {"order.grpc": ["grpc://127.0.0.1:9001"]}
+type StaticRegistry struct {
+	mu       sync.RWMutex
+	services map[string][]string
+	changed  chan struct{} // 地址变化时关闭并替换，用于唤醒监听器
+}
+
+// NewStaticRegistry 创建静态注册中心
+func NewStaticRegistry(services map[string][]string) *StaticRegistry {
+	return &StaticRegistry{
+		services: services,
+		changed:  make(chan struct{}),
+	}
+}
+
+// SetServices 替换全部静态地址并通知监听器
+func (r *StaticRegistry) SetServices(services map[string][]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.services = services
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Register 单机模式不注册
+func (r *StaticRegistry) Register(_ context.Context, _ *registry.ServiceInstance) error {
+	return nil
+}
+
+// Deregister 单机模式不注销
+func (r *StaticRegistry) Deregister(_ context.Context, _ *registry.ServiceInstance) error {
+	return nil
+}
+
+// GetService 返回服务的静态地址，每个endpoint作为一个实例
+func (r *StaticRegistry) GetService(_ context.Context, serviceName string) ([]*registry.ServiceInstance, error) {
+	items, _ := r.instances(serviceName)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: 单机模式未配置服务地址: %s", ErrNoServiceInstance, serviceName)
+	}
+	return items, nil
+}
+
+// Watch 监听静态地址变化
+func (r *StaticRegistry) Watch(ctx context.Context, serviceName string) (registry.Watcher, error) {
+	w := &staticWatcher{registry: r, serviceName: serviceName, first: true}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	return w, nil
+}
+
+// instances 生成服务实例，同时返回当前的变化通知通道
+func (r *StaticRegistry) instances(serviceName string) ([]*registry.ServiceInstance, chan struct{}) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	endpoints := append([]string(nil), r.services[serviceName]...)
+	sort.Strings(endpoints)
+	items := make([]*registry.ServiceInstance, 0, len(endpoints))
+	for i, ep := range endpoints {
+		items = append(items, &registry.ServiceInstance{
+			ID:        serviceName + "-" + strconv.Itoa(i),
+			Name:      serviceName,
+			Endpoints: []string{ep},
+		})
+	}
+	return items, r.changed
+}
+
+// staticWatcher 静态地址监听器
+type staticWatcher struct {
+	registry    *StaticRegistry
+	serviceName string
+	ctx         context.Context
+	cancel      context.CancelFunc
+	changed     chan struct{}
+	first       bool
+}
+
+// Next 首次调用立即返回，之后阻塞到地址变化
+func (w *staticWatcher) Next() ([]*registry.ServiceInstance, error) {
+	if !w.first {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-w.changed:
+		}
+	}
+	w.first = false
+
+	items, changed := w.registry.instances(w.serviceName)
+	w.changed = changed
+	return items, nil
+}
+
+// Stop 停止监听
+func (w *staticWatcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+// standaloneFromEnv 环境变量是否开启单机模式
+func standaloneFromEnv() bool {
+	v, _ := strconv.ParseBool(os.Getenv(StandaloneEnv))
+	return v
+}
+
+// localSources 去掉Nacos配置源，单机模式只使用本地文件、环境变量等配置源
+func localSources(s []config.Source) (local []config.Source, dropped int) {
+	for _, src := range s {
+		if _, ok := src.(*nacosconfig.Config); ok {
+			dropped++
+			continue
+		}
+		local = append(local, src)
+	}
+	return local, dropped
+}
+
+// watchStandaloneServices 从配置加载静态服务地址并监听变化
+func watchStandaloneServices(c config.Config, r *StaticRegistry) error {
+	var services map[string][]string
+	if err := c.Value(StandaloneServicesKey).Scan(&services); err != nil && !errors.Is(err, config.ErrNotFound) {
+		return fmt.Errorf("解码配置失败(%s): %w", StandaloneServicesKey, err)
+	}
+	if services != nil {
+		r.SetServices(services)
+	}
+
+	err := c.Watch(StandaloneServicesKey, func(_ string, v config.Value) {
+		var services map[string][]string
+		if err := v.Scan(&services); err == nil {
+			r.SetServices(services)
+		}
+	})
+	if errors.Is(err, config.ErrNotFound) {
+		return nil
+	}
+	return err
+}
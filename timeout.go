@@ -0,0 +1,129 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// 超时配置键
+	TimeoutConfigKey = "timeout"
+
+	// 默认请求超时
+	DefaultRequestTimeout = 30 * time.Second
+)
+
+// ErrRequestTimeout 请求处理超时
+var ErrRequestTimeout = errors.New(504, "TIMEOUT", "request handling timed out")
+
+// TimeoutConfig 超时配置，对应配置中的 timeout 节点
+type TimeoutConfig struct {
+	Default Duration            `json:"default"` // 默认超时，<=0时使用30s
+	Routes  map[string]Duration `json:"routes"`  // 按operation覆盖的超时，<=0表示不限制
+}
+
+// Timeout 请求超时中间件，超时配置可热更新
+type Timeout struct {
+	mu  sync.RWMutex
+	cfg TimeoutConfig
+}
+
+// NewTimeout 根据配置创建超时中间件
+func NewTimeout(cfg TimeoutConfig) *Timeout {
+	return &Timeout{cfg: cfg}
+}
+
+// Update 更新超时配置
+func (t *Timeout) Update(cfg TimeoutConfig) {
+	t.mu.Lock()
+	t.cfg = cfg
+	t.mu.Unlock()
+}
+
+// Middleware 服务端超时中间件
+// 处理函数在独立协程中执行，超时后立即返回504，处理函数可通过ctx感知取消；
+// 处理函数中的panic会转到调用方协程重新抛出，交由recovery处理
+func (t *Timeout) Middleware() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var operation string
+			if info, ok := transport.FromServerContext(ctx); ok {
+				operation = info.Operation()
+			}
+			d := t.timeout(operation)
+			if d <= 0 {
+				return handler(ctx, req)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			type result struct {
+				reply interface{}
+				err   error
+				panic interface{}
+			}
+			done := make(chan result, 1)
+			go func() {
+				var r result
+				defer func() {
+					if e := recover(); e != nil {
+						r.panic = e
+					}
+					done <- r
+				}()
+				r.reply, r.err = handler(ctx, req)
+			}()
+
+			select {
+			case r := <-done:
+				if r.panic != nil {
+					panic(r.panic)
+				}
+				return r.reply, r.err
+			case <-ctx.Done():
+				return nil, ErrRequestTimeout
+			}
+		}
+	}
+}
+
+// NewTimeoutFromConfig 从配置的 timeout 节点创建超时中间件，并在配置变化时热更新
+func NewTimeoutFromConfig(r *appResult) (*Timeout, error) {
+	var cfg TimeoutConfig
+	if err := r.Cfg.Value(TimeoutConfigKey).Scan(&cfg); err != nil && err != config.ErrNotFound {
+		return nil, err
+	}
+
+	t := NewTimeout(cfg)
+	err := Watch(r, TimeoutConfigKey, func(cfg TimeoutConfig) error {
+		t.Update(cfg)
+		log.NewHelper(r.Logger).Info("超时配置已更新")
+		return nil
+	})
+	if err != nil && err != config.ErrNotFound {
+		return nil, err
+	}
+	return t, nil
+}
+
+// timeout 获取operation对应的超时
+func (t *Timeout) timeout(operation string) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if d, ok := t.cfg.Routes[operation]; ok {
+		return d.Std()
+	}
+	if t.cfg.Default > 0 {
+		return t.cfg.Default.Std()
+	}
+	return DefaultRequestTimeout
+}
@@ -0,0 +1,112 @@
+package timeutil
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Calendar 工作日历
+// 默认周一至周五为工作日，可追加法定节假日和调休上班日，按业务时区判断日期
+type Calendar struct {
+	mu       sync.RWMutex
+	holidays map[string]struct{}
+	workdays map[string]struct{}
+}
+
+// NewCalendar 创建工作日历
+func NewCalendar() *Calendar {
+	return &Calendar{
+		holidays: make(map[string]struct{}),
+		workdays: make(map[string]struct{}),
+	}
+}
+
+// AddHolidays 追加节假日，日期格式 2006-01-02
+func (c *Calendar) AddHolidays(dates ...string) error {
+	return c.add(c.holidays, dates)
+}
+
+// AddWorkdays 追加调休上班日，日期格式 2006-01-02
+func (c *Calendar) AddWorkdays(dates ...string) error {
+	return c.add(c.workdays, dates)
+}
+
+// Reset 用新的节假日和调休上班日替换原有设置，用于配置热更新
+func (c *Calendar) Reset(holidays, workdays []string) error {
+	next := NewCalendar()
+	if err := next.AddHolidays(holidays...); err != nil {
+		return err
+	}
+	if err := next.AddWorkdays(workdays...); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.holidays, c.workdays = next.holidays, next.workdays
+	c.mu.Unlock()
+	return nil
+}
+
+// IsWorkday 是否为工作日
+func (c *Calendar) IsWorkday(t time.Time) bool {
+	key := FormatDate(t)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.workdays[key]; ok {
+		return true
+	}
+	if _, ok := c.holidays[key]; ok {
+		return false
+	}
+	wd := In(t).Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+// NextWorkday 下一个工作日的零点(不含当天)
+func (c *Calendar) NextWorkday(t time.Time) time.Time {
+	return c.AddWorkingDays(t, 1)
+}
+
+// AddWorkingDays 从t起跳过非工作日前进n个工作日，返回当天零点；n<=0时返回t当天零点
+func (c *Calendar) AddWorkingDays(t time.Time, n int) time.Time {
+	day := StartOfDay(t)
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if c.IsWorkday(day) {
+			n--
+		}
+	}
+	return day
+}
+
+// WorkingDaysBetween [from, to)之间的工作日天数
+func (c *Calendar) WorkingDaysBetween(from, to time.Time) int {
+	var n int
+	for day := StartOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if c.IsWorkday(day) {
+			n++
+		}
+	}
+	return n
+}
+
+// add 解析日期并加入集合
+func (c *Calendar) add(set map[string]struct{}, dates []string) error {
+	keys := make([]string, 0, len(dates))
+	for _, d := range dates {
+		t, err := Parse(Date, d)
+		if err != nil {
+			return fmt.Errorf("日期格式错误(%s): %w", d, err)
+		}
+		keys = append(keys, t.Format(Date))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package timeutil
+
+import (
+	"sync"
+	"time"
+)
+
+// Lap 计时分段
+type Lap struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Stopwatch 基于单调时钟的计时器，不受系统时间调整影响
+type Stopwatch struct {
+	mu    sync.Mutex
+	start time.Time
+	last  time.Time
+	laps  []Lap
+}
+
+// StartStopwatch 创建并开始计时
+func StartStopwatch() *Stopwatch {
+	now := time.Now()
+	return &Stopwatch{start: now, last: now}
+}
+
+// Elapsed 开始计时至今的时间
+func (s *Stopwatch) Elapsed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.start)
+}
+
+// Lap 记录一个分段，返回距上一分段(或开始)的时间
+func (s *Stopwatch) Lap(name string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	d := now.Sub(s.last)
+	s.last = now
+	s.laps = append(s.laps, Lap{Name: name, Duration: d})
+	return d
+}
+
+// Laps 已记录的分段
+func (s *Stopwatch) Laps() []Lap {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Lap(nil), s.laps...)
+}
+
+// Reset 清空分段并重新开始计时
+func (s *Stopwatch) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.start, s.last = now, now
+	s.laps = nil
+}
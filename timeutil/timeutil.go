@@ -0,0 +1,136 @@
+package timeutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// 常用格式
+const (
+	DateTime = "2006-01-02 15:04:05"
+	Date     = "2006-01-02"
+	Clock    = "15:04:05"
+)
+
+// DefaultLocationName 默认业务时区
+const DefaultLocationName = "Asia/Shanghai"
+
+// cst 系统缺少时区数据时使用的东八区
+var cst = time.FixedZone("CST", 8*3600)
+
+var location atomic.Pointer[time.Location]
+
+func init() {
+	loc, err := time.LoadLocation(DefaultLocationName)
+	if err != nil {
+		loc = cst
+	}
+	location.Store(loc)
+}
+
+// Location 当前业务时区
+func Location() *time.Location {
+	return location.Load()
+}
+
+// SetLocation 设置业务时区
+func SetLocation(loc *time.Location) {
+	if loc != nil {
+		location.Store(loc)
+	}
+}
+
+// SetLocationName 按名称设置业务时区，如 Asia/Shanghai、UTC
+func SetLocationName(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		if name != DefaultLocationName {
+			return fmt.Errorf("加载时区失败(%s): %w", name, err)
+		}
+		loc = cst
+	}
+	location.Store(loc)
+	return nil
+}
+
+// Now 业务时区的当前时间
+func Now() time.Time {
+	return time.Now().In(Location())
+}
+
+// In 转换到业务时区
+func In(t time.Time) time.Time {
+	return t.In(Location())
+}
+
+// Parse 按业务时区解析不带时区的时间字符串
+func Parse(layout, value string) (time.Time, error) {
+	return time.ParseInLocation(layout, value, Location())
+}
+
+// ParseAny 依次尝试RFC3339、DateTime、Date格式解析，不带时区的按业务时区解析
+func ParseAny(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, DateTime, Date} {
+		if t, err := Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法识别的时间格式: %s", value)
+}
+
+// Format 按业务时区格式化
+func Format(t time.Time, layout string) string {
+	return t.In(Location()).Format(layout)
+}
+
+// FormatDateTime 按业务时区格式化为 2006-01-02 15:04:05
+func FormatDateTime(t time.Time) string {
+	return Format(t, DateTime)
+}
+
+// FormatDate 按业务时区格式化为 2006-01-02
+func FormatDate(t time.Time) string {
+	return Format(t, Date)
+}
+
+// StartOfDay 业务时区当天零点
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay 业务时区当天最后一纳秒
+func EndOfDay(t time.Time) time.Time {
+	return StartOfDay(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartOfWeek 业务时区本周一零点
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// StartOfMonth 业务时区本月1日零点
+func StartOfMonth(t time.Time) time.Time {
+	t = t.In(Location())
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfMonth 业务时区本月最后一纳秒
+func EndOfMonth(t time.Time) time.Time {
+	return StartOfMonth(t).AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// TruncateHour 业务时区截断到整点
+// time.Truncate 按绝对时间截断，对非整小时偏移的时区结果不正确，这里按本地时间截断
+func TruncateHour(t time.Time) time.Time {
+	t = t.In(Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+}
+
+// SameDay 两个时间在业务时区是否为同一天
+func SameDay(a, b time.Time) bool {
+	return StartOfDay(a).Equal(StartOfDay(b))
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/lnhlg/gbm-common/timeutil"
+)
+
+const (
+	// 业务时区配置键，如 Asia/Shanghai，默认 Asia/Shanghai
+	TimezoneKey = "app.timezone"
+
+	// 工作日历配置键，包含 holidays 和 workdays 两个日期列表(2006-01-02)
+	CalendarKey = "calendar"
+)
+
+type calendarSettings struct {
+	Holidays []string `json:"holidays"` // 节假日
+	Workdays []string `json:"workdays"` // 调休上班日
+}
+
+// WatchTimezone 从配置读取 app.timezone 作为业务时区，并在配置变化时更新
+func WatchTimezone(c config.Config, logger log.Logger) error {
+	if s, err := c.Value(TimezoneKey).String(); err == nil && s != "" {
+		if err := timeutil.SetLocationName(s); err != nil {
+			return err
+		}
+	}
+
+	err := c.Watch(TimezoneKey, func(_ string, v config.Value) {
+		s, err := v.String()
+		if err != nil || s == "" {
+			return
+		}
+		if err := timeutil.SetLocationName(s); err != nil {
+			_ = logger.Log(log.LevelError, log.DefaultMessageKey, "更新业务时区失败: "+err.Error())
+			return
+		}
+		_ = logger.Log(log.LevelInfo, log.DefaultMessageKey, "业务时区已更新: "+s)
+	})
+	if err == config.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// WatchCalendar 从配置 calendar 加载工作日历，并在配置变化时更新
+func WatchCalendar(c config.Config, cal *timeutil.Calendar, logger log.Logger) error {
+	var cs calendarSettings
+	if err := c.Value(CalendarKey).Scan(&cs); err == nil {
+		if err := cal.Reset(cs.Holidays, cs.Workdays); err != nil {
+			return err
+		}
+	}
+
+	err := c.Watch(CalendarKey, func(_ string, v config.Value) {
+		var cs calendarSettings
+		if err := v.Scan(&cs); err != nil {
+			_ = logger.Log(log.LevelError, log.DefaultMessageKey, "解析工作日历失败: "+err.Error())
+			return
+		}
+		if err := cal.Reset(cs.Holidays, cs.Workdays); err != nil {
+			_ = logger.Log(log.LevelError, log.DefaultMessageKey, "更新工作日历失败: "+err.Error())
+			return
+		}
+		_ = logger.Log(log.LevelInfo, log.DefaultMessageKey, "工作日历已更新")
+	})
+	if err == config.ErrNotFound {
+		return nil
+	}
+	return err
+}
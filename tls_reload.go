@@ -0,0 +1,170 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	// 默认证书文件变化检查间隔
+	DefaultCertReloadInterval = 30 * time.Second
+)
+
+// CertReloader 服务端证书热加载
+// 周期性检查证书、私钥和客户端CA文件的修改时间，变化时重新加载，新连接使用新证书；
+// 加载失败时保留原证书并记录日志，适用于证书轮换和Kubernetes Secret更新
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+	logger   *log.Helper
+
+	cert    atomic.Pointer[tls.Certificate]
+	clients atomic.Pointer[x509.CertPool]
+	mtime   time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewCertReloader 加载证书并创建热加载器，caFile非空时开启双向认证，要求并校验客户端证书
+func NewCertReloader(certFile, keyFile, caFile string, logger log.Logger) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		interval: DefaultCertReloadInterval,
+		logger:   log.NewHelper(logger),
+		stop:     make(chan struct{}),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WithInterval 设置证书文件变化检查间隔
+func (r *CertReloader) WithInterval(d time.Duration) *CertReloader {
+	if d > 0 {
+		r.interval = d
+	}
+	return r
+}
+
+// TLSConfig 生成TLS配置，每次握手读取当前证书和客户端CA
+func (r *CertReloader) TLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.cert.Load(), nil
+		},
+	}
+	if r.caFile == "" {
+		return base
+	}
+
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+	base.ClientCAs = r.clients.Load()
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cfg := base.Clone()
+		cfg.ClientCAs = r.clients.Load()
+		cfg.GetConfigForClient = nil
+		return cfg, nil
+	}
+	return base
+}
+
+// Start 启动后台检查
+func (r *CertReloader) Start() {
+	go r.run()
+}
+
+// Stop 停止后台检查，可多次调用
+func (r *CertReloader) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// run 周期性检查文件变化
+func (r *CertReloader) run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+
+		mtime, err := r.latestModTime()
+		if err != nil {
+			r.logger.Warnf("检查证书文件失败: %v", err)
+			continue
+		}
+		if !mtime.After(r.mtime) {
+			continue
+		}
+		if err := r.load(); err != nil {
+			r.logger.Errorf("重新加载证书失败，继续使用原证书: %v", err)
+			continue
+		}
+		r.logger.Infof("证书已重新加载: %s", r.certFile)
+	}
+}
+
+// load 加载证书、私钥和客户端CA
+func (r *CertReloader) load() error {
+	mtime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("加载服务端证书失败: %w", err)
+	}
+
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端CA证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("无效的客户端CA证书: %s", r.caFile)
+		}
+		r.clients.Store(pool)
+	}
+
+	r.cert.Store(&cert)
+	r.mtime = mtime
+	return nil
+}
+
+// latestModTime 证书相关文件中最新的修改时间
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f == "" {
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// 链路上报协议
+	TraceProtocolGRPC = "grpc"
+	TraceProtocolHTTP = "http"
+)
+
+// TraceConfig 链路追踪配置，对应配置中的 trace 节点
+// Jaeger(1.35+)原生支持OTLP协议，endpoint 直接填写Jaeger collector的OTLP端口即可
+type TraceConfig struct {
+	Endpoint    string            `json:"endpoint"`     // 上报地址，为空时不启用链路追踪
+	Protocol    string            `json:"protocol"`     // grpc(默认) 或 http
+	URLPath     string            `json:"url_path"`     // http协议的上报路径，默认 /v1/traces
+	Insecure    bool              `json:"insecure"`     // 是否使用明文连接
+	Headers     map[string]string `json:"headers"`      // 上报时附带的请求头
+	SampleRatio *float64          `json:"sample_ratio"` // 采样率[0,1]，默认全采样
+	Sampling    *TraceSampling    `json:"sampling"`     // 按路由采样和出错必采样，支持热更新
+}
+
+// NewTracerProvider 创建OTLP链路追踪提供者，并设置为全局TracerProvider
+func NewTracerProvider(ctx context.Context, cfg TraceConfig, id, name, version string) (*sdktrace.TracerProvider, error) {
+	sampler, err := cfg.Sampler()
+	if err != nil {
+		return nil, err
+	}
+	return NewTracerProviderWithSampler(ctx, cfg, sampler, id, name, version)
+}
+
+// NewTracerProviderWithSampler 使用指定采样器创建链路追踪提供者，采样器可通过 WatchTraceSampling 热更新
+func NewTracerProviderWithSampler(ctx context.Context, cfg TraceConfig, sampler *TraceSampler, id, name, version string) (*sdktrace.TracerProvider, error) {
+	exporter, err := newTraceExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceInstanceID(id),
+		semconv.ServiceName(name),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(&errorSpanProcessor{
+			SpanProcessor: sdktrace.NewBatchSpanProcessor(exporter),
+			sampler:       sampler,
+		}),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp, nil
+}
+
+// newTraceExporter 根据协议创建OTLP导出器
+func newTraceExporter(ctx context.Context, cfg TraceConfig) (*otlptrace.Exporter, error) {
+	switch cfg.Protocol {
+	case "", TraceProtocolGRPC:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithHeaders(cfg.Headers),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	case TraceProtocolHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.Endpoint),
+			otlptracehttp.WithHeaders(cfg.Headers),
+		}
+		if cfg.URLPath != "" {
+			opts = append(opts, otlptracehttp.WithURLPath(cfg.URLPath))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	default:
+		return nil, fmt.Errorf("不支持的链路上报协议: %s", cfg.Protocol)
+	}
+}
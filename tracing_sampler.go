@@ -0,0 +1,195 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// 链路追踪配置键
+	TraceConfigKey = "trace"
+)
+
+// TraceSampling 采样配置，对应配置中的 trace.sampling 节点，支持热更新
+type TraceSampling struct {
+	Ratio         *float64           `json:"ratio"`           // 默认采样率[0,1]，为空时使用 trace.sample_ratio，都为空时全采样
+	Routes        map[string]float64 `json:"routes"`          // 按operation或路径覆盖采样率，以*结尾表示前缀匹配，最长匹配优先
+	AlwaysOnError bool               `json:"always_on_error"` // 未采样的请求出错时仍上报出错的span
+}
+
+var _ sdktrace.Sampler = (*TraceSampler)(nil)
+
+// TraceSampler 可热更新的采样器
+// - 上游已采样的请求始终采样，上游未采样的请求不采样(开启 AlwaysOnError 时只记录)
+// - 根span按span名称(即operation)匹配 Routes 中的采样率，未匹配时使用默认采样率
+// - 开启 AlwaysOnError 时未采样的span也会记录，状态为错误的span结束时仍会上报(只上报该span本身)
+type TraceSampler struct {
+	state atomic.Pointer[samplerState]
+}
+
+type samplerState struct {
+	ratio   sdktrace.Sampler
+	exact   map[string]sdktrace.Sampler
+	prefix  []routeSampler // 按前缀长度降序
+	onError bool
+	desc    string
+}
+
+type routeSampler struct {
+	prefix  string
+	sampler sdktrace.Sampler
+}
+
+// NewTraceSampler 创建采样器，fallback为未配置 Ratio 时的默认采样率
+func NewTraceSampler(cfg TraceSampling, fallback *float64) (*TraceSampler, error) {
+	s := &TraceSampler{}
+	if err := s.Update(cfg, fallback); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Update 更新采样配置，配置非法时保留原配置
+func (s *TraceSampler) Update(cfg TraceSampling, fallback *float64) error {
+	ratio := 1.0
+	switch {
+	case cfg.Ratio != nil:
+		ratio = *cfg.Ratio
+	case fallback != nil:
+		ratio = *fallback
+	}
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("采样率必须在[0,1]之间: %v", ratio)
+	}
+
+	st := &samplerState{
+		ratio:   sdktrace.TraceIDRatioBased(ratio),
+		exact:   make(map[string]sdktrace.Sampler),
+		onError: cfg.AlwaysOnError,
+	}
+	for route, r := range cfg.Routes {
+		if r < 0 || r > 1 {
+			return fmt.Errorf("路由%s的采样率必须在[0,1]之间: %v", route, r)
+		}
+		if p, ok := strings.CutSuffix(route, "*"); ok {
+			st.prefix = append(st.prefix, routeSampler{prefix: p, sampler: sdktrace.TraceIDRatioBased(r)})
+			continue
+		}
+		st.exact[route] = sdktrace.TraceIDRatioBased(r)
+	}
+	sort.Slice(st.prefix, func(i, j int) bool { return len(st.prefix[i].prefix) > len(st.prefix[j].prefix) })
+	st.desc = fmt.Sprintf("TraceSampler{ratio=%g,routes=%d,onError=%t}", ratio, len(cfg.Routes), cfg.AlwaysOnError)
+
+	s.state.Store(st)
+	return nil
+}
+
+// AlwaysOnError 是否开启出错必采样
+func (s *TraceSampler) AlwaysOnError() bool {
+	return s.state.Load().onError
+}
+
+// ShouldSample 实现 sdktrace.Sampler
+func (s *TraceSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	st := s.state.Load()
+	psc := trace.SpanContextFromContext(p.ParentContext)
+
+	var res sdktrace.SamplingResult
+	switch {
+	case psc.IsValid() && psc.IsSampled():
+		res = sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: psc.TraceState()}
+	case psc.IsValid():
+		res = sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: psc.TraceState()}
+	default:
+		res = st.route(p.Name).ShouldSample(p)
+	}
+
+	if res.Decision == sdktrace.Drop && st.onError {
+		res.Decision = sdktrace.RecordOnly
+	}
+	return res
+}
+
+// Description 实现 sdktrace.Sampler
+func (s *TraceSampler) Description() string {
+	return s.state.Load().desc
+}
+
+// route 按span名称选择采样器
+func (st *samplerState) route(name string) sdktrace.Sampler {
+	if r, ok := st.exact[name]; ok {
+		return r
+	}
+	for _, r := range st.prefix {
+		if strings.HasPrefix(name, r.prefix) {
+			return r.sampler
+		}
+	}
+	return st.ratio
+}
+
+// Sampler 根据链路追踪配置创建采样器
+func (cfg TraceConfig) Sampler() (*TraceSampler, error) {
+	var sampling TraceSampling
+	if cfg.Sampling != nil {
+		sampling = *cfg.Sampling
+	}
+	return NewTraceSampler(sampling, cfg.SampleRatio)
+}
+
+// WatchTraceSampling 监听 trace 配置节点，采样配置变化时热更新采样器
+func WatchTraceSampling(c config.Config, s *TraceSampler, logger log.Logger) error {
+	err := c.Watch(TraceConfigKey, func(_ string, v config.Value) {
+		var cfg TraceConfig
+		if err := v.Scan(&cfg); err != nil {
+			_ = logger.Log(log.LevelError, log.DefaultMessageKey, "解析链路追踪配置失败: "+err.Error())
+			return
+		}
+		var sampling TraceSampling
+		if cfg.Sampling != nil {
+			sampling = *cfg.Sampling
+		}
+		if err := s.Update(sampling, cfg.SampleRatio); err != nil {
+			_ = logger.Log(log.LevelError, log.DefaultMessageKey, "更新采样配置失败: "+err.Error())
+			return
+		}
+		_ = logger.Log(log.LevelInfo, log.DefaultMessageKey, "采样配置已更新: "+s.Description())
+	})
+	if err == config.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// errorSpanProcessor 开启 AlwaysOnError 时，将未采样但出错的span按已采样转交给下游处理器
+type errorSpanProcessor struct {
+	sdktrace.SpanProcessor
+	sampler *TraceSampler
+}
+
+// OnEnd 实现 sdktrace.SpanProcessor
+func (p *errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() && s.Status().Code == codes.Error && p.sampler.AlwaysOnError() {
+		s = sampledSpan{s}
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+// sampledSpan 标记为已采样的只读span
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+// SpanContext 返回带采样标记的span上下文
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/lnhlg/gbm-common/i18n"
+	"github.com/lnhlg/gbm-common/response"
+)
+
+// selfValidator 由protoc-gen-validate等生成的校验接口
+type selfValidator interface {
+	Validate() error
+}
+
+// ValidateRequest 请求校验中间件
+// 对解码后的请求结构体执行 validate 标签中的规则，请求实现 Validate() error 时先调用它；
+// 校验失败返回 response.ValidationFailed(HTTP 400)，msg汇总全部字段错误，
+// 元数据中以字段名为键记录未通过的规则
+func ValidateRequest() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if err := validateRequest(ctx, req); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// validateRequest 校验请求，非结构体请求直接通过
+func validateRequest(ctx context.Context, req interface{}) error {
+	lang := i18n.FromContext(ctx)
+
+	if v, ok := req.(selfValidator); ok {
+		if err := v.Validate(); err != nil {
+			return response.ValidationFailed.Error(i18n.Sprintf(lang, "参数校验失败: %s", err.Error()))
+		}
+	}
+
+	rv := reflect.ValueOf(req)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := tagValidator.Struct(req)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		if err != nil {
+			return response.ValidationFailed.Error(i18n.Sprintf(lang, "参数校验失败: %s", err.Error()))
+		}
+		return nil
+	}
+
+	md := make(map[string]string, len(verrs))
+	details := make([]string, 0, len(verrs))
+	for _, fe := range verrs {
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		// 去掉命名空间开头的结构体名
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		md[field] = rule
+		details = append(details, field+"("+rule+")")
+	}
+
+	e := response.ValidationFailed.Error(i18n.Sprintf(lang, "参数校验失败: %s", strings.Join(details, "; ")))
+	for k, v := range e.Metadata {
+		md[k] = v
+	}
+	return e.WithMetadata(md)
+}
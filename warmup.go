@@ -0,0 +1,192 @@
+package common
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+const (
+	// 预热任务的默认超时时间
+	DefaultWarmupTimeout = time.Minute
+
+	// 权重爬升的默认步数
+	DefaultWarmupSteps = 10
+)
+
+var _ registry.Registrar = (*WarmupRegistrar)(nil)
+
+// WarmupTask 注册前执行的预热任务，如缓存预加载、首批请求预跑
+type WarmupTask func(ctx context.Context) error
+
+type warmupTask struct {
+	name string
+	fn   WarmupTask
+}
+
+// WarmupRegistrar 预热后注册的注册器
+// - Register 先依次执行预热任务(只执行一次)，失败时记录日志后继续注册
+// - 设置权重爬升时先以初始权重注册，再在爬升时间内分步重新注册，权重线性升到目标权重
+// - 目标权重取实例元数据weight，未设置时为 DefaultNacosWeight
+type WarmupRegistrar struct {
+	reg     registry.Registrar
+	logger  *log.Helper
+	tasks   []warmupTask
+	timeout time.Duration
+
+	initial float64
+	ramp    time.Duration
+	steps   int
+
+	once   sync.Once
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWarmupRegistrar 创建预热后注册的注册器
+func NewWarmupRegistrar(reg registry.Registrar, logger log.Logger) *WarmupRegistrar {
+	return &WarmupRegistrar{
+		reg:     reg,
+		logger:  log.NewHelper(logger),
+		timeout: DefaultWarmupTimeout,
+		steps:   DefaultWarmupSteps,
+	}
+}
+
+// WithTask 追加预热任务，按追加顺序执行
+func (r *WarmupRegistrar) WithTask(name string, fn WarmupTask) *WarmupRegistrar {
+	r.tasks = append(r.tasks, warmupTask{name: name, fn: fn})
+	return r
+}
+
+// WithTimeout 设置全部预热任务的总超时时间
+func (r *WarmupRegistrar) WithTimeout(d time.Duration) *WarmupRegistrar {
+	r.timeout = d
+	return r
+}
+
+// WithRamp 设置权重爬升，initial为初始权重，d为升到目标权重的时间，d<=0时不爬升
+func (r *WarmupRegistrar) WithRamp(initial float64, d time.Duration) *WarmupRegistrar {
+	r.initial = initial
+	r.ramp = d
+	return r
+}
+
+// WithSteps 设置权重爬升的步数
+func (r *WarmupRegistrar) WithSteps(steps int) *WarmupRegistrar {
+	if steps > 0 {
+		r.steps = steps
+	}
+	return r
+}
+
+// Register 预热后注册，并启动后台权重爬升；重复调用时重新开始爬升
+func (r *WarmupRegistrar) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	r.once.Do(func() { r.warmup(ctx) })
+
+	target := float64(DefaultNacosWeight)
+	if w, err := strconv.ParseFloat(service.Metadata["weight"], 64); err == nil && w > 0 {
+		target = w
+	}
+	// 重新注册（就绪恢复、Nacos重连）时先停止进行中的爬升，避免两个爬升同时写权重
+	r.stopRamp()
+	if r.ramp <= 0 || r.initial <= 0 || r.initial >= target {
+		return r.reg.Register(ctx, service)
+	}
+
+	if err := r.reg.Register(ctx, withWeight(service, r.initial)); err != nil {
+		return err
+	}
+
+	rctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	r.mu.Lock()
+	prevCancel, prevDone := r.cancel, r.done
+	r.cancel, r.done = cancel, done
+	r.mu.Unlock()
+	if prevCancel != nil {
+		// 并发的Register已启动爬升
+		prevCancel()
+		<-prevDone
+	}
+
+	go r.rampUp(rctx, service, target, done)
+	return nil
+}
+
+// Deregister 停止权重爬升并注销
+func (r *WarmupRegistrar) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	r.stopRamp()
+	return r.reg.Deregister(ctx, service)
+}
+
+// warmup 依次执行预热任务
+func (r *WarmupRegistrar) warmup(ctx context.Context) {
+	if len(r.tasks) == 0 {
+		return
+	}
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	for _, t := range r.tasks {
+		if err := t.fn(ctx); err != nil {
+			r.logger.Warnf("预热任务%s失败: %v", t.name, err)
+		}
+	}
+	r.logger.Infof("预热完成，耗时%s", time.Since(start))
+}
+
+// rampUp 分步重新注册，权重从初始值线性升到目标值
+func (r *WarmupRegistrar) rampUp(ctx context.Context, service *registry.ServiceInstance, target float64, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.ramp / time.Duration(r.steps))
+	defer ticker.Stop()
+
+	for i := 1; i <= r.steps; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		weight := r.initial + (target-r.initial)*float64(i)/float64(r.steps)
+		if err := r.reg.Register(ctx, withWeight(service, weight)); err != nil {
+			r.logger.Warnf("调整注册权重到%.1f失败: %v", weight, err)
+		}
+	}
+	r.logger.Infof("注册权重已升到%.1f", target)
+}
+
+// stopRamp 停止权重爬升并等待后台任务退出
+func (r *WarmupRegistrar) stopRamp() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// withWeight 复制实例并设置元数据weight
+func withWeight(service *registry.ServiceInstance, weight float64) *registry.ServiceInstance {
+	si := *service
+	si.Metadata = make(map[string]string, len(service.Metadata)+1)
+	for k, v := range service.Metadata {
+		si.Metadata[k] = v
+	}
+	si.Metadata["weight"] = strconv.FormatFloat(weight, 'f', -1, 64)
+	return &si
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ConfigValue 读取配置键并解码为T类型
+func ConfigValue[T any](r *appResult, key string) (T, error) {
+	var v T
+	if err := r.Cfg.Value(key).Scan(&v); err != nil {
+		return v, fmt.Errorf("解码配置失败(%s): %w", key, err)
+	}
+	return v, nil
+}
+
+// Watch 监听配置键的变化，新值解码为T类型后调用fn
+// 解码失败、fn返回错误或fn发生panic时只记录日志，不会影响配置监听协程
+func Watch[T any](r *appResult, key string, fn func(newVal T) error) error {
+	helper := log.NewHelper(r.Logger)
+
+	return r.Cfg.Watch(key, func(k string, value config.Value) {
+		defer func() {
+			if e := recover(); e != nil {
+				helper.Errorf("配置回调发生panic(%s): %v", k, e)
+			}
+		}()
+
+		var v T
+		if err := value.Scan(&v); err != nil {
+			helper.Errorf("解码配置失败(%s): %v", k, err)
+			return
+		}
+
+		if err := fn(v); err != nil {
+			helper.Errorf("配置回调失败(%s): %v", k, err)
+		}
+	})
+}
+
+// Bound 绑定到配置键的结构体，配置变化时整体替换，读取方始终得到完整一致的快照
+type Bound[T any] struct {
+	v atomic.Pointer[T]
+}
+
+// Load 当前配置快照，返回值只读，不要修改
+func (b *Bound[T]) Load() *T {
+	return b.v.Load()
+}
+
+// WatchStruct 将配置键绑定到结构体并随配置变化自动更新
+// 每次解码都先应用 default 标签再执行 validate 校验，校验失败时保留旧值并记录日志；
+// 配置键不存在时使用默认值。新值与旧值相同时不替换，替换后调用onChange(可为nil)
+func WatchStruct[T any](r *appResult, key string, onChange func(old, cur *T)) (*Bound[T], error) {
+	cur, err := decodeStruct[T](r.Cfg.Value(key))
+	if err != nil {
+		return nil, fmt.Errorf("绑定配置失败(%s): %w", key, err)
+	}
+
+	b := &Bound[T]{}
+	b.v.Store(cur)
+
+	helper := log.NewHelper(r.Logger)
+	err = r.Cfg.Watch(key, func(k string, value config.Value) {
+		next, err := decodeStruct[T](value)
+		if err != nil {
+			helper.Errorf("配置校验失败，保留旧值(%s): %v", k, err)
+			return
+		}
+
+		old := b.v.Load()
+		if reflect.DeepEqual(old, next) {
+			return
+		}
+		b.v.Store(next)
+
+		if onChange != nil {
+			defer func() {
+				if e := recover(); e != nil {
+					helper.Errorf("配置回调发生panic(%s): %v", k, e)
+				}
+			}()
+			onChange(old, next)
+		}
+	})
+	if err != nil && err != config.ErrNotFound {
+		return nil, err
+	}
+	return b, nil
+}
+
+// decodeStruct 应用默认值、解码并校验，配置不存在时只应用默认值
+func decodeStruct[T any](value config.Value) (*T, error) {
+	v := new(T)
+	err := bindConfig(v, func(v interface{}) error {
+		if err := value.Scan(v); err != nil && !errors.Is(err, config.ErrNotFound) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
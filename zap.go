@@ -0,0 +1,81 @@
+package common
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var _ log.Logger = (*zapLogger)(nil)
+
+// zapLogger 基于zap的kratos日志实现
+type zapLogger struct {
+	log *zap.Logger
+}
+
+// NewZapLogger 创建基于zap的kratos日志，jsonFormat为true时输出JSON
+func NewZapLogger(w io.Writer, jsonFormat bool) log.Logger {
+	encCfg := zapcore.EncoderConfig{
+		LevelKey:       "level",
+		MessageKey:     "msg",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+
+	encoder := zapcore.NewConsoleEncoder(encCfg)
+	if jsonFormat {
+		encoder = zapcore.NewJSONEncoder(encCfg)
+	}
+	core := zapcore.NewCore(encoder, zapcore.AddSync(w), zapcore.DebugLevel)
+	// Fatal级别只记录日志不退出进程，与kratos标准日志行为保持一致
+	return &zapLogger{log: zap.New(core, zap.WithFatalHook(noopFatalHook{}))}
+}
+
+// noopFatalHook Fatal日志写入后不做任何处理
+type noopFatalHook struct{}
+
+func (noopFatalHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
+
+// Log 实现 log.Logger
+func (l *zapLogger) Log(level log.Level, keyvals ...interface{}) error {
+	if len(keyvals) == 0 {
+		return nil
+	}
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+
+	var msg string
+	fields := make([]zap.Field, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if key == log.DefaultMessageKey {
+			msg = fmt.Sprint(keyvals[i+1])
+			continue
+		}
+		fields = append(fields, zap.Any(key, keyvals[i+1]))
+	}
+
+	switch level {
+	case log.LevelDebug:
+		l.log.Debug(msg, fields...)
+	case log.LevelInfo:
+		l.log.Info(msg, fields...)
+	case log.LevelWarn:
+		l.log.Warn(msg, fields...)
+	case log.LevelError:
+		l.log.Error(msg, fields...)
+	case log.LevelFatal:
+		l.log.Fatal(msg, fields...)
+	}
+	return nil
+}
+
+// Sync 刷新缓冲区
+func (l *zapLogger) Sync() error {
+	return l.log.Sync()
+}